@@ -2,17 +2,25 @@ package main
 
 import (
 	"context"
+	"go-musthave-diploma-tpl/internal/gophermart/accrualclient"
+	"go-musthave-diploma-tpl/internal/gophermart/breaker"
 	config "go-musthave-diploma-tpl/internal/gophermart/config"
 	db "go-musthave-diploma-tpl/internal/gophermart/config/db"
+	"go-musthave-diploma-tpl/internal/gophermart/events"
+	"go-musthave-diploma-tpl/internal/gophermart/fraud"
 	chiRouter "go-musthave-diploma-tpl/internal/gophermart/handler"
 	"go-musthave-diploma-tpl/internal/gophermart/listener"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+	"go-musthave-diploma-tpl/internal/gophermart/notify"
+	"go-musthave-diploma-tpl/internal/gophermart/ratelimit"
 	"go-musthave-diploma-tpl/internal/gophermart/repository/postgres"
 	"go-musthave-diploma-tpl/internal/gophermart/service"
+	"go-musthave-diploma-tpl/internal/gophermart/webhook"
+	pgk "go-musthave-diploma-tpl/pkg"
 	logger "go-musthave-diploma-tpl/pkg/runtime/logger"
 	"net/http"
 	"os"
 	"os/signal"
-	"strings"
 	"syscall"
 	"time"
 
@@ -20,10 +28,13 @@ import (
 )
 
 func main() {
-	// конфиг
-	cfg := config.Load()
 	// логгер
 	customLogger := logger.NewHTTPLogger().Logger.Sugar()
+	// конфиг
+	cfg, err := config.Load()
+	if err != nil {
+		customLogger.Fatalf("Некорректная конфигурация: %v", err)
+	}
 	// бд и миграции
 	if err := db.Init(cfg.DatabaseURI); err != nil {
 		customLogger.Fatalf("PostgreSQL недоступна: %v", zap.Error(err))
@@ -32,13 +43,60 @@ func main() {
 	}
 	// chi роутер
 	repo := postgres.New()
-	//что должен реализовывать этот сервис
-	addr := cfg.AccrualSystemAddress
-	if !strings.HasPrefix(addr, "http") {
-		addr = "http://" + addr
+	repo.SetReferralBonus(models.ReferralBonus{
+		Referrer: cfg.ReferralBonusReferrer,
+		Referred: cfg.ReferralBonusReferred,
+	})
+	// клиент системы начислений
+	accrual := accrualclient.New(cfg.AccrualSystemAddress)
+
+	// circuit breaker вокруг запросов к системе начислений
+	accrualBreaker := breaker.New("accrual", breaker.Config{
+		FailureThreshold: cfg.AccrualBreakerThreshold,
+		CoolDown:         cfg.AccrualBreakerCoolDown,
+	}, func(name string, from, to breaker.State) {
+		customLogger.Warnf("Circuit breaker %s: %s -> %s", name, from, to)
+	})
+
+	// схема проверки номеров заказов
+	orderValidator, err := pgk.NewValidator(cfg.OrderNumberScheme, cfg.OrderNumberPattern)
+	if err != nil {
+		customLogger.Fatalf("Некорректная схема номеров заказов: %v", err)
 	}
 
-	svc := service.NewGofemartService(repo, addr)
+	// правила антифрода
+	fraudRules, err := fraud.LoadRules(cfg.FraudRulesFile)
+	if err != nil {
+		customLogger.Fatalf("Некорректные правила антифрода: %v", err)
+	}
+
+	// письма пользователям
+	notifier, err := notify.New(notify.Config{
+		Kind: cfg.Notifier,
+		File: cfg.NotifyFile,
+		SMTP: notify.SMTPConfig{
+			Addr:     cfg.SMTPAddr,
+			From:     cfg.SMTPFrom,
+			Username: cfg.SMTPUser,
+			Password: cfg.SMTPPass,
+		},
+	}, customLogger)
+	if err != nil {
+		customLogger.Fatalf("Некорректные настройки писем: %v", err)
+	}
+
+	svc := service.NewGofemartService(repo, cfg.AccrualSystemAddress)
+	svc.SetLogger(customLogger)
+	svc.SetOrderValidator(orderValidator)
+	svc.SetAccrualBreaker(accrualBreaker)
+	svc.SetSyncAccrual(accrual, cfg.SyncAccrualTimeout)
+	svc.SetAdminLogins(cfg.AdminLogins)
+	svc.SetPromoLimiter(ratelimit.New(ratelimit.Config{Limit: cfg.PromoRedeemAttempts, Window: cfg.PromoRedeemWindow}))
+	svc.SetAuditLog(repo)
+	svc.SetFraudEngine(fraud.NewEngine(fraudRules, repo, customLogger))
+	svc.SetWebhookAllowPrivate(cfg.WebhookAllowPrivate)
+	svc.SetNotifier(notifier, cfg.LargeWithdrawalAlert)
+	svc.SetEmailTokenTTL(cfg.EmailTokenTTL)
 	//инициализируем хандлеры
 	h := chiRouter.NewHandler(svc)
 	//инициализируем роуты
@@ -48,15 +106,44 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
-	// ПЕРЕДАЕМ АДРЕС СЕРВИСА НАЧИСЛЕНИЙ В LISTENER
-	orderListener := listener.NewOrderListener(cfg.DatabaseURI, cfg.AccrualSystemAddress, customLogger)
+	// listener разбирает очередь заказов через общий с сервисом клиент начислений и breaker
+	orderListener := listener.NewOrderListener(cfg.DatabaseURI, listener.Config{
+		InstanceID:           cfg.InstanceID,
+		Workers:              cfg.Workers,
+		VisibilityTimeout:    cfg.VisibilityTimeout,
+		MaxAttempts:          cfg.MaxJobAttempts,
+		DrainTimeout:         cfg.DrainTimeout,
+		ReconcileInterval:    cfg.ReconcileInterval,
+		StaleAfter:           cfg.StaleAfter,
+		PointsTTL:            cfg.PointsTTL,
+		PointsExpiryInterval: cfg.PointsExpiryInterval,
+	}, repo, accrual, accrualBreaker, customLogger)
+	orderListener.SetAuditLog(repo)
+	orderListener.SetNotifier(svc)
+	orderListener.SetPointsExpirer(repo)
 	orderListener.Start(ctx)
+	svc.SetLeaderReporter(orderListener.Elector())
+
+	// события для SSE слушает каждый инстанс
+	eventBroker := events.NewBroker(cfg.DatabaseURI, repo, cfg.EventsRetention, customLogger)
+	go eventBroker.Run(ctx)
+	svc.SetEventBroker(eventBroker)
+
+	// доставка вебхуков из outbox, доставки разбираются инстансами через SKIP LOCKED
+	webhookDispatcher := webhook.NewDispatcher(repo.DB, webhook.Config{
+		Timeout:             cfg.WebhookTimeout,
+		MaxAttempts:         cfg.WebhookMaxAttempts,
+		AllowPrivateTargets: cfg.WebhookAllowPrivate,
+	}, customLogger)
+	go webhookDispatcher.Run(ctx)
 
 	//создаём серве
 	server := &http.Server{
 		Addr:    cfg.RunAddress,
 		Handler: r,
 	}
+	// Shutdown не прерывает активные запросы, поэтому SSE-потоки закрываются через брокер
+	server.RegisterOnShutdown(eventBroker.Close)
 	//start server and graceful shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
@@ -79,6 +166,8 @@ func main() {
 	if err := server.Shutdown(ctx); err != nil {
 		customLogger.Fatalf("Принудительное завершение: %v", err)
 	}
+	// дожидаемся заказов в обработке и закрываем соединения слушателя
+	orderListener.Stop()
 	if err := logger.NewHTTPLogger().Close(); err != nil {
 		customLogger.Fatalf("Логгер не завершил работу: %v", err)
 	}
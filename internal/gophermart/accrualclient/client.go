@@ -0,0 +1,132 @@
+package accrualclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Client - клиент системы расчёта начислений
+type Client interface {
+	// получение информации о расчёте начислений
+	GetOrder(ctx context.Context, number string) (*OrderInfo, error)
+	// регистрация нового совершённого заказа
+	RegisterOrder(ctx context.Context, order Order) error
+	// регистрация информации о вознаграждении за товар
+	RegisterReward(ctx context.Context, reward Reward) error
+}
+
+const (
+	defaultTimeout    = 20 * time.Second
+	defaultRetryAfter = 60 * time.Second
+)
+
+// общий транспорт для всех клиентов, чтобы переиспользовать соединения
+var sharedTransport = func() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConnsPerHost = 32
+	return t
+}()
+
+// HTTPClient - реализация Client поверх HTTP API системы начислений
+type HTTPClient struct {
+	baseURL string
+	http    *http.Client
+}
+
+func New(address string) *HTTPClient {
+	return &HTTPClient{
+		baseURL: NormalizeURL(address),
+		http: &http.Client{
+			Transport: sharedTransport,
+			Timeout:   defaultTimeout,
+		},
+	}
+}
+
+// NormalizeURL - добавляет схему к адресу вида host:port
+func NormalizeURL(address string) string {
+	address = strings.TrimRight(strings.Trim(address, `"`), "/")
+	if !strings.HasPrefix(address, "http://") && !strings.HasPrefix(address, "https://") {
+		address = "http://" + address
+	}
+	return address
+}
+
+func (c *HTTPClient) GetOrder(ctx context.Context, number string) (*OrderInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/orders/"+url.PathEscape(number), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build accrual request: %w", err)
+	}
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("http request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+		var info OrderInfo
+		if err := json.NewDecoder(resp.Body).Decode(&info); err != nil {
+			return nil, fmt.Errorf("failed to decode accrual response: %w", err)
+		}
+		return &info, nil
+	case http.StatusNoContent:
+		return nil, ErrNotRegistered
+	case http.StatusTooManyRequests:
+		return nil, &RateLimitError{RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+	default:
+		return nil, &UnexpectedStatusError{StatusCode: resp.StatusCode}
+	}
+}
+
+func (c *HTTPClient) RegisterOrder(ctx context.Context, order Order) error {
+	return c.post(ctx, "/api/orders", order, http.StatusAccepted)
+}
+
+func (c *HTTPClient) RegisterReward(ctx context.Context, reward Reward) error {
+	return c.post(ctx, "/api/goods", reward, http.StatusOK)
+}
+
+func (c *HTTPClient) post(ctx context.Context, path string, body any, expected int) error {
+	payload, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("failed to encode accrual request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, bytes.NewReader(payload))
+	if err != nil {
+		return fmt.Errorf("failed to build accrual request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return fmt.Errorf("http request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case expected:
+		return nil
+	case http.StatusTooManyRequests:
+		return &RateLimitError{RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"))}
+	default:
+		return &UnexpectedStatusError{StatusCode: resp.StatusCode}
+	}
+}
+
+func parseRetryAfter(value string) time.Duration {
+	sec, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || sec <= 0 {
+		return defaultRetryAfter
+	}
+	return time.Duration(sec) * time.Second
+}
@@ -0,0 +1,38 @@
+package accrualclient
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNotRegistered - заказ не зарегистрирован в системе начислений (204)
+var ErrNotRegistered = errors.New("order is not registered in accrual system")
+
+// RateLimitError - превышено количество запросов (429)
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("accrual rate limit exceeded, retry after %s", e.RetryAfter)
+}
+
+// UnexpectedStatusError - система начислений ответила неожиданным статусом
+type UnexpectedStatusError struct {
+	StatusCode int
+}
+
+func (e *UnexpectedStatusError) Error() string {
+	return fmt.Sprintf("unexpected accrual status: %d", e.StatusCode)
+}
+
+// IsUnavailable - говорит ли ошибка о недоступности системы начислений.
+// 204 и 429 означают, что сервис жив
+func IsUnavailable(err error) bool {
+	if err == nil || errors.Is(err, ErrNotRegistered) {
+		return false
+	}
+	var rateErr *RateLimitError
+	return !errors.As(err, &rateErr)
+}
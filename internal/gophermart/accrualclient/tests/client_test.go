@@ -0,0 +1,179 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-musthave-diploma-tpl/internal/gophermart/accrualclient"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNormalizeURL(t *testing.T) {
+	tests := []struct {
+		address  string
+		expected string
+	}{
+		{"localhost:8081", "http://localhost:8081"},
+		{"http://localhost:8081", "http://localhost:8081"},
+		{"https://accrual.example/", "https://accrual.example"},
+		{`"localhost:8081"`, "http://localhost:8081"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.address, func(t *testing.T) {
+			assert.Equal(t, tt.expected, accrualclient.NormalizeURL(tt.address))
+		})
+	}
+}
+
+func TestHTTPClient_GetOrder(t *testing.T) {
+	tests := []struct {
+		name       string
+		handler    http.HandlerFunc
+		expected   *accrualclient.OrderInfo
+		checkError func(t *testing.T, err error)
+	}{
+		{
+			name: "Processed order with numeric number",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/api/orders/12345678903", r.URL.Path)
+				w.Header().Set("Content-Type", "application/json")
+				w.Write([]byte(`{"order":12345678903,"status":"PROCESSED","accrual":500}`))
+			},
+			expected: &accrualclient.OrderInfo{Order: "12345678903", Status: "PROCESSED", Accrual: 500},
+		},
+		{
+			name: "Order with string number",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(`{"order":"12345678903","status":"PROCESSING"}`))
+			},
+			expected: &accrualclient.OrderInfo{Order: "12345678903", Status: "PROCESSING"},
+		},
+		{
+			name: "Not registered",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNoContent)
+			},
+			checkError: func(t *testing.T, err error) {
+				assert.ErrorIs(t, err, accrualclient.ErrNotRegistered)
+				assert.False(t, accrualclient.IsUnavailable(err))
+			},
+		},
+		{
+			name: "Rate limited",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "7")
+				w.WriteHeader(http.StatusTooManyRequests)
+			},
+			checkError: func(t *testing.T, err error) {
+				var rateErr *accrualclient.RateLimitError
+				require.True(t, errors.As(err, &rateErr))
+				assert.Equal(t, 7*time.Second, rateErr.RetryAfter)
+				assert.False(t, accrualclient.IsUnavailable(err))
+			},
+		},
+		{
+			name: "Internal error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			checkError: func(t *testing.T, err error) {
+				var statusErr *accrualclient.UnexpectedStatusError
+				require.True(t, errors.As(err, &statusErr))
+				assert.Equal(t, http.StatusInternalServerError, statusErr.StatusCode)
+				assert.True(t, accrualclient.IsUnavailable(err))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(tt.handler)
+			defer server.Close()
+
+			client := accrualclient.New(server.URL)
+			info, err := client.GetOrder(context.Background(), "12345678903")
+
+			if tt.checkError != nil {
+				assert.Nil(t, info)
+				tt.checkError(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, info)
+		})
+	}
+}
+
+func TestHTTPClient_GetOrder_ContextCanceled(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done()
+	}))
+	defer server.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	_, err := accrualclient.New(server.URL).GetOrder(ctx, "12345678903")
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestHTTPClient_RegisterOrder(t *testing.T) {
+	var received accrualclient.Order
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/orders", r.URL.Path)
+		assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&received))
+		w.WriteHeader(http.StatusAccepted)
+	}))
+	defer server.Close()
+
+	order := accrualclient.Order{
+		Order: "12345678903",
+		Goods: []accrualclient.Goods{{Description: "Чайник Bork", Price: 7000}},
+	}
+	require.NoError(t, accrualclient.New(server.URL).RegisterOrder(context.Background(), order))
+	assert.Equal(t, order, received)
+}
+
+func TestHTTPClient_RegisterReward(t *testing.T) {
+	tests := []struct {
+		name       string
+		status     int
+		expectFail bool
+	}{
+		{name: "Registered", status: http.StatusOK},
+		{name: "Conflict", status: http.StatusConflict, expectFail: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				assert.Equal(t, "/api/goods", r.URL.Path)
+				w.WriteHeader(tt.status)
+			}))
+			defer server.Close()
+
+			err := accrualclient.New(server.URL).RegisterReward(context.Background(), accrualclient.Reward{
+				Match:      "Bork",
+				Reward:     10,
+				RewardType: accrualclient.RewardTypePercent,
+			})
+			if !tt.expectFail {
+				assert.NoError(t, err)
+				return
+			}
+			var statusErr *accrualclient.UnexpectedStatusError
+			require.True(t, errors.As(err, &statusErr))
+			assert.Equal(t, tt.status, statusErr.StatusCode)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package accrualclient
+
+import (
+	"encoding/json"
+	"strings"
+)
+
+// статусы расчёта начислений
+const (
+	StatusRegistered = "REGISTERED"
+	StatusInvalid    = "INVALID"
+	StatusProcessing = "PROCESSING"
+	StatusProcessed  = "PROCESSED"
+)
+
+// OrderInfo - ответ GET /api/orders/{number}
+type OrderInfo struct {
+	Order   string  `json:"order"`
+	Status  string  `json:"status"`
+	Accrual float64 `json:"accrual,omitempty"`
+}
+
+// UnmarshalJSON - номер заказа может прийти и строкой, и числом
+func (o *OrderInfo) UnmarshalJSON(data []byte) error {
+	var raw struct {
+		Order   json.RawMessage `json:"order"`
+		Status  string          `json:"status"`
+		Accrual float64         `json:"accrual"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+
+	o.Order = strings.Trim(string(raw.Order), `"`)
+	o.Status = raw.Status
+	o.Accrual = raw.Accrual
+	return nil
+}
+
+// Order - регистрация совершённого заказа
+type Order struct {
+	Order string  `json:"order"`
+	Goods []Goods `json:"goods"`
+}
+
+type Goods struct {
+	Description string  `json:"description"`
+	Price       float64 `json:"price"`
+}
+
+// Reward - механика вознаграждения за товар
+type Reward struct {
+	Match      string  `json:"match"`
+	Reward     float64 `json:"reward"`
+	RewardType string  `json:"reward_type"`
+}
+
+const (
+	RewardTypePercent = "%"
+	RewardTypePoints  = "pt"
+)
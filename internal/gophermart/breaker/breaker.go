@@ -0,0 +1,199 @@
+package breaker
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// State - состояние автомата
+type State int
+
+const (
+	StateClosed State = iota
+	StateOpen
+	StateHalfOpen
+)
+
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+var ErrOpen = errors.New("circuit breaker is open")
+
+// Config - настройки автомата
+type Config struct {
+	// сколько ошибок подряд переводят автомат в open
+	FailureThreshold int
+	// сколько автомат остаётся в open перед пробным запросом
+	CoolDown time.Duration
+}
+
+// Breaker - circuit breaker с состояниями closed, open и half-open
+type Breaker struct {
+	name     string
+	cfg      Config
+	onChange func(name string, from, to State)
+
+	mu       sync.Mutex
+	state    State
+	failures int
+	openedAt time.Time
+	probing  bool
+	changed  chan struct{}
+}
+
+func New(name string, cfg Config, onChange func(name string, from, to State)) *Breaker {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 5
+	}
+	if cfg.CoolDown <= 0 {
+		cfg.CoolDown = 30 * time.Second
+	}
+	return &Breaker{
+		name:     name,
+		cfg:      cfg,
+		onChange: onChange,
+		changed:  make(chan struct{}),
+	}
+}
+
+func (b *Breaker) Name() string {
+	return b.name
+}
+
+// State - текущее состояние с учётом истёкшего cool-down
+func (b *Breaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state == StateOpen && time.Since(b.openedAt) >= b.cfg.CoolDown {
+		return StateHalfOpen
+	}
+	return b.state
+}
+
+// Allow - можно ли выполнить запрос. В half-open пропускается только один пробный запрос
+func (b *Breaker) Allow() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case StateClosed:
+		return nil
+	case StateOpen:
+		if time.Since(b.openedAt) < b.cfg.CoolDown {
+			return ErrOpen
+		}
+		b.setState(StateHalfOpen)
+		b.probing = true
+		return nil
+	default:
+		if b.probing {
+			return ErrOpen
+		}
+		b.probing = true
+		return nil
+	}
+}
+
+// Success - запрос прошёл, сервис доступен
+func (b *Breaker) Success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.failures = 0
+	b.probing = false
+	if b.state != StateClosed {
+		b.setState(StateClosed)
+	}
+}
+
+// Failure - запрос завершился ошибкой доступности
+func (b *Breaker) Failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	switch b.state {
+	case StateHalfOpen:
+		b.open()
+	case StateClosed:
+		b.failures++
+		if b.failures >= b.cfg.FailureThreshold {
+			b.open()
+		}
+	}
+}
+
+// Release - запрос не дал ответа о доступности сервиса, например отменён на нашей стороне.
+// Состояние не меняется, в half-open освобождается место для следующего пробного запроса
+func (b *Breaker) Release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+}
+
+// Wait - паркует вызывающего, пока автомат открыт.
+// Возвращается, когда истёк cool-down, автомат сменил состояние или отменён контекст
+func (b *Breaker) Wait(ctx context.Context) error {
+	b.mu.Lock()
+	if b.state == StateClosed {
+		b.mu.Unlock()
+		return nil
+	}
+	changed := b.changed
+	wait := time.Until(b.openedAt.Add(b.cfg.CoolDown))
+	b.mu.Unlock()
+
+	if wait <= 0 {
+		// идёт пробный запрос - ждём его результата
+		wait = b.cfg.CoolDown
+	}
+
+	timer := time.NewTimer(wait)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-changed:
+		return nil
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (b *Breaker) open() {
+	b.failures = 0
+	b.openedAt = time.Now()
+	b.setState(StateOpen)
+}
+
+// setState вызывается под мьютексом
+func (b *Breaker) setState(to State) {
+	from := b.state
+	if from == to {
+		return
+	}
+	b.state = to
+
+	// будим всех, кто ждёт в Wait
+	close(b.changed)
+	b.changed = make(chan struct{})
+
+	if b.onChange != nil {
+		b.onChange(b.name, from, to)
+	}
+}
@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-musthave-diploma-tpl/internal/gophermart/breaker"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBreaker_OpensAfterThreshold(t *testing.T) {
+	b := breaker.New("accrual", breaker.Config{FailureThreshold: 3, CoolDown: time.Hour}, nil)
+
+	for i := 0; i < 2; i++ {
+		require.NoError(t, b.Allow())
+		b.Failure()
+		assert.Equal(t, breaker.StateClosed, b.State())
+	}
+
+	require.NoError(t, b.Allow())
+	b.Failure()
+	assert.Equal(t, breaker.StateOpen, b.State())
+	assert.ErrorIs(t, b.Allow(), breaker.ErrOpen)
+}
+
+func TestBreaker_SuccessResetsFailures(t *testing.T) {
+	b := breaker.New("accrual", breaker.Config{FailureThreshold: 2, CoolDown: time.Hour}, nil)
+
+	b.Failure()
+	b.Success()
+	b.Failure()
+
+	assert.Equal(t, breaker.StateClosed, b.State())
+}
+
+func TestBreaker_HalfOpenProbe(t *testing.T) {
+	var transitions []string
+	b := breaker.New("accrual", breaker.Config{FailureThreshold: 1, CoolDown: 20 * time.Millisecond},
+		func(_ string, from, to breaker.State) {
+			transitions = append(transitions, from.String()+"->"+to.String())
+		})
+
+	b.Failure()
+	assert.ErrorIs(t, b.Allow(), breaker.ErrOpen)
+
+	time.Sleep(30 * time.Millisecond)
+	assert.Equal(t, breaker.StateHalfOpen, b.State())
+
+	// пропускается только один пробный запрос
+	require.NoError(t, b.Allow())
+	assert.ErrorIs(t, b.Allow(), breaker.ErrOpen)
+
+	// неудачная проба снова открывает автомат
+	b.Failure()
+	assert.Equal(t, breaker.StateOpen, b.State())
+
+	time.Sleep(30 * time.Millisecond)
+	require.NoError(t, b.Allow())
+	b.Success()
+	assert.Equal(t, breaker.StateClosed, b.State())
+
+	assert.Equal(t, []string{
+		"closed->open",
+		"open->half-open",
+		"half-open->open",
+		"open->half-open",
+		"half-open->closed",
+	}, transitions)
+}
+
+func TestBreaker_WaitParksUntilCoolDown(t *testing.T) {
+	b := breaker.New("accrual", breaker.Config{FailureThreshold: 1, CoolDown: 50 * time.Millisecond}, nil)
+
+	// закрытый автомат не блокирует
+	require.NoError(t, b.Wait(context.Background()))
+
+	b.Failure()
+	start := time.Now()
+	require.NoError(t, b.Wait(context.Background()))
+	assert.GreaterOrEqual(t, time.Since(start), 40*time.Millisecond)
+}
+
+func TestBreaker_WaitContextCanceled(t *testing.T) {
+	b := breaker.New("accrual", breaker.Config{FailureThreshold: 1, CoolDown: time.Hour}, nil)
+	b.Failure()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+
+	assert.ErrorIs(t, b.Wait(ctx), context.DeadlineExceeded)
+}
+
+func TestBreaker_ReleaseFreesProbe(t *testing.T) {
+	b := breaker.New("accrual", breaker.Config{FailureThreshold: 1, CoolDown: time.Millisecond}, nil)
+
+	b.Failure()
+	time.Sleep(5 * time.Millisecond)
+
+	require.NoError(t, b.Allow())
+	assert.ErrorIs(t, b.Allow(), breaker.ErrOpen)
+
+	// проба отменена без ответа: состояние прежнее, следующая проба допускается
+	b.Release()
+	assert.Equal(t, breaker.StateHalfOpen, b.State())
+	assert.NoError(t, b.Allow())
+}
@@ -0,0 +1,219 @@
+package events
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+
+	"github.com/jackc/pgx/v4"
+	"github.com/jackc/pgx/v4/pgxpool"
+	"go.uber.org/zap"
+)
+
+const (
+	// Channel - канал NOTIFY, в который триггеры публикуют события
+	Channel = "user_events"
+
+	subscriptionBuffer  = 64
+	minReconnectBackoff = time.Second
+	maxReconnectBackoff = 30 * time.Second
+)
+
+// EventStore - хранилище журнала событий
+type EventStore interface {
+	PruneUserEvents(ctx context.Context, before time.Time) (int64, error)
+}
+
+// Broker - раздаёт события из LISTEN user_events подписчикам конкретного пользователя.
+// Работает на каждом инстансе: SSE-клиент может быть подключён к любому
+type Broker struct {
+	dbURI     string
+	store     EventStore
+	retention time.Duration
+	logger    *zap.SugaredLogger
+
+	mu     sync.Mutex
+	subs   map[int]map[*Subscription]struct{}
+	closed bool
+}
+
+// Subscription - подписка на события пользователя.
+// Канал C закрывается, если подписчик не успевает читать или брокер остановлен -
+// клиент переподключается с Last-Event-ID и добирает пропущенное из журнала
+type Subscription struct {
+	C <-chan models.UserEvent
+
+	ch     chan models.UserEvent
+	userID int
+	broker *Broker
+}
+
+func NewBroker(dbURI string, store EventStore, retention time.Duration, logger *zap.SugaredLogger) *Broker {
+	return &Broker{
+		dbURI:     dbURI,
+		store:     store,
+		retention: retention,
+		logger:    logger,
+		subs:      make(map[int]map[*Subscription]struct{}),
+	}
+}
+
+// Subscribe - подписка на события пользователя, после использования обязателен Close
+func (b *Broker) Subscribe(userID int) *Subscription {
+	ch := make(chan models.UserEvent, subscriptionBuffer)
+	sub := &Subscription{C: ch, ch: ch, userID: userID, broker: b}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		close(ch)
+		return sub
+	}
+	if b.subs[userID] == nil {
+		b.subs[userID] = make(map[*Subscription]struct{})
+	}
+	b.subs[userID][sub] = struct{}{}
+	return sub
+}
+
+func (s *Subscription) Close() {
+	s.broker.mu.Lock()
+	defer s.broker.mu.Unlock()
+	s.broker.remove(s)
+}
+
+// Publish - отправляет событие подписчикам его пользователя
+func (b *Broker) Publish(ev models.UserEvent) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	for sub := range b.subs[ev.UserID] {
+		select {
+		case sub.ch <- ev:
+		default:
+			// медленный подписчик отключается, пропущенное он доберёт по Last-Event-ID
+			b.logger.Warnf("Event subscriber of user %d is too slow, disconnecting", ev.UserID)
+			b.remove(sub)
+		}
+	}
+}
+
+// Close - закрывает все подписки и не даёт создавать новые
+func (b *Broker) Close() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.closed = true
+	for _, subs := range b.subs {
+		for sub := range subs {
+			b.remove(sub)
+		}
+	}
+}
+
+// remove - вызывается под b.mu
+func (b *Broker) remove(sub *Subscription) {
+	subs, ok := b.subs[sub.userID]
+	if !ok {
+		return
+	}
+	if _, ok := subs[sub]; !ok {
+		return
+	}
+
+	delete(subs, sub)
+	if len(subs) == 0 {
+		delete(b.subs, sub.userID)
+	}
+	close(sub.ch)
+}
+
+// Run - держит LISTEN user_events и чистит журнал до отмены ctx
+func (b *Broker) Run(ctx context.Context) {
+	if b.retention > 0 {
+		go b.runPruning(ctx)
+	}
+
+	backoff := minReconnectBackoff
+	for ctx.Err() == nil {
+		err := b.listenOnce(ctx, func() {
+			backoff = minReconnectBackoff
+		})
+		if ctx.Err() != nil {
+			break
+		}
+
+		b.logger.Warnf("LISTEN %s connection lost, reconnecting in %s: %v", Channel, backoff, err)
+		select {
+		case <-ctx.Done():
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if backoff > maxReconnectBackoff {
+			backoff = maxReconnectBackoff
+		}
+	}
+}
+
+func (b *Broker) listenOnce(ctx context.Context, onConnected func()) error {
+	cfg, err := pgxpool.ParseConfig(strings.Trim(b.dbURI, `"`))
+	if err != nil {
+		return fmt.Errorf("failed to parse pgx config: %w", err)
+	}
+
+	conn, err := pgx.ConnectConfig(ctx, cfg.ConnConfig)
+	if err != nil {
+		return fmt.Errorf("failed to connect to Postgres for LISTEN: %w", err)
+	}
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+Channel); err != nil {
+		return fmt.Errorf("failed LISTEN: %w", err)
+	}
+
+	b.logger.Infof("Listening for %s notifications", Channel)
+	onConnected()
+
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return fmt.Errorf("WaitForNotification: %w", err)
+		}
+
+		var ev models.UserEvent
+		if err := json.Unmarshal([]byte(n.Payload), &ev); err != nil {
+			b.logger.Errorf("Invalid %s payload %q: %v", Channel, n.Payload, err)
+			continue
+		}
+		b.Publish(ev)
+	}
+}
+
+// runPruning - раз в час удаляет события старше retention
+func (b *Broker) runPruning(ctx context.Context) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := b.store.PruneUserEvents(ctx, time.Now().Add(-b.retention))
+			if err != nil {
+				b.logger.Errorf("Failed to prune user events: %v", err)
+				continue
+			}
+			if n > 0 {
+				b.logger.Infof("Pruned user events: %d", n)
+			}
+		}
+	}
+}
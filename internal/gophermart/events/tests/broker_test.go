@@ -0,0 +1,76 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"go-musthave-diploma-tpl/internal/gophermart/events"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func newBroker() *events.Broker {
+	return events.NewBroker("", nil, 0, zap.NewNop().Sugar())
+}
+
+func TestBroker_PublishToUserSubscribers(t *testing.T) {
+	b := newBroker()
+
+	own := b.Subscribe(1)
+	defer own.Close()
+	other := b.Subscribe(2)
+	defer other.Close()
+
+	b.Publish(models.UserEvent{ID: 10, UserID: 1, Type: models.UserEventOrder, Data: json.RawMessage(`{}`)})
+
+	select {
+	case ev := <-own.C:
+		assert.Equal(t, int64(10), ev.ID)
+	default:
+		t.Fatal("subscriber did not receive event")
+	}
+
+	// события чужого пользователя не приходят
+	select {
+	case ev := <-other.C:
+		t.Fatalf("unexpected event %+v", ev)
+	default:
+	}
+}
+
+func TestBroker_SlowSubscriberDisconnected(t *testing.T) {
+	b := newBroker()
+	sub := b.Subscribe(1)
+	defer sub.Close()
+
+	for i := 0; i < 1000; i++ {
+		b.Publish(models.UserEvent{ID: int64(i + 1), UserID: 1})
+	}
+
+	// буфер переполнен - канал закрыт после последнего доставленного события
+	var received int
+	for range sub.C {
+		received++
+	}
+	assert.Greater(t, received, 0)
+	assert.Less(t, received, 1000)
+}
+
+func TestBroker_Close(t *testing.T) {
+	b := newBroker()
+	sub := b.Subscribe(1)
+
+	b.Close()
+	_, ok := <-sub.C
+	assert.False(t, ok)
+
+	// повторное закрытие подписки после остановки брокера безопасно
+	sub.Close()
+
+	late := b.Subscribe(1)
+	_, ok = <-late.C
+	require.False(t, ok)
+}
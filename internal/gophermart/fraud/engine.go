@@ -0,0 +1,204 @@
+package fraud
+
+import (
+	"context"
+	"encoding/json"
+	"math"
+	"time"
+
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+
+	"go.uber.org/zap"
+)
+
+// Store - активность пользователя и сохранение срабатываний
+type Store interface {
+	FraudActivity(ctx context.Context, userID int, since time.Time) (models.FraudActivity, error)
+	// время события с номером offset (с нуля) от самого старого в окне, nil - событий меньше
+	FraudEventTime(ctx context.Context, userID int, event string, since time.Time, offset int) (*time.Time, error)
+	// открытый флаг по тому же правилу не дублируется, а учитывает повторное срабатывание
+	CreateFraudFlags(ctx context.Context, flags []models.FraudFlag) error
+}
+
+// Input - проверяемое действие
+type Input struct {
+	Event  string
+	UserID int
+	// сколько заказов загружается, для пакетной загрузки больше одного
+	Count int
+	// сумма списания
+	Amount float64
+}
+
+// Decision - итог проверки: самая строгая реакция среди сработавших правил
+type Decision struct {
+	Action     string
+	RetryAfter time.Duration
+	Rules      []string
+}
+
+// Err - ошибка для вызывающего: flag пропускает действие, delay и block - нет
+func (d Decision) Err() error {
+	switch d.Action {
+	case models.FraudActionBlock:
+		return models.ErrActionBlocked
+	case models.FraudActionDelay:
+		return &models.FraudDelayError{RetryAfter: d.RetryAfter}
+	default:
+		return nil
+	}
+}
+
+var severity = map[string]int{
+	models.FraudActionAllow: 0,
+	models.FraudActionFlag:  1,
+	models.FraudActionDelay: 2,
+	models.FraudActionBlock: 3,
+}
+
+// Engine - проверяет действия пользователей по правилам
+type Engine struct {
+	rules  []Rule
+	store  Store
+	logger *zap.SugaredLogger
+}
+
+func NewEngine(rules []Rule, store Store, logger *zap.SugaredLogger) *Engine {
+	return &Engine{rules: rules, store: store, logger: logger}
+}
+
+// Evaluate - проверка действия. Ошибки хранилища не блокируют пользователя:
+// правило, по которому не удалось получить данные, пропускается
+func (e *Engine) Evaluate(ctx context.Context, in Input) Decision {
+	decision := Decision{Action: models.FraudActionAllow}
+	if in.Count <= 0 {
+		in.Count = 1
+	}
+
+	now := time.Now()
+	// правила с одинаковым окном используют одну выборку
+	activity := make(map[Duration]models.FraudActivity)
+
+	var flags []models.FraudFlag
+	for _, rule := range e.rules {
+		if rule.Event() != in.Event {
+			continue
+		}
+
+		a, ok := activity[rule.Window]
+		if !ok {
+			var err error
+			a, err = e.store.FraudActivity(ctx, in.UserID, now.Add(-time.Duration(rule.Window)))
+			if err != nil {
+				e.logger.Errorf("fraud rule %s: %v", rule.Name, err)
+				continue
+			}
+			activity[rule.Window] = a
+		}
+
+		value, hit := rule.check(a, in, now)
+		if !hit {
+			continue
+		}
+
+		decision.Rules = append(decision.Rules, rule.Name)
+		if severity[rule.Action] > severity[decision.Action] {
+			decision.Action = rule.Action
+		}
+		if rule.Action == models.FraudActionDelay {
+			if retry := e.retryAfter(ctx, rule, a, in, value, now); retry > decision.RetryAfter {
+				decision.RetryAfter = retry
+			}
+		}
+
+		details, _ := json.Marshal(map[string]any{
+			"metric":    rule.Metric,
+			"value":     value,
+			"threshold": rule.Threshold,
+			"window":    rule.Window,
+			"count":     in.Count,
+			"amount":    in.Amount,
+		})
+		flags = append(flags, models.FraudFlag{
+			UserID:  in.UserID,
+			Rule:    rule.Name,
+			Event:   in.Event,
+			Action:  rule.Action,
+			Details: details,
+		})
+	}
+
+	if len(flags) > 0 {
+		if err := e.store.CreateFraudFlags(context.WithoutCancel(ctx), flags); err != nil {
+			e.logger.Errorf("failed to save fraud flags: %v", err)
+		}
+		e.logger.Warnf("fraud rules %v hit for user %d on %s: %s", decision.Rules, in.UserID, in.Event, decision.Action)
+	}
+	return decision
+}
+
+// retryAfter - когда правило перестанет срабатывать на то же действие, но не раньше Delay.
+// Иначе повтор по Retry-After снова попадает в окно и delay работает как block
+func (e *Engine) retryAfter(ctx context.Context, rule Rule, a models.FraudActivity, in Input, value float64, now time.Time) time.Duration {
+	window := time.Duration(rule.Window)
+	var clearsAt time.Time
+
+	switch rule.Metric {
+	case MetricUploads, MetricWithdrawals:
+		// из окна должны выйти столько прошлых событий, на сколько превышен порог
+		excess := int(value) - int(math.Floor(rule.Threshold))
+		stored := a.Uploads
+		if rule.Metric == MetricWithdrawals {
+			stored = a.Withdrawals
+		}
+		if excess <= 0 || excess > stored {
+			// одно текущее действие превышает порог, ожидание не поможет
+			break
+		}
+		at, err := e.store.FraudEventTime(ctx, in.UserID, rule.Event(), now.Add(-window), excess-1)
+		if err != nil {
+			e.logger.Errorf("fraud rule %s: %v", rule.Name, err)
+			break
+		}
+		if at != nil {
+			clearsAt = at.Add(window)
+		}
+	case MetricWithdrawalAfterAccrual:
+		if a.LastAccrualAt != nil {
+			clearsAt = a.LastAccrualAt.Add(window)
+		}
+	}
+
+	retry := time.Duration(rule.Delay)
+	if wait := clearsAt.Sub(now); wait > retry {
+		retry = wait.Truncate(time.Second) + time.Second
+	}
+	return retry
+}
+
+// check - значение метрики и сработало ли правило
+func (r Rule) check(a models.FraudActivity, in Input, now time.Time) (float64, bool) {
+	switch r.Metric {
+	case MetricUploads:
+		value := float64(a.Uploads + in.Count)
+		return value, value > r.Threshold
+	case MetricInvalidRatio:
+		if a.FinalOrders == 0 || a.FinalOrders < r.MinOrders {
+			return 0, false
+		}
+		value := float64(a.InvalidOrders) / float64(a.FinalOrders)
+		return value, value >= r.Threshold
+	case MetricWithdrawals:
+		value := float64(a.Withdrawals + 1)
+		return value, value > r.Threshold
+	case MetricWithdrawalAfterAccrual:
+		if a.LastAccrualAt == nil {
+			return 0, false
+		}
+		// секунды с последнего поступления баллов
+		value := now.Sub(*a.LastAccrualAt).Seconds()
+		return value, value < time.Duration(r.Window).Seconds()
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,161 @@
+package fraud
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+)
+
+// метрики, по которым срабатывают правила
+const (
+	// загрузок заказов за окно вместе с текущими, срабатывает при превышении порога
+	MetricUploads = "uploads"
+	// доля INVALID среди заказов в финальном статусе за окно, срабатывает от порога
+	MetricInvalidRatio = "invalid_ratio"
+	// списаний за окно вместе с текущим, срабатывает при превышении порога
+	MetricWithdrawals = "withdrawals"
+	// списание раньше, чем через окно после поступления баллов
+	MetricWithdrawalAfterAccrual = "withdrawal_after_accrual"
+)
+
+var metricEvents = map[string]string{
+	MetricUploads:                models.FraudEventOrderUpload,
+	MetricInvalidRatio:           models.FraudEventOrderUpload,
+	MetricWithdrawals:            models.FraudEventWithdrawal,
+	MetricWithdrawalAfterAccrual: models.FraudEventWithdrawal,
+}
+
+// Duration - длительность в JSON строкой вида "1h30m"
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// Rule - эвристика антифрода. Событие правила определяется метрикой
+type Rule struct {
+	Name      string   `json:"name"`
+	Metric    string   `json:"metric"`
+	Threshold float64  `json:"threshold"`
+	Window    Duration `json:"window"`
+	// для invalid_ratio: сколько заказов должно быть в финальном статусе, чтобы доля что-то значила
+	MinOrders int `json:"min_orders,omitempty"`
+	// flag, delay или block
+	Action string `json:"action"`
+	// минимальная пауза при delay; Retry-After продлевается до момента, когда окно правила освободится
+	Delay Duration `json:"delay,omitempty"`
+}
+
+// Event - событие, на котором проверяется правило
+func (r Rule) Event() string {
+	return metricEvents[r.Metric]
+}
+
+func (r Rule) validate() error {
+	if r.Name == "" {
+		return fmt.Errorf("%w: rule name is required", models.ErrInvalidFraudRuleSet)
+	}
+	if _, ok := metricEvents[r.Metric]; !ok {
+		return fmt.Errorf("%w: rule %s: unknown metric %q", models.ErrInvalidFraudRuleSet, r.Name, r.Metric)
+	}
+	if r.Window <= 0 {
+		return fmt.Errorf("%w: rule %s: window must be positive", models.ErrInvalidFraudRuleSet, r.Name)
+	}
+	switch r.Action {
+	case models.FraudActionFlag, models.FraudActionBlock:
+	case models.FraudActionDelay:
+		if r.Delay <= 0 {
+			return fmt.Errorf("%w: rule %s: delay must be positive", models.ErrInvalidFraudRuleSet, r.Name)
+		}
+	default:
+		return fmt.Errorf("%w: rule %s: unknown action %q", models.ErrInvalidFraudRuleSet, r.Name, r.Action)
+	}
+	return nil
+}
+
+// DefaultRules - правила, когда файл с правилами не задан
+func DefaultRules() []Rule {
+	return []Rule{
+		{
+			Name:      "uploads_per_hour",
+			Metric:    MetricUploads,
+			Threshold: 100,
+			Window:    Duration(time.Hour),
+			Action:    models.FraudActionDelay,
+			Delay:     Duration(15 * time.Minute),
+		},
+		{
+			Name:      "invalid_ratio",
+			Metric:    MetricInvalidRatio,
+			Threshold: 0.5,
+			Window:    Duration(24 * time.Hour),
+			MinOrders: 20,
+			Action:    models.FraudActionFlag,
+		},
+		{
+			Name:   "withdrawal_after_accrual",
+			Metric: MetricWithdrawalAfterAccrual,
+			Window: Duration(time.Minute),
+			Action: models.FraudActionFlag,
+		},
+		{
+			Name:      "withdrawals_per_hour",
+			Metric:    MetricWithdrawals,
+			Threshold: 20,
+			Window:    Duration(time.Hour),
+			Action:    models.FraudActionBlock,
+		},
+	}
+}
+
+// LoadRules - правила из JSON-файла, пустой путь - DefaultRules, пустой массив выключает проверки
+func LoadRules(path string) ([]Rule, error) {
+	if path == "" {
+		return DefaultRules(), nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fraud rules: %w", err)
+	}
+
+	var rules []Rule
+	if err := json.Unmarshal(data, &rules); err != nil {
+		return nil, fmt.Errorf("%w: %v", models.ErrInvalidFraudRuleSet, err)
+	}
+	if err := ValidateRules(rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// ValidateRules - проверка набора правил, имена должны быть уникальны
+func ValidateRules(rules []Rule) error {
+	names := make(map[string]bool, len(rules))
+	for _, r := range rules {
+		if err := r.validate(); err != nil {
+			return err
+		}
+		if names[r.Name] {
+			return fmt.Errorf("%w: duplicate rule %s", models.ErrInvalidFraudRuleSet, r.Name)
+		}
+		names[r.Name] = true
+	}
+	return nil
+}
@@ -0,0 +1,213 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"go-musthave-diploma-tpl/internal/gophermart/fraud"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// fakeStore - активность задаётся тестом, флаги копятся в памяти
+type fakeStore struct {
+	activity models.FraudActivity
+	err      error
+	queries  int
+	flags    []models.FraudFlag
+	// время события, которое должно выйти из окна, и запрошенные номера
+	eventTime *time.Time
+	offsets   []int
+}
+
+func (s *fakeStore) FraudActivity(ctx context.Context, userID int, since time.Time) (models.FraudActivity, error) {
+	s.queries++
+	return s.activity, s.err
+}
+
+func (s *fakeStore) FraudEventTime(ctx context.Context, userID int, event string, since time.Time, offset int) (*time.Time, error) {
+	s.offsets = append(s.offsets, offset)
+	return s.eventTime, nil
+}
+
+func (s *fakeStore) CreateFraudFlags(ctx context.Context, flags []models.FraudFlag) error {
+	s.flags = append(s.flags, flags...)
+	return nil
+}
+
+func newEngine(store fraud.Store) *fraud.Engine {
+	return fraud.NewEngine(fraud.DefaultRules(), store, zap.NewNop().Sugar())
+}
+
+func TestEngine_UploadsPerHour(t *testing.T) {
+	store := &fakeStore{activity: models.FraudActivity{Uploads: 99}}
+	engine := newEngine(store)
+
+	decision := engine.Evaluate(context.Background(), fraud.Input{Event: models.FraudEventOrderUpload, UserID: 1})
+	assert.Equal(t, models.FraudActionAllow, decision.Action)
+	assert.NoError(t, decision.Err())
+	assert.Empty(t, store.flags)
+
+	// пакет из двух номеров превышает порог в 100 загрузок
+	decision = engine.Evaluate(context.Background(), fraud.Input{Event: models.FraudEventOrderUpload, UserID: 1, Count: 2})
+	assert.Equal(t, models.FraudActionDelay, decision.Action)
+	assert.Equal(t, []string{"uploads_per_hour"}, decision.Rules)
+
+	var delayErr *models.FraudDelayError
+	require.ErrorAs(t, decision.Err(), &delayErr)
+	assert.Equal(t, 15*time.Minute, delayErr.RetryAfter)
+	assert.ErrorIs(t, decision.Err(), models.ErrActionDelayed)
+
+	require.Len(t, store.flags, 1)
+	assert.Equal(t, 1, store.flags[0].UserID)
+	assert.Equal(t, models.FraudActionDelay, store.flags[0].Action)
+	assert.JSONEq(t, `{"metric":"uploads","value":101,"threshold":100,"window":"1h0m0s","count":2,"amount":0}`, string(store.flags[0].Details))
+}
+
+func TestEngine_DelayUntilWindowClears(t *testing.T) {
+	// из окна должны выйти 2 самые старые загрузки, вторая из них выйдет через 40 минут
+	second := time.Now().Add(-20 * time.Minute)
+	store := &fakeStore{activity: models.FraudActivity{Uploads: 100}, eventTime: &second}
+	engine := newEngine(store)
+
+	decision := engine.Evaluate(context.Background(), fraud.Input{Event: models.FraudEventOrderUpload, UserID: 1, Count: 2})
+	assert.Equal(t, models.FraudActionDelay, decision.Action)
+	assert.Equal(t, []int{1}, store.offsets)
+	assert.InDelta(t, (40 * time.Minute).Seconds(), decision.RetryAfter.Seconds(), 2)
+
+	// окно освободится раньше минимальной паузы - действует Delay
+	soon := time.Now().Add(-59 * time.Minute)
+	store.eventTime = &soon
+	decision = engine.Evaluate(context.Background(), fraud.Input{Event: models.FraudEventOrderUpload, UserID: 1, Count: 2})
+	assert.Equal(t, 15*time.Minute, decision.RetryAfter)
+}
+
+func TestEngine_InvalidRatio(t *testing.T) {
+	tests := []struct {
+		name     string
+		activity models.FraudActivity
+		want     string
+	}{
+		{name: "Too few orders", activity: models.FraudActivity{FinalOrders: 10, InvalidOrders: 10}, want: models.FraudActionAllow},
+		{name: "Below ratio", activity: models.FraudActivity{FinalOrders: 40, InvalidOrders: 19}, want: models.FraudActionAllow},
+		{name: "Half invalid", activity: models.FraudActivity{FinalOrders: 40, InvalidOrders: 20}, want: models.FraudActionFlag},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeStore{activity: tt.activity}
+			decision := newEngine(store).Evaluate(context.Background(), fraud.Input{Event: models.FraudEventOrderUpload, UserID: 1})
+
+			assert.Equal(t, tt.want, decision.Action)
+			// флаг пропускает действие
+			assert.NoError(t, decision.Err())
+		})
+	}
+}
+
+func TestEngine_Withdrawal(t *testing.T) {
+	justNow := time.Now().Add(-10 * time.Second)
+
+	tests := []struct {
+		name      string
+		activity  models.FraudActivity
+		want      string
+		wantRules []string
+		wantErr   error
+	}{
+		{name: "Quiet account", activity: models.FraudActivity{Withdrawals: 1}, want: models.FraudActionAllow},
+		{
+			name:      "Right after accrual",
+			activity:  models.FraudActivity{LastAccrualAt: &justNow},
+			want:      models.FraudActionFlag,
+			wantRules: []string{"withdrawal_after_accrual"},
+		},
+		{
+			name:      "Block wins over flag",
+			activity:  models.FraudActivity{Withdrawals: 20, LastAccrualAt: &justNow},
+			want:      models.FraudActionBlock,
+			wantRules: []string{"withdrawal_after_accrual", "withdrawals_per_hour"},
+			wantErr:   models.ErrActionBlocked,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fakeStore{activity: tt.activity}
+			decision := newEngine(store).Evaluate(context.Background(), fraud.Input{Event: models.FraudEventWithdrawal, UserID: 1, Amount: 500})
+
+			assert.Equal(t, tt.want, decision.Action)
+			assert.Equal(t, tt.wantRules, decision.Rules)
+			assert.Len(t, store.flags, len(tt.wantRules))
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, decision.Err(), tt.wantErr)
+			} else {
+				assert.NoError(t, decision.Err())
+			}
+		})
+	}
+}
+
+func TestEngine_SharesActivityPerWindow(t *testing.T) {
+	store := &fakeStore{}
+	rules := []fraud.Rule{
+		{Name: "a", Metric: fraud.MetricUploads, Threshold: 10, Window: fraud.Duration(time.Hour), Action: models.FraudActionFlag},
+		{Name: "b", Metric: fraud.MetricInvalidRatio, Threshold: 0.5, Window: fraud.Duration(time.Hour), Action: models.FraudActionFlag},
+		{Name: "c", Metric: fraud.MetricUploads, Threshold: 50, Window: fraud.Duration(24 * time.Hour), Action: models.FraudActionFlag},
+	}
+
+	fraud.NewEngine(rules, store, zap.NewNop().Sugar()).
+		Evaluate(context.Background(), fraud.Input{Event: models.FraudEventOrderUpload, UserID: 1})
+	assert.Equal(t, 2, store.queries)
+}
+
+func TestEngine_StoreErrorAllows(t *testing.T) {
+	store := &fakeStore{err: errors.New("db down")}
+	decision := newEngine(store).Evaluate(context.Background(), fraud.Input{Event: models.FraudEventWithdrawal, UserID: 1})
+
+	assert.Equal(t, models.FraudActionAllow, decision.Action)
+	assert.NoError(t, decision.Err())
+}
+
+func TestLoadRules(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+		return path
+	}
+
+	rules, err := fraud.LoadRules("")
+	require.NoError(t, err)
+	assert.Equal(t, fraud.DefaultRules(), rules)
+
+	rules, err = fraud.LoadRules(write("ok.json", `[
+		{"name":"burst","metric":"uploads","threshold":5,"window":"10m","action":"delay","delay":"30s"}
+	]`))
+	require.NoError(t, err)
+	require.Len(t, rules, 1)
+	assert.Equal(t, fraud.Duration(10*time.Minute), rules[0].Window)
+	assert.Equal(t, models.FraudEventOrderUpload, rules[0].Event())
+
+	rules, err = fraud.LoadRules(write("off.json", `[]`))
+	require.NoError(t, err)
+	assert.Empty(t, rules)
+
+	for name, content := range map[string]string{
+		"metric.json":    `[{"name":"x","metric":"logins","window":"1h","action":"flag"}]`,
+		"action.json":    `[{"name":"x","metric":"uploads","window":"1h","action":"ban"}]`,
+		"delay.json":     `[{"name":"x","metric":"uploads","window":"1h","action":"delay"}]`,
+		"window.json":    `[{"name":"x","metric":"uploads","window":"soon","action":"flag"}]`,
+		"duplicate.json": `[{"name":"x","metric":"uploads","window":"1h","action":"flag"},{"name":"x","metric":"withdrawals","window":"1h","action":"flag"}]`,
+	} {
+		_, err := fraud.LoadRules(write(name, content))
+		assert.ErrorIs(t, err, models.ErrInvalidFraudRuleSet, name)
+	}
+}
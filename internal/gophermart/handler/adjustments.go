@@ -0,0 +1,91 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go-musthave-diploma-tpl/internal/gophermart/middleware"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// CreateAdjustment - ручное начисление или списание баллов пользователю, автор - текущий администратор
+func (h *Handler) CreateAdjustment(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	adminID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, `{"error":"`+ErrUserIsNotAuthenticated.Error()+`"}`, http.StatusUnauthorized)
+		return
+	}
+
+	userID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, `{"error":"`+ErrUserNotFound.Error()+`"}`, http.StatusNotFound)
+		return
+	}
+
+	var req models.AdjustmentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"`+ErrInvalidJSONFormat.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+
+	adminIDint, _ := strconv.Atoi(adminID)
+	adjustment, err := h.svc.AdjustBalance(adminIDint, userID, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidAdjustment):
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+		case errors.Is(err, ErrUserNotFound):
+			http.Error(w, `{"error":"`+ErrUserNotFound.Error()+`"}`, http.StatusNotFound)
+		case errors.Is(err, ErrLackOfFunds):
+			http.Error(w, `{"error":"`+ErrLackOfFunds.Error()+`"}`, http.StatusConflict)
+		default:
+			castomLogger.Infof(ErrInternalServerError.Error(), err.Error())
+			http.Error(w, `{"error":"`+ErrInternalServerError.Error()+`"}`, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	h.audit(r, adminIDint, models.AuditBalanceAdjusted, models.UserTarget(userID), map[string]any{
+		"adjustment_id": adjustment.ID,
+		"amount":        adjustment.Amount,
+		"reason":        adjustment.Reason,
+	})
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(adjustment)
+}
+
+// Adjustments - корректировки баланса пользователя с автором и причиной, ?limit= ограничивает число записей
+func (h *Handler) Adjustments(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, `{"error":"`+ErrUserNotFound.Error()+`"}`, http.StatusNotFound)
+		return
+	}
+
+	var limit int
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 {
+			http.Error(w, `{"error":"`+ErrInvalidRequestFormat.Error()+`"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	adjustments, err := h.svc.Adjustments(userID, limit)
+	if err != nil {
+		castomLogger.Infof(ErrInternalServerError.Error(), err.Error())
+		http.Error(w, `{"error":"`+ErrInternalServerError.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(adjustments)
+}
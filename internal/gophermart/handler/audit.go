@@ -0,0 +1,97 @@
+package httpserver
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"strconv"
+
+	"go-musthave-diploma-tpl/internal/gophermart/middleware"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+)
+
+// audit - запись действия в журнал аудита с IP и User-Agent запроса.
+// actorID 0 - неаутентифицированный запрос. Ошибка записи не прерывает обработку запроса
+func (h *Handler) audit(r *http.Request, actorID int, action, target string, payload any) {
+	event := models.AuditEvent{
+		Action:    action,
+		Target:    target,
+		IP:        clientIP(r),
+		UserAgent: r.UserAgent(),
+	}
+	if actorID > 0 {
+		event.ActorID = &actorID
+	}
+	if payload != nil {
+		raw, err := json.Marshal(payload)
+		if err != nil {
+			castomLogger.Errorf("audit %s: %v", action, err)
+			return
+		}
+		event.Payload = raw
+	}
+
+	// запись не должна теряться, если клиент уже закрыл соединение
+	if err := h.svc.RecordAudit(context.WithoutCancel(r.Context()), event); err != nil {
+		castomLogger.Errorf("audit %s: %v", action, err)
+	}
+}
+
+// adminID - автор действия в админских маршрутах, 0 если не удалось определить
+func adminID(r *http.Request) int {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		return 0
+	}
+	id, _ := strconv.Atoi(userID)
+	return id
+}
+
+// clientIP - адрес клиента без порта
+func clientIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+// AuditEvents - журнал аудита с фильтрами user_id, action, from, to и постраничным выводом
+func (h *Handler) AuditEvents(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	query := r.URL.Query()
+	params, err := parseListParams(query)
+	if err != nil {
+		http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+
+	filter := models.AuditFilter{Action: query.Get("action"), ListParams: params}
+	if v := query.Get("user_id"); v != "" {
+		if filter.UserID, err = strconv.Atoi(v); err != nil || filter.UserID <= 0 {
+			http.Error(w, `{"error":"`+ErrInvalidUserID.Error()+`"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	events, nextCursor, err := h.svc.AuditEvents(filter)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidListParams), errors.Is(err, models.ErrInvalidCursor):
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+		case errors.Is(err, models.ErrAuditUnavailable):
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusServiceUnavailable)
+		default:
+			castomLogger.Infof(ErrInternalServerError.Error(), err.Error())
+			http.Error(w, `{"error":"`+ErrInternalServerError.Error()+`"}`, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	setNextPage(w, r, nextCursor)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(events)
+}
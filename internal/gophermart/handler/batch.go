@@ -0,0 +1,33 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"mime"
+	"strings"
+)
+
+// maxBatchBodySize - ограничение тела пакетной загрузки, с запасом на MaxBatchSize номеров
+const maxBatchBodySize = 1 << 20
+
+// parseOrderNumbers - номера заказов из тела: JSON-массив для application/json, иначе по одному на строку
+func parseOrderNumbers(contentType string, body []byte) ([]string, error) {
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if mediaType == "application/json" {
+		var numbers []string
+		if err := json.Unmarshal(body, &numbers); err != nil {
+			return nil, err
+		}
+		for i := range numbers {
+			numbers[i] = strings.TrimSpace(numbers[i])
+		}
+		return numbers, nil
+	}
+
+	var numbers []string
+	for _, line := range strings.Split(string(body), "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			numbers = append(numbers, line)
+		}
+	}
+	return numbers, nil
+}
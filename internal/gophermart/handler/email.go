@@ -0,0 +1,101 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go-musthave-diploma-tpl/internal/gophermart/middleware"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+)
+
+// writeEmailError - ответ на ошибку операций с почтой
+func writeEmailError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrInvalidEmail), errors.Is(err, ErrEmailTokenInvalid):
+		http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+	case errors.Is(err, ErrUserNotFound):
+		http.Error(w, `{"error":"`+ErrUserNotFound.Error()+`"}`, http.StatusNotFound)
+	case errors.Is(err, ErrEmailTaken):
+		http.Error(w, `{"error":"`+ErrEmailTaken.Error()+`"}`, http.StatusConflict)
+	case errors.Is(err, models.ErrNotifierUnavailable):
+		http.Error(w, `{"error":"`+models.ErrNotifierUnavailable.Error()+`"}`, http.StatusServiceUnavailable)
+	default:
+		castomLogger.Infof(ErrInternalServerError.Error(), err.Error())
+		http.Error(w, `{"error":"`+ErrInternalServerError.Error()+`"}`, http.StatusInternalServerError)
+	}
+}
+
+// SetEmail - новая почта пользователя, на неё отправляется код подтверждения.
+// До подтверждения письма на адрес не отправляются
+func (h *Handler) SetEmail(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, `{"error":"`+ErrUserIsNotAuthenticated.Error()+`"}`, http.StatusUnauthorized)
+		return
+	}
+
+	var req models.EmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"`+ErrInvalidJSONFormat.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+
+	userIDint, _ := strconv.Atoi(userID)
+	if err := h.svc.RequestEmailVerification(r.Context(), userIDint, req); err != nil {
+		writeEmailError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(struct{}{})
+}
+
+// VerifyEmail - подтверждение почты кодом из письма
+func (h *Handler) VerifyEmail(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, `{"error":"`+ErrUserIsNotAuthenticated.Error()+`"}`, http.StatusUnauthorized)
+		return
+	}
+
+	var req models.VerifyEmailRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"`+ErrInvalidJSONFormat.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+
+	userIDint, _ := strconv.Atoi(userID)
+	user, err := h.svc.VerifyEmail(userIDint, req)
+	if err != nil {
+		writeEmailError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(user)
+}
+
+// RemoveEmail - удаление почты, письма пользователю больше не отправляются
+func (h *Handler) RemoveEmail(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, `{"error":"`+ErrUserIsNotAuthenticated.Error()+`"}`, http.StatusUnauthorized)
+		return
+	}
+
+	userIDint, _ := strconv.Atoi(userID)
+	if err := h.svc.RemoveEmail(userIDint); err != nil {
+		writeEmailError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
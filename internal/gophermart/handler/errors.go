@@ -1,6 +1,10 @@
 package httpserver
 
-import "errors"
+import (
+	"errors"
+
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+)
 
 var (
 	ErrLoginAndPasswordRequired = errors.New("login and password are required")
@@ -14,7 +18,29 @@ var (
 	ErrInvalidOrderNumber       = errors.New("invalid order number")
 	ErrLackOfFunds              = errors.New("lack of funds")
 	ErrInvalidNumberFormat      = errors.New("invalid number format")
-	ErrInvalidLoginOrPassword   = errors.New("invalid login or password")
+	ErrInvalidLoginOrPassword   = models.ErrInvalidLoginOrPassword
 	ErrInvalidRequestFormat     = errors.New("invalid request format")
 	ErrLoginAlreadyExists       = errors.New("login already exists")
+	ErrForbidden                = errors.New("forbidden")
+	ErrDeadLetterNotFound       = errors.New("dead letter not found")
+	ErrOrderNotFound            = errors.New("order not found")
+	ErrWebhookNotFound          = errors.New("webhook not found")
+	ErrInvalidReferralCode      = errors.New("invalid referral code")
+	ErrUserNotFound             = errors.New("user not found")
+	ErrPromoCodeNotFound        = models.ErrPromoCodeNotFound
+	ErrPromoCodeExists          = errors.New("promo code already exists")
+	ErrPromoCodeExpired         = errors.New("promo code expired")
+	ErrPromoCodeExhausted       = errors.New("promo code fully redeemed")
+	ErrPromoCodeLimitReached    = errors.New("promo code redemption limit reached")
+	ErrFraudFlagNotFound        = errors.New("fraud flag not found")
+	ErrFraudFlagAlreadyReviewed = errors.New("fraud flag already reviewed")
+	ErrHouseholdNotFound        = errors.New("household not found")
+	ErrAlreadyInHousehold       = errors.New("user already belongs to a household")
+	ErrHouseholdMemberNotFound  = errors.New("household member not found")
+	ErrInviteNotFound           = errors.New("household invite not found")
+	ErrInviteExists             = errors.New("household invite already pending")
+	ErrOwnerCannotLeave         = errors.New("household owner cannot leave")
+	ErrWithdrawNotPermitted     = errors.New("withdrawal from household wallet not permitted")
+	ErrEmailTokenInvalid        = errors.New("invalid or expired verification code")
+	ErrEmailTaken               = errors.New("email already verified by another user")
 )
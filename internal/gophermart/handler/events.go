@@ -0,0 +1,124 @@
+package httpserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"go-musthave-diploma-tpl/internal/gophermart/middleware"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+)
+
+const (
+	// eventsHeartbeat - комментарий-пинг, чтобы прокси не закрывали простаивающее соединение
+	eventsHeartbeat = 15 * time.Second
+	// eventsReplayPage - размер порции при догоне пропущенных событий
+	eventsReplayPage = 500
+)
+
+// Events - SSE-поток смены статусов заказов, личного баланса и баланса общего кошелька семьи.
+// С заголовком Last-Event-ID сначала отдаются пропущенные события из журнала
+func (h *Handler) Events(w http.ResponseWriter, r *http.Request) {
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, `{"error":"`+ErrUserIsNotAuthenticated.Error()+`"}`, http.StatusUnauthorized)
+		return
+	}
+	userIDint, _ := strconv.Atoi(userID)
+
+	var lastID int64
+	if v := r.Header.Get("Last-Event-ID"); v != "" {
+		lastID, err = strconv.ParseInt(v, 10, 64)
+		if err != nil || lastID < 0 {
+			http.Error(w, `{"error":"invalid Last-Event-ID"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	// подписка раньше догона: события, пришедшие во время догона, не потеряются
+	sub, err := h.svc.SubscribeEvents(userIDint)
+	if err != nil {
+		if errors.Is(err, models.ErrEventsUnavailable) {
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusServiceUnavailable)
+			return
+		}
+		http.Error(w, `{"error":"`+ErrInternalServerError.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+	defer sub.Close()
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	if lastID > 0 {
+		for {
+			missed, err := h.svc.UserEventsSince(userIDint, lastID, eventsReplayPage)
+			if err != nil {
+				castomLogger.Infof(ErrInternalServerError.Error(), err.Error())
+				return
+			}
+			for _, ev := range missed {
+				if err := writeEvent(w, ev); err != nil {
+					return
+				}
+				lastID = ev.ID
+			}
+			if len(missed) < eventsReplayPage {
+				break
+			}
+		}
+	}
+	if err := rc.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(eventsHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case ev, ok := <-sub.C:
+			if !ok {
+				// брокер остановлен или клиент не успевал читать - пусть переподключится
+				return
+			}
+			// id событий пользователя выдаются в порядке фиксации (миграция 23),
+			// поэтому меньший id - уже отправленное при догоне событие
+			if ev.ID <= lastID {
+				continue
+			}
+			if err := writeEvent(w, ev); err != nil {
+				return
+			}
+			lastID = ev.ID
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": ping\n\n"); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeEvent - событие в формате SSE, data в одну строку
+func writeEvent(w io.Writer, ev models.UserEvent) error {
+	var data bytes.Buffer
+	if err := json.Compact(&data, ev.Data); err != nil {
+		return err
+	}
+	_, err := fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", ev.ID, ev.Type, data.Bytes())
+	return err
+}
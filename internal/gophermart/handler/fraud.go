@@ -0,0 +1,108 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"net/http"
+	"strconv"
+
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// writeFraudError - ответ на действие, не пропущенное правилами антифрода, false если ошибка другая
+func writeFraudError(w http.ResponseWriter, err error) bool {
+	var delayErr *models.FraudDelayError
+	switch {
+	case errors.As(err, &delayErr):
+		w.Header().Set("Retry-After", strconv.Itoa(int(math.Ceil(delayErr.RetryAfter.Seconds()))))
+		http.Error(w, `{"error":"`+models.ErrActionDelayed.Error()+`"}`, http.StatusTooManyRequests)
+	case errors.Is(err, models.ErrActionBlocked):
+		http.Error(w, `{"error":"`+models.ErrActionBlocked.Error()+`"}`, http.StatusForbidden)
+	default:
+		return false
+	}
+	return true
+}
+
+// FraudFlags - флаги антифрода, фильтры user_id и status, постраничный вывод
+func (h *Handler) FraudFlags(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	query := r.URL.Query()
+	params, err := parseListParams(query)
+	if err != nil {
+		http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+
+	filter := models.FraudFlagFilter{ListParams: params}
+	if v := query.Get("user_id"); v != "" {
+		if filter.UserID, err = strconv.Atoi(v); err != nil || filter.UserID <= 0 {
+			http.Error(w, `{"error":"`+ErrInvalidUserID.Error()+`"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	flags, nextCursor, err := h.svc.FraudFlags(filter)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidListParams) || errors.Is(err, models.ErrInvalidCursor) {
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+			return
+		}
+		castomLogger.Infof(ErrInternalServerError.Error(), err.Error())
+		http.Error(w, `{"error":"`+ErrInternalServerError.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	setNextPage(w, r, nextCursor)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(flags)
+}
+
+// ReviewFraudFlag - закрытие флага: DISMISSED для ложного срабатывания, CONFIRMED для мошенничества.
+// Повторное решение по закрытому флагу - 409
+func (h *Handler) ReviewFraudFlag(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	flagID, err := strconv.ParseInt(chi.URLParam(r, "id"), 10, 64)
+	if err != nil {
+		http.Error(w, `{"error":"`+ErrFraudFlagNotFound.Error()+`"}`, http.StatusNotFound)
+		return
+	}
+
+	var review models.FraudReview
+	if err := json.NewDecoder(r.Body).Decode(&review); err != nil {
+		http.Error(w, `{"error":"`+ErrInvalidJSONFormat.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+
+	reviewer := adminID(r)
+	flag, err := h.svc.ReviewFraudFlag(flagID, reviewer, review)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidFraudReview):
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+		case errors.Is(err, ErrFraudFlagNotFound):
+			http.Error(w, `{"error":"`+ErrFraudFlagNotFound.Error()+`"}`, http.StatusNotFound)
+		case errors.Is(err, ErrFraudFlagAlreadyReviewed):
+			http.Error(w, `{"error":"`+ErrFraudFlagAlreadyReviewed.Error()+`"}`, http.StatusConflict)
+		default:
+			castomLogger.Infof(ErrInternalServerError.Error(), err.Error())
+			http.Error(w, `{"error":"`+ErrInternalServerError.Error()+`"}`, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	h.audit(r, reviewer, models.AuditFraudFlagReviewed, models.UserTarget(flag.UserID), map[string]any{
+		"flag_id": flag.ID,
+		"rule":    flag.Rule,
+		"status":  flag.Status,
+		"note":    flag.ReviewNote,
+	})
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(flag)
+}
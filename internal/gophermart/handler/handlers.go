@@ -1,6 +1,7 @@
 package httpserver
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"io"
@@ -12,7 +13,8 @@ import (
 	"go-musthave-diploma-tpl/internal/gophermart/models"
 	"go-musthave-diploma-tpl/internal/gophermart/service"
 
-	pgk "go-musthave-diploma-tpl/pkg"
+	"github.com/go-chi/chi/v5"
+
 	logger "go-musthave-diploma-tpl/pkg/runtime/logger"
 )
 
@@ -45,8 +47,12 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := h.svc.RegisterUser(req.Login, req.Password)
+	user, err := h.svc.RegisterReferredUser(req.Login, req.Password, req.ReferralCode)
 	if err != nil {
+		if errors.Is(err, ErrInvalidReferralCode) {
+			http.Error(w, `{"error":"`+ErrInvalidReferralCode.Error()+`"}`, http.StatusBadRequest)
+			return
+		}
 		switch err.Error() {
 		case "login already exists":
 			http.Error(w, `{"error":"login already taken"}`, http.StatusConflict)
@@ -58,6 +64,11 @@ func (h *Handler) Register(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.audit(r, user.ID, models.AuditUserRegistered, models.UserTarget(user.ID), map[string]any{
+		"login":         user.Login,
+		"referral_code": strings.TrimSpace(req.ReferralCode),
+	})
+
 	middleware.SetEncryptedCookie(w, strconv.Itoa(user.ID))
 	w.WriteHeader(http.StatusOK)
 }
@@ -84,6 +95,7 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 	user, err := h.svc.LoginUser(req.Login, req.Password)
 	if err != nil {
 		if errors.Is(err, ErrInvalidLoginOrPassword) {
+			h.audit(r, 0, models.AuditUserLoginFailed, models.LoginTarget(req.Login), nil)
 			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusUnauthorized)
 		} else {
 			castomLogger.Infof(ErrInternalServerError.Error(), err.Error())
@@ -92,6 +104,8 @@ func (h *Handler) Login(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.audit(r, user.ID, models.AuditUserLogin, models.UserTarget(user.ID), nil)
+
 	middleware.SetEncryptedCookie(w, strconv.Itoa(user.ID))
 	w.WriteHeader(http.StatusOK)
 }
@@ -119,7 +133,7 @@ func (h *Handler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if !pgk.ContainsOnlyDigits(orderNumber) || !pgk.ValidateLuhn(orderNumber) {
+	if !h.svc.ValidateOrderNumber(orderNumber) {
 		http.Error(w, `{"error":"invalid order number"}`, http.StatusUnprocessableEntity)
 		return
 	}
@@ -129,6 +143,9 @@ func (h *Handler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 	// Создаём заказ в базе
 	err = h.svc.CreateOrder(userIDint, orderNumber)
 	if err != nil {
+		if writeFraudError(w, err) {
+			return
+		}
 		switch {
 		case errors.Is(err, ErrDuplicateOrder):
 			w.WriteHeader(http.StatusOK)
@@ -143,10 +160,70 @@ func (h *Handler) CreateOrder(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	h.audit(r, userIDint, models.AuditOrderUploaded, models.OrderTarget(orderNumber), nil)
+
 	w.WriteHeader(http.StatusAccepted)
 	json.NewEncoder(w).Encode(map[string]string{"message": "order accepted for processing"})
 }
 
+// CreateOrders - пакетная загрузка номеров заказов: JSON-массив строк или номера по одному на строку
+func (h *Handler) CreateOrders(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, `{"error":"`+ErrUserIsNotAuthenticated.Error()+`"}`, http.StatusUnauthorized)
+		return
+	}
+
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBatchBodySize))
+	if err != nil {
+		http.Error(w, `{"error":"failed to read request body"}`, http.StatusRequestEntityTooLarge)
+		return
+	}
+	defer r.Body.Close()
+
+	numbers, err := parseOrderNumbers(r.Header.Get("Content-Type"), body)
+	if err != nil {
+		http.Error(w, `{"error":"`+ErrInvalidRequestFormat.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+
+	userIDint, _ := strconv.Atoi(userID)
+	results, err := h.svc.CreateOrders(userIDint, numbers)
+	if err != nil {
+		if writeFraudError(w, err) {
+			return
+		}
+		switch {
+		case errors.Is(err, models.ErrEmptyBatch):
+			http.Error(w, `{"error":"`+ErrOrderNumberRequired.Error()+`"}`, http.StatusBadRequest)
+		case errors.Is(err, models.ErrBatchTooLarge):
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusRequestEntityTooLarge)
+		default:
+			castomLogger.Infof(ErrInternalServerError.Error(), err.Error())
+			http.Error(w, `{"error":"`+ErrInternalServerError.Error()+`"}`, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	accepted := make([]string, 0, len(results))
+	for _, res := range results {
+		if res.Result == models.BatchResultAccepted {
+			accepted = append(accepted, res.Number)
+		}
+	}
+	if len(accepted) > 0 {
+		h.audit(r, userIDint, models.AuditOrdersBatch, models.UserTarget(userIDint), map[string]any{
+			"submitted": len(results),
+			"accepted":  accepted,
+		})
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(results)
+}
+
 func (h *Handler) GetOrders(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -157,6 +234,11 @@ func (h *Handler) GetOrders(w http.ResponseWriter, r *http.Request) {
 	}
 
 	userIDint, _ := strconv.Atoi(userID)
+	if isListQuery(r.URL.Query()) {
+		h.getOrdersPage(w, r, userIDint)
+		return
+	}
+
 	result, err := h.svc.GetOrders(userIDint)
 	if err != nil {
 		http.Error(w, ErrInternalServerError.Error(), http.StatusInternalServerError)
@@ -173,6 +255,65 @@ func (h *Handler) GetOrders(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(result)
 }
 
+// getOrdersPage - список заказов с пагинацией и фильтрами, пустая страница - []
+func (h *Handler) getOrdersPage(w http.ResponseWriter, r *http.Request, userID int) {
+	params, err := parseListParams(r.URL.Query())
+	if err != nil {
+		http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+
+	orders, nextCursor, err := h.svc.GetOrdersPage(userID, params)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidListParams) || errors.Is(err, models.ErrInvalidCursor) {
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+			return
+		}
+		http.Error(w, ErrInternalServerError.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	if orders == nil {
+		orders = []models.Order{}
+	}
+
+	setNextPage(w, r, nextCursor)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(orders)
+}
+
+func (h *Handler) GetOrder(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, `{"error":"`+ErrUserIsNotAuthenticated.Error()+`"}`, http.StatusUnauthorized)
+		return
+	}
+
+	number := chi.URLParam(r, "number")
+	if number == "" {
+		http.Error(w, `{"error":"`+ErrOrderNumberRequired.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+
+	userIDint, _ := strconv.Atoi(userID)
+	order, err := h.svc.GetOrder(userIDint, number)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrOrderNotFound):
+			http.Error(w, `{"error":"`+ErrOrderNotFound.Error()+`"}`, http.StatusNotFound)
+		default:
+			castomLogger.Infof(ErrInternalServerError.Error(), err.Error())
+			http.Error(w, `{"error":"`+ErrInternalServerError.Error()+`"}`, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(order)
+}
+
 func (h *Handler) GetBalance(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -182,13 +323,24 @@ func (h *Handler) GetBalance(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// ?wallet=household - баланс общего кошелька семьи
+	wallet, err := models.ParseWallet(r.URL.Query().Get("wallet"))
+	if err != nil {
+		http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+
 	userIDint, err := strconv.Atoi(userID)
 	if err != nil {
 		http.Error(w, `{"error":"invalid user ID"}`, http.StatusInternalServerError)
 		return
 	}
-	result, err := h.svc.GetBalance(userIDint)
+	result, err := h.svc.WalletBalance(userIDint, wallet)
 	if err != nil {
+		if errors.Is(err, ErrHouseholdNotFound) {
+			http.Error(w, `{"error":"`+ErrHouseholdNotFound.Error()+`"}`, http.StatusNotFound)
+			return
+		}
 		http.Error(w, `{"error":"`+ErrInternalServerError.Error()+`"}`, http.StatusInternalServerError)
 		return
 	}
@@ -211,6 +363,13 @@ func (h *Handler) Withdraw(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// ?wallet=household - списание из общего кошелька семьи
+	wallet, err := models.ParseWallet(r.URL.Query().Get("wallet"))
+	if err != nil {
+		http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+
 	var withdraw models.WithdrawBalance
 	if err := json.NewDecoder(r.Body).Decode(&withdraw); err != nil {
 		http.Error(w, `{"error":"`+ErrInvalidJSONFormat.Error()+`"}`, http.StatusBadRequest)
@@ -221,7 +380,7 @@ func (h *Handler) Withdraw(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, `{"error":"sum must be positive"}`, http.StatusBadRequest)
 		return
 	}
-	if !pgk.ContainsOnlyDigits(withdraw.Order) || !pgk.ValidateLuhn(withdraw.Order) {
+	if !h.svc.ValidateOrderNumber(withdraw.Order) {
 		http.Error(w, `{"error":"invalid order number"}`, http.StatusUnprocessableEntity)
 		return
 	}
@@ -231,13 +390,20 @@ func (h *Handler) Withdraw(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, `{"error":"invalid user ID"}`, http.StatusInternalServerError)
 		return
 	}
-	err = h.svc.Withdraw(userIDint, withdraw)
+	err = h.svc.WithdrawFrom(userIDint, wallet, withdraw)
 	if err != nil {
+		if writeFraudError(w, err) {
+			return
+		}
 		switch err {
 		case ErrInvalidOrderNumber:
 			http.Error(w, `{"error":"`+ErrInvalidOrderNumber.Error()+`"}`, http.StatusUnprocessableEntity)
 		case ErrLackOfFunds:
 			http.Error(w, `{"error":"`+ErrLackOfFunds.Error()+`"}`, http.StatusPaymentRequired)
+		case ErrHouseholdNotFound:
+			http.Error(w, `{"error":"`+ErrHouseholdNotFound.Error()+`"}`, http.StatusNotFound)
+		case ErrWithdrawNotPermitted:
+			http.Error(w, `{"error":"`+ErrWithdrawNotPermitted.Error()+`"}`, http.StatusForbidden)
 		default:
 			castomLogger.Infof(ErrInternalServerError.Error(), err.Error())
 			http.Error(w, `{"error":"`+ErrInternalServerError.Error()+`"}`, http.StatusInternalServerError)
@@ -245,6 +411,19 @@ func (h *Handler) Withdraw(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	payload := map[string]any{"sum": withdraw.Sum}
+	if wallet == models.WalletHousehold {
+		payload["wallet"] = wallet
+	}
+	h.audit(r, userIDint, models.AuditWithdrawal, models.OrderTarget(withdraw.Order), payload)
+
+	// письмо о крупном списании не задерживает ответ
+	go func(ctx context.Context) {
+		if err := h.svc.AlertLargeWithdrawal(ctx, userIDint, withdraw); err != nil {
+			castomLogger.Infof("failed to send withdrawal alert: %s", err.Error())
+		}
+	}(context.WithoutCancel(r.Context()))
+
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(struct{}{})
 }
@@ -259,6 +438,11 @@ func (h *Handler) Withdrawals(w http.ResponseWriter, r *http.Request) {
 	}
 
 	userIDint, _ := strconv.Atoi(userID)
+	if isListQuery(r.URL.Query()) {
+		h.withdrawalsPage(w, r, userIDint)
+		return
+	}
+
 	withdrawals, err := h.svc.Withdrawals(userIDint)
 	if err != nil {
 		http.Error(w, `{"error":"`+ErrInternalServerError.Error()+`"}`, http.StatusInternalServerError)
@@ -273,3 +457,117 @@ func (h *Handler) Withdrawals(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(withdrawals)
 }
+
+// withdrawalsPage - список списаний с пагинацией и фильтрами, пустая страница - [], как и у заказов
+func (h *Handler) withdrawalsPage(w http.ResponseWriter, r *http.Request, userID int) {
+	params, err := parseListParams(r.URL.Query())
+	if err != nil {
+		http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+
+	withdrawals, nextCursor, err := h.svc.WithdrawalsPage(userID, params)
+	if err != nil {
+		if errors.Is(err, models.ErrInvalidListParams) || errors.Is(err, models.ErrInvalidCursor) {
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+			return
+		}
+		http.Error(w, `{"error":"`+ErrInternalServerError.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	setNextPage(w, r, nextCursor)
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(withdrawals)
+}
+
+// Referrals - реферальный код пользователя и статистика приглашений
+func (h *Handler) Referrals(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, `{"error":"`+ErrUserIsNotAuthenticated.Error()+`"}`, http.StatusUnauthorized)
+		return
+	}
+
+	userIDint, _ := strconv.Atoi(userID)
+	stats, err := h.svc.ReferralStats(userIDint)
+	if err != nil {
+		castomLogger.Infof(ErrInternalServerError.Error(), err.Error())
+		http.Error(w, `{"error":"`+ErrInternalServerError.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(stats)
+}
+
+func (h *Handler) Health(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(h.svc.Health())
+}
+
+func (h *Handler) DeadLetters(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	deadLetters, err := h.svc.DeadLetters()
+	if err != nil {
+		castomLogger.Infof(ErrInternalServerError.Error(), err.Error())
+		http.Error(w, `{"error":"`+ErrInternalServerError.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	if len(deadLetters) == 0 {
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode([]interface{}{})
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(deadLetters)
+}
+
+func (h *Handler) RequeueDeadLetter(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	number := chi.URLParam(r, "number")
+	if number == "" {
+		http.Error(w, `{"error":"`+ErrOrderNumberRequired.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+
+	err := h.svc.RequeueDeadLetter(number)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrDeadLetterNotFound):
+			http.Error(w, `{"error":"`+ErrDeadLetterNotFound.Error()+`"}`, http.StatusNotFound)
+		default:
+			castomLogger.Infof(ErrInternalServerError.Error(), err.Error())
+			http.Error(w, `{"error":"`+ErrInternalServerError.Error()+`"}`, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	h.audit(r, adminID(r), models.AuditOrderRequeued, models.OrderTarget(number), nil)
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]int64{"requeued": 1})
+}
+
+func (h *Handler) RequeueAllDeadLetters(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	n, err := h.svc.RequeueAllDeadLetters()
+	if err != nil {
+		castomLogger.Infof(ErrInternalServerError.Error(), err.Error())
+		http.Error(w, `{"error":"`+ErrInternalServerError.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	h.audit(r, adminID(r), models.AuditOrderRequeued, "", map[string]int64{"requeued": n})
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(map[string]int64{"requeued": n})
+}
@@ -0,0 +1,291 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go-musthave-diploma-tpl/internal/gophermart/middleware"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+
+	"github.com/go-chi/chi/v5"
+)
+
+// writeHouseholdError - ответ на ошибку операций с семьёй
+func writeHouseholdError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, models.ErrInvalidHousehold):
+		http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+	case errors.Is(err, ErrForbidden):
+		http.Error(w, `{"error":"`+ErrForbidden.Error()+`"}`, http.StatusForbidden)
+	case errors.Is(err, ErrHouseholdNotFound), errors.Is(err, ErrHouseholdMemberNotFound),
+		errors.Is(err, ErrInviteNotFound), errors.Is(err, ErrUserNotFound):
+		http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusNotFound)
+	case errors.Is(err, ErrAlreadyInHousehold), errors.Is(err, ErrInviteExists), errors.Is(err, ErrOwnerCannotLeave):
+		http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusConflict)
+	default:
+		castomLogger.Infof(ErrInternalServerError.Error(), err.Error())
+		http.Error(w, `{"error":"`+ErrInternalServerError.Error()+`"}`, http.StatusInternalServerError)
+	}
+}
+
+// CreateHousehold - создание семьи, текущий пользователь становится владельцем
+func (h *Handler) CreateHousehold(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, `{"error":"`+ErrUserIsNotAuthenticated.Error()+`"}`, http.StatusUnauthorized)
+		return
+	}
+
+	var req models.HouseholdRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"`+ErrInvalidJSONFormat.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+
+	userIDint, _ := strconv.Atoi(userID)
+	household, err := h.svc.CreateHousehold(userIDint, req)
+	if err != nil {
+		writeHouseholdError(w, err)
+		return
+	}
+
+	h.audit(r, userIDint, models.AuditHouseholdCreated, models.HouseholdTarget(household.ID), map[string]any{
+		"name": household.Name,
+	})
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(household)
+}
+
+// Household - семья пользователя с участниками и балансом общего кошелька
+func (h *Handler) Household(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, `{"error":"`+ErrUserIsNotAuthenticated.Error()+`"}`, http.StatusUnauthorized)
+		return
+	}
+
+	userIDint, _ := strconv.Atoi(userID)
+	household, err := h.svc.Household(userIDint)
+	if err != nil {
+		writeHouseholdError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(household)
+}
+
+// InviteToHousehold - приглашение пользователя по логину, доступно владельцу
+func (h *Handler) InviteToHousehold(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, `{"error":"`+ErrUserIsNotAuthenticated.Error()+`"}`, http.StatusUnauthorized)
+		return
+	}
+
+	var req models.InviteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"`+ErrInvalidJSONFormat.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+
+	userIDint, _ := strconv.Atoi(userID)
+	invite, err := h.svc.InviteToHousehold(userIDint, req)
+	if err != nil {
+		writeHouseholdError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(invite)
+}
+
+// HouseholdInvites - приглашения текущего пользователя, ожидающие ответа
+func (h *Handler) HouseholdInvites(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, `{"error":"`+ErrUserIsNotAuthenticated.Error()+`"}`, http.StatusUnauthorized)
+		return
+	}
+
+	userIDint, _ := strconv.Atoi(userID)
+	invites, err := h.svc.HouseholdInvites(userIDint)
+	if err != nil {
+		writeHouseholdError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(invites)
+}
+
+// AcceptHouseholdInvite - вступление в семью по приглашению
+func (h *Handler) AcceptHouseholdInvite(w http.ResponseWriter, r *http.Request) {
+	h.respondHouseholdInvite(w, r, true)
+}
+
+// DeclineHouseholdInvite - отказ от приглашения
+func (h *Handler) DeclineHouseholdInvite(w http.ResponseWriter, r *http.Request) {
+	h.respondHouseholdInvite(w, r, false)
+}
+
+func (h *Handler) respondHouseholdInvite(w http.ResponseWriter, r *http.Request, accept bool) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, `{"error":"`+ErrUserIsNotAuthenticated.Error()+`"}`, http.StatusUnauthorized)
+		return
+	}
+
+	inviteID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, `{"error":"`+ErrInviteNotFound.Error()+`"}`, http.StatusNotFound)
+		return
+	}
+
+	userIDint, _ := strconv.Atoi(userID)
+	invite, err := h.svc.RespondHouseholdInvite(userIDint, inviteID, accept)
+	if err != nil {
+		writeHouseholdError(w, err)
+		return
+	}
+
+	if accept {
+		h.audit(r, userIDint, models.AuditHouseholdJoined, models.HouseholdTarget(invite.HouseholdID), map[string]any{
+			"invite_id":  invite.ID,
+			"invited_by": invite.InvitedBy,
+		})
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(invite)
+}
+
+// UpdateHouseholdMember - права участника на списание из общего кошелька, доступно владельцу
+func (h *Handler) UpdateHouseholdMember(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, `{"error":"`+ErrUserIsNotAuthenticated.Error()+`"}`, http.StatusUnauthorized)
+		return
+	}
+
+	memberID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, `{"error":"`+ErrHouseholdMemberNotFound.Error()+`"}`, http.StatusNotFound)
+		return
+	}
+
+	var perms models.MemberPermissions
+	if err := json.NewDecoder(r.Body).Decode(&perms); err != nil {
+		http.Error(w, `{"error":"`+ErrInvalidJSONFormat.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+
+	userIDint, _ := strconv.Atoi(userID)
+	member, err := h.svc.UpdateHouseholdMember(userIDint, memberID, perms)
+	if err != nil {
+		writeHouseholdError(w, err)
+		return
+	}
+
+	h.audit(r, userIDint, models.AuditHouseholdUpdated, models.UserTarget(memberID), perms)
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(member)
+}
+
+// RemoveHouseholdMember - владелец исключает участника, участник с собственным id выходит из семьи
+func (h *Handler) RemoveHouseholdMember(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, `{"error":"`+ErrUserIsNotAuthenticated.Error()+`"}`, http.StatusUnauthorized)
+		return
+	}
+
+	memberID, err := strconv.Atoi(chi.URLParam(r, "id"))
+	if err != nil {
+		http.Error(w, `{"error":"`+ErrHouseholdMemberNotFound.Error()+`"}`, http.StatusNotFound)
+		return
+	}
+
+	userIDint, _ := strconv.Atoi(userID)
+	if err := h.svc.RemoveHouseholdMember(userIDint, memberID); err != nil {
+		writeHouseholdError(w, err)
+		return
+	}
+
+	h.audit(r, userIDint, models.AuditHouseholdRemoved, models.UserTarget(memberID), nil)
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// SetAccrualSharing - зачислять ли начисления текущего пользователя в общий кошелёк
+func (h *Handler) SetAccrualSharing(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, `{"error":"`+ErrUserIsNotAuthenticated.Error()+`"}`, http.StatusUnauthorized)
+		return
+	}
+
+	var req models.SharingRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"`+ErrInvalidJSONFormat.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+
+	userIDint, _ := strconv.Atoi(userID)
+	if err := h.svc.SetAccrualSharing(userIDint, req.ShareAccruals); err != nil {
+		writeHouseholdError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(req)
+}
+
+// HouseholdWithdrawals - списания из общего кошелька с авторами, ?limit= ограничивает число записей
+func (h *Handler) HouseholdWithdrawals(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, `{"error":"`+ErrUserIsNotAuthenticated.Error()+`"}`, http.StatusUnauthorized)
+		return
+	}
+
+	var limit int
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 {
+			http.Error(w, `{"error":"`+ErrInvalidRequestFormat.Error()+`"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	userIDint, _ := strconv.Atoi(userID)
+	withdrawals, err := h.svc.HouseholdWithdrawals(userIDint, limit)
+	if err != nil {
+		writeHouseholdError(w, err)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(withdrawals)
+}
@@ -0,0 +1,85 @@
+package httpserver
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+)
+
+// listParamNames - параметры, включающие постраничный режим списков
+var listParamNames = []string{"limit", "cursor", "status", "from", "to", "order"}
+
+// isListQuery - запрошен ли постраничный режим. Прочие параметры, например
+// для сброса кеша, не меняют ответ без параметров
+func isListQuery(query url.Values) bool {
+	for _, name := range listParamNames {
+		if _, ok := query[name]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// parseListParams - параметры постраничной выборки из query: limit, cursor, status, from, to, order
+func parseListParams(query url.Values) (models.ListParams, error) {
+	var params models.ListParams
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 {
+			return params, fmt.Errorf("%w: limit", models.ErrInvalidListParams)
+		}
+		params.Limit = limit
+	}
+
+	params.Cursor = query.Get("cursor")
+
+	for _, v := range query["status"] {
+		for _, status := range strings.Split(v, ",") {
+			if status = strings.TrimSpace(status); status != "" {
+				params.Statuses = append(params.Statuses, strings.ToUpper(status))
+			}
+		}
+	}
+
+	for name, dst := range map[string]**time.Time{"from": &params.From, "to": &params.To} {
+		v := query.Get(name)
+		if v == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return params, fmt.Errorf("%w: %s", models.ErrInvalidListParams, name)
+		}
+		*dst = &t
+	}
+
+	switch strings.ToLower(query.Get("order")) {
+	case "", "desc":
+	case "asc":
+		params.Ascending = true
+	default:
+		return params, fmt.Errorf("%w: order", models.ErrInvalidListParams)
+	}
+
+	return params, nil
+}
+
+// setNextPage - отдаёт курсор следующей страницы в X-Next-Cursor и ссылку на неё в Link
+func setNextPage(w http.ResponseWriter, r *http.Request, cursor string) {
+	if cursor == "" {
+		return
+	}
+
+	query := r.URL.Query()
+	query.Set("cursor", cursor)
+	next := url.URL{Path: r.URL.Path, RawQuery: query.Encode()}
+
+	w.Header().Set("X-Next-Cursor", cursor)
+	w.Header().Set("Link", fmt.Sprintf(`<%s>; rel="next"`, next.String()))
+}
@@ -0,0 +1,81 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"go-musthave-diploma-tpl/internal/gophermart/middleware"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+)
+
+// Notifications - входящие уведомления и число непрочитанных,
+// ?unread=true - только непрочитанные, ?limit= ограничивает число записей
+func (h *Handler) Notifications(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, `{"error":"`+ErrUserIsNotAuthenticated.Error()+`"}`, http.StatusUnauthorized)
+		return
+	}
+
+	query := r.URL.Query()
+	var limit int
+	if v := query.Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 {
+			http.Error(w, `{"error":"`+ErrInvalidRequestFormat.Error()+`"}`, http.StatusBadRequest)
+			return
+		}
+	}
+	var unreadOnly bool
+	if v := query.Get("unread"); v != "" {
+		if unreadOnly, err = strconv.ParseBool(v); err != nil {
+			http.Error(w, `{"error":"`+ErrInvalidRequestFormat.Error()+`"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	userIDint, _ := strconv.Atoi(userID)
+	notifications, err := h.svc.Notifications(userIDint, unreadOnly, limit)
+	if err != nil {
+		castomLogger.Infof(ErrInternalServerError.Error(), err.Error())
+		http.Error(w, `{"error":"`+ErrInternalServerError.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(notifications)
+}
+
+// ReadNotifications - отметка уведомлений прочитанными, без тела или без ids - всех
+func (h *Handler) ReadNotifications(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, `{"error":"`+ErrUserIsNotAuthenticated.Error()+`"}`, http.StatusUnauthorized)
+		return
+	}
+
+	var req models.ReadNotificationsRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, `{"error":"`+ErrInvalidJSONFormat.Error()+`"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	userIDint, _ := strconv.Atoi(userID)
+	unread, err := h.svc.MarkNotificationsRead(userIDint, req.IDs)
+	if err != nil {
+		castomLogger.Infof(ErrInternalServerError.Error(), err.Error())
+		http.Error(w, `{"error":"`+ErrInternalServerError.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(struct {
+		Unread int `json:"unread"`
+	}{unread})
+}
@@ -0,0 +1,48 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go-musthave-diploma-tpl/internal/gophermart/middleware"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+)
+
+// Me - профиль текущего пользователя со статистикой, ?months= задаёт окно помесячной статистики
+func (h *Handler) Me(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, `{"error":"`+ErrUserIsNotAuthenticated.Error()+`"}`, http.StatusUnauthorized)
+		return
+	}
+
+	var months int
+	if v := r.URL.Query().Get("months"); v != "" {
+		if months, err = strconv.Atoi(v); err != nil || months <= 0 {
+			http.Error(w, `{"error":"`+ErrInvalidRequestFormat.Error()+`"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	userIDint, _ := strconv.Atoi(userID)
+	profile, err := h.svc.Profile(userIDint, months)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidProfileMonths):
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+		case errors.Is(err, ErrUserNotFound):
+			http.Error(w, `{"error":"`+ErrUserNotFound.Error()+`"}`, http.StatusNotFound)
+		default:
+			castomLogger.Infof(ErrInternalServerError.Error(), err.Error())
+			http.Error(w, `{"error":"`+ErrInternalServerError.Error()+`"}`, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(profile)
+}
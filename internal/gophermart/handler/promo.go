@@ -0,0 +1,146 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"go-musthave-diploma-tpl/internal/gophermart/middleware"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+)
+
+type redeemPromoCodeRequest struct {
+	Code string `json:"code"`
+}
+
+// RedeemPromoCode - погашение промокода, баллы зачисляются на счёт сразу
+func (h *Handler) RedeemPromoCode(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, `{"error":"`+ErrUserIsNotAuthenticated.Error()+`"}`, http.StatusUnauthorized)
+		return
+	}
+
+	var req redeemPromoCodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, `{"error":"`+ErrInvalidJSONFormat.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+
+	userIDint, _ := strconv.Atoi(userID)
+	redemption, err := h.svc.RedeemPromoCode(userIDint, req.Code)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidPromoCode):
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+		case errors.Is(err, models.ErrTooManyRedeemAttempts):
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusTooManyRequests)
+		case errors.Is(err, ErrPromoCodeNotFound):
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusNotFound)
+		case errors.Is(err, ErrPromoCodeExpired), errors.Is(err, ErrPromoCodeExhausted):
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusGone)
+		case errors.Is(err, ErrPromoCodeLimitReached):
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusConflict)
+		default:
+			castomLogger.Infof(ErrInternalServerError.Error(), err.Error())
+			http.Error(w, `{"error":"`+ErrInternalServerError.Error()+`"}`, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	h.audit(r, userIDint, models.AuditPromoRedeemed, models.PromoTarget(redemption.Code), map[string]any{
+		"amount": redemption.Amount,
+	})
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(redemption)
+}
+
+// BalanceHistory - движения по счёту, ?limit= ограничивает число записей
+func (h *Handler) BalanceHistory(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, `{"error":"`+ErrUserIsNotAuthenticated.Error()+`"}`, http.StatusUnauthorized)
+		return
+	}
+
+	var limit int
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if limit, err = strconv.Atoi(v); err != nil || limit <= 0 {
+			http.Error(w, `{"error":"`+ErrInvalidRequestFormat.Error()+`"}`, http.StatusBadRequest)
+			return
+		}
+	}
+
+	userIDint, _ := strconv.Atoi(userID)
+	entries, err := h.svc.BalanceHistory(userIDint, limit)
+	if err != nil {
+		castomLogger.Infof(ErrInternalServerError.Error(), err.Error())
+		http.Error(w, `{"error":"`+ErrInternalServerError.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(entries)
+}
+
+// CreatePromoCode - создание промокода администратором
+func (h *Handler) CreatePromoCode(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	userID, err := middleware.GetUserID(r.Context())
+	if err != nil {
+		http.Error(w, `{"error":"`+ErrUserIsNotAuthenticated.Error()+`"}`, http.StatusUnauthorized)
+		return
+	}
+
+	var promo models.PromoCode
+	if err := json.NewDecoder(r.Body).Decode(&promo); err != nil {
+		http.Error(w, `{"error":"`+ErrInvalidJSONFormat.Error()+`"}`, http.StatusBadRequest)
+		return
+	}
+
+	adminID, _ := strconv.Atoi(userID)
+	created, err := h.svc.CreatePromoCode(adminID, promo)
+	if err != nil {
+		switch {
+		case errors.Is(err, models.ErrInvalidPromoCode):
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusBadRequest)
+		case errors.Is(err, ErrPromoCodeExists):
+			http.Error(w, `{"error":"`+err.Error()+`"}`, http.StatusConflict)
+		default:
+			castomLogger.Infof(ErrInternalServerError.Error(), err.Error())
+			http.Error(w, `{"error":"`+ErrInternalServerError.Error()+`"}`, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	h.audit(r, adminID, models.AuditPromoCreated, models.PromoTarget(created.Code), map[string]any{
+		"value":           created.Value,
+		"max_redemptions": created.MaxRedemptions,
+		"per_user_limit":  created.PerUserLimit,
+		"expires_at":      created.ExpiresAt,
+	})
+
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+func (h *Handler) PromoCodes(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	codes, err := h.svc.PromoCodes()
+	if err != nil {
+		castomLogger.Infof(ErrInternalServerError.Error(), err.Error())
+		http.Error(w, `{"error":"`+ErrInternalServerError.Error()+`"}`, http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(codes)
+}
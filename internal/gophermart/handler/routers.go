@@ -1,6 +1,7 @@
 package httpserver
 
 import (
+	"expvar"
 	"net/http"
 
 	middleware "go-musthave-diploma-tpl/internal/gophermart/middleware"
@@ -18,6 +19,8 @@ func NewRouter(h *Handler, svc *service.GofemartService) http.Handler {
 	// публичные маршруты
 	r.Post("/api/user/register", h.Register)
 	r.Post("/api/user/login", h.Login)
+	// состояние сервиса и circuit breaker системы начислений
+	r.Get("/api/health", h.Health)
 
 	// защищённые маршруты
 	r.Route("/api", func(r chi.Router) {
@@ -25,20 +28,114 @@ func NewRouter(h *Handler, svc *service.GofemartService) http.Handler {
 			// подключаем проверку cookie
 			r.Use(middleware.AccessCookieMiddleware(svc))
 
+			// профиль пользователя со статистикой заказов и начислений
+			r.Get("/me", h.Me)
 			r.Route("/orders", func(r chi.Router) {
 				// загрузка пользователем номера заказа для расчёта
 				r.Post("/", h.CreateOrder)
+				// пакетная загрузка номеров заказов с результатом по каждому
+				r.Post("/batch", h.CreateOrders)
 				// получение списка загруженных пользователем номеров заказов, статусов их обработки и информации о начислениях
 				r.Get("/", h.GetOrders)
+				// заказ с текущим начислением и историей смены статусов
+				r.Get("/{number}", h.GetOrder)
 			})
 			r.Route("/balance", func(r chi.Router) {
-				// получение текущего баланса счёта баллов лояльности пользователя
+				// получение текущего баланса счёта баллов лояльности пользователя, ?wallet=household - общего кошелька семьи
 				r.Get("/", h.GetBalance)
-				// запрос на списание баллов с накопительного счёта в счёт оплаты нового заказа
+				// запрос на списание баллов с накопительного счёта в счёт оплаты нового заказа, ?wallet=household - из общего кошелька
 				r.Post("/withdraw", h.Withdraw)
+				// погашение промокода
+				r.Post("/redeem", h.RedeemPromoCode)
+				// начисления, корректировки и списания
+				r.Get("/history", h.BalanceHistory)
 			})
 			// получение информации о выводе средств с накопительного счёта пользователем
 			r.Get("/withdrawals", h.Withdrawals)
+			r.Route("/household", func(r chi.Router) {
+				// создание семьи с общим кошельком
+				r.Post("/", h.CreateHousehold)
+				// семья, участники и баланс общего кошелька
+				r.Get("/", h.Household)
+				// приглашение пользователя по логину
+				r.Post("/invites", h.InviteToHousehold)
+				// приглашения текущего пользователя
+				r.Get("/invites", h.HouseholdInvites)
+				r.Post("/invites/{id}/accept", h.AcceptHouseholdInvite)
+				r.Post("/invites/{id}/decline", h.DeclineHouseholdInvite)
+				// права участника на списание из общего кошелька
+				r.Put("/members/{id}", h.UpdateHouseholdMember)
+				// исключение участника или выход из семьи
+				r.Delete("/members/{id}", h.RemoveHouseholdMember)
+				// зачисление своих начислений в общий кошелёк
+				r.Put("/sharing", h.SetAccrualSharing)
+				// списания из общего кошелька
+				r.Get("/withdrawals", h.HouseholdWithdrawals)
+			})
+			r.Route("/email", func(r chi.Router) {
+				// новая почта, на неё отправляется код подтверждения
+				r.Put("/", h.SetEmail)
+				// подтверждение почты кодом из письма
+				r.Post("/verify", h.VerifyEmail)
+				// удаление почты
+				r.Delete("/", h.RemoveEmail)
+			})
+			// реферальный код и статистика приглашений
+			r.Get("/referrals", h.Referrals)
+			r.Route("/notifications", func(r chi.Router) {
+				// входящие уведомления и число непрочитанных
+				r.Get("/", h.Notifications)
+				// отметка уведомлений прочитанными
+				r.Post("/read", h.ReadNotifications)
+			})
+			// SSE-поток смены статусов заказов и баланса
+			r.Get("/events", h.Events)
+			r.Route("/webhooks", func(r chi.Router) {
+				// регистрация вебхука, в ответе секрет подписи
+				r.Post("/", h.CreateWebhook)
+				// вебхуки пользователя
+				r.Get("/", h.Webhooks)
+				// удаление вебхука
+				r.Delete("/{id}", h.DeleteWebhook)
+				// журнал доставок вебхука
+				r.Get("/{id}/deliveries", h.WebhookDeliveries)
+			})
+		})
+		r.Route("/admin", func(r chi.Router) {
+			// только пользователи из списка администраторов
+			r.Use(middleware.AccessCookieMiddleware(svc))
+			r.Use(middleware.AdminMiddleware(svc))
+
+			r.Route("/dead-letters", func(r chi.Router) {
+				// заказы, обработка которых окончательно провалилась
+				r.Get("/", h.DeadLetters)
+				// вернуть в обработку все заказы
+				r.Post("/requeue", h.RequeueAllDeadLetters)
+				// вернуть в обработку один заказ
+				r.Post("/{number}/requeue", h.RequeueDeadLetter)
+			})
+			r.Route("/promo-codes", func(r chi.Router) {
+				// создание промокода кампании
+				r.Post("/", h.CreatePromoCode)
+				// промокоды и число погашений
+				r.Get("/", h.PromoCodes)
+			})
+			r.Route("/users/{id}/adjustments", func(r chi.Router) {
+				// ручное начисление или списание баллов с причиной
+				r.Post("/", h.CreateAdjustment)
+				// корректировки баланса пользователя с автором
+				r.Get("/", h.Adjustments)
+			})
+			r.Route("/fraud-flags", func(r chi.Router) {
+				// срабатывания правил антифрода
+				r.Get("/", h.FraudFlags)
+				// решение по флагу
+				r.Post("/{id}/review", h.ReviewFraudFlag)
+			})
+			// журнал аудита с фильтрами по пользователю, действию и времени
+			r.Get("/audit", h.AuditEvents)
+			// метрики expvar, в том числе размер DLQ
+			r.Get("/metrics", expvar.Handler().ServeHTTP)
 		})
 	})
 	return r
@@ -0,0 +1,104 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+	"go-musthave-diploma-tpl/internal/gophermart/middleware"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+	"go-musthave-diploma-tpl/internal/gophermart/service"
+	mocks "go-musthave-diploma-tpl/internal/gophermart/service/mocks"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateAdjustmentHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	h := handler.NewHandler(service.NewGofemartService(mockRepo, "http://localhost:8081"))
+
+	tests := []struct {
+		name           string
+		userID         string
+		body           string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name:   "Credit",
+			userID: "5",
+			body:   `{"type":"credit","amount":50,"reason":" late delivery "}`,
+			mockSetup: func() {
+				mockRepo.EXPECT().CreateAdjustment(1, 5, 50.0, "late delivery").
+					Return(&models.BalanceAdjustment{ID: 1, UserID: 5, Amount: 50, Kind: models.AdjustmentManual}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:   "Debit is stored negative",
+			userID: "5",
+			body:   `{"type":"debit","amount":20,"reason":"fraud"}`,
+			mockSetup: func() {
+				mockRepo.EXPECT().CreateAdjustment(1, 5, -20.0, "fraud").
+					Return(&models.BalanceAdjustment{ID: 2, UserID: 5, Amount: -20, Kind: models.AdjustmentManual}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Reason is required",
+			userID:         "5",
+			body:           `{"type":"credit","amount":50,"reason":"  "}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Unknown type",
+			userID:         "5",
+			body:           `{"type":"gift","amount":50,"reason":"x"}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Debit over balance",
+			userID: "5",
+			body:   `{"type":"debit","amount":1000,"reason":"clawback"}`,
+			mockSetup: func() {
+				mockRepo.EXPECT().CreateAdjustment(1, 5, -1000.0, "clawback").Return(nil, handler.ErrLackOfFunds)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:   "Unknown user",
+			userID: "404",
+			body:   `{"type":"credit","amount":50,"reason":"x"}`,
+			mockSetup: func() {
+				mockRepo.EXPECT().CreateAdjustment(1, 404, 50.0, "x").Return(nil, handler.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("POST", "/api/admin/users/"+tt.userID+"/adjustments", strings.NewReader(tt.body))
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.userID)
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			ctx = context.WithValue(ctx, middleware.UserIDKey, "1")
+			rr := httptest.NewRecorder()
+			h.CreateAdjustment(rr, req.WithContext(ctx))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
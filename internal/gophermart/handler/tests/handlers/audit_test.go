@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+	"go-musthave-diploma-tpl/internal/gophermart/middleware"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+	"go-musthave-diploma-tpl/internal/gophermart/service"
+	mocks "go-musthave-diploma-tpl/internal/gophermart/service/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLoginHandler_Audit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	mockAudit := mocks.NewMockAuditLog(ctrl)
+	svc := service.NewGofemartService(mockRepo, "http://localhost:8081")
+	svc.SetAuditLog(mockAudit)
+	h := handler.NewHandler(svc)
+
+	tests := []struct {
+		name           string
+		user           *models.User
+		expectedStatus int
+		check          func(t *testing.T, event models.AuditEvent)
+	}{
+		{
+			name:           "Successful login",
+			user:           &models.User{ID: 7, Login: "testuser"},
+			expectedStatus: http.StatusOK,
+			check: func(t *testing.T, event models.AuditEvent) {
+				assert.Equal(t, models.AuditUserLogin, event.Action)
+				require.NotNil(t, event.ActorID)
+				assert.Equal(t, 7, *event.ActorID)
+				assert.Equal(t, "user:7", event.Target)
+			},
+		},
+		{
+			name:           "Wrong password",
+			expectedStatus: http.StatusUnauthorized,
+			check: func(t *testing.T, event models.AuditEvent) {
+				assert.Equal(t, models.AuditUserLoginFailed, event.Action)
+				assert.Nil(t, event.ActorID)
+				assert.Equal(t, "login:testuser", event.Target)
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.EXPECT().GetUserByLoginAndPassword("testuser", "secret").Return(tt.user, nil)
+
+			var recorded models.AuditEvent
+			mockAudit.EXPECT().RecordAudit(gomock.Any(), gomock.Any()).
+				DoAndReturn(func(_ context.Context, event models.AuditEvent) error {
+					recorded = event
+					return nil
+				})
+
+			req := httptest.NewRequest("POST", "/api/user/login", bytes.NewBufferString(`{"login":"testuser","password":"secret"}`))
+			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("User-Agent", "gophermart-test")
+			req.RemoteAddr = "203.0.113.5:41234"
+			rr := httptest.NewRecorder()
+			h.Login(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.Equal(t, "203.0.113.5", recorded.IP)
+			assert.Equal(t, "gophermart-test", recorded.UserAgent)
+			tt.check(t, recorded)
+		})
+	}
+}
+
+func TestWithdrawHandler_Audit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	mockAudit := mocks.NewMockAuditLog(ctrl)
+	svc := service.NewGofemartService(mockRepo, "http://localhost:8081")
+	svc.SetAuditLog(mockAudit)
+	h := handler.NewHandler(svc)
+
+	withdraw := models.WithdrawBalance{Order: "2377225624", Sum: 751}
+	mockRepo.EXPECT().Withdraw(1, withdraw).Return(nil)
+	mockAudit.EXPECT().RecordAudit(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, event models.AuditEvent) error {
+			assert.Equal(t, models.AuditWithdrawal, event.Action)
+			assert.Equal(t, "order:2377225624", event.Target)
+			assert.JSONEq(t, `{"sum":751}`, string(event.Payload))
+			return nil
+		})
+
+	req := httptest.NewRequest("POST", "/api/user/balance/withdraw", bytes.NewBufferString(`{"order":"2377225624","sum":751}`))
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "1"))
+	rr := httptest.NewRecorder()
+	h.Withdraw(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+}
+
+func TestAuditEventsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	mockAudit := mocks.NewMockAuditLog(ctrl)
+	svc := service.NewGofemartService(mockRepo, "http://localhost:8081")
+	svc.SetAuditLog(mockAudit)
+	h := handler.NewHandler(svc)
+
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockAudit.EXPECT().AuditEvents(gomock.Any()).
+		DoAndReturn(func(filter models.AuditFilter) ([]models.AuditEvent, string, error) {
+			assert.Equal(t, 5, filter.UserID)
+			assert.Equal(t, models.AuditWithdrawal, filter.Action)
+			require.NotNil(t, filter.From)
+			assert.True(t, from.Equal(*filter.From))
+			assert.Equal(t, 10, filter.Limit)
+			return []models.AuditEvent{{ID: 3, Action: models.AuditWithdrawal, CreatedAt: from}}, "next", nil
+		})
+
+	req := httptest.NewRequest("GET", "/api/admin/audit?user_id=5&action=balance.withdrawn&from=2026-01-01T00:00:00Z&limit=10", nil)
+	rr := httptest.NewRecorder()
+	h.AuditEvents(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "next", rr.Header().Get("X-Next-Cursor"))
+	var events []models.AuditEvent
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &events))
+	assert.Len(t, events, 1)
+
+	for _, query := range []string{"user_id=abc", "status=NEW", "limit=0"} {
+		req := httptest.NewRequest("GET", "/api/admin/audit?"+query, nil)
+		rr := httptest.NewRecorder()
+		h.AuditEvents(rr, req)
+		assert.Equal(t, http.StatusBadRequest, rr.Code, query)
+	}
+}
+
+func TestAuditEventsHandler_Unavailable(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	h := handler.NewHandler(service.NewGofemartService(mocks.NewMockGofemartRepo(ctrl), "http://localhost:8081"))
+
+	req := httptest.NewRequest("GET", "/api/admin/audit", nil)
+	rr := httptest.NewRecorder()
+	h.AuditEvents(rr, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, rr.Code)
+}
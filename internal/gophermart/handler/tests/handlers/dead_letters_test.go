@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+	"go-musthave-diploma-tpl/internal/gophermart/service"
+	mocks "go-musthave-diploma-tpl/internal/gophermart/service/mocks"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_DeadLetters(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	svc := service.NewGofemartService(mockRepo, "http://localhost:8081")
+	h := handler.NewHandler(svc)
+
+	deadAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		setupMock      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name: "List dead letters",
+			setupMock: func() {
+				mockRepo.EXPECT().DeadLetters().Return([]models.DeadLetter{{
+					Number:    "12345678903",
+					UserID:    1,
+					Status:    "NEW",
+					LastError: "db update failed",
+					Attempts:  10,
+					DeadAt:    deadAt,
+				}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[{"number":"12345678903","user_id":1,"status":"NEW","last_error":"db update failed","attempts":10,"dead_at":"2026-10-01T12:00:00Z"}]`,
+		},
+		{
+			name: "Empty queue",
+			setupMock: func() {
+				mockRepo.EXPECT().DeadLetters().Return(nil, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `[]`,
+		},
+		{
+			name: "Database error",
+			setupMock: func() {
+				mockRepo.EXPECT().DeadLetters().Return(nil, fmt.Errorf("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMock()
+
+			req := httptest.NewRequest("GET", "/api/admin/dead-letters", nil)
+			rr := httptest.NewRecorder()
+
+			h.DeadLetters(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+		})
+	}
+}
+
+func TestHandler_RequeueDeadLetter(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	svc := service.NewGofemartService(mockRepo, "http://localhost:8081")
+	h := handler.NewHandler(svc)
+
+	tests := []struct {
+		name           string
+		number         string
+		setupMock      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "Requeued",
+			number: "12345678903",
+			setupMock: func() {
+				mockRepo.EXPECT().RequeueDeadLetter("12345678903").Return(nil)
+				mockRepo.EXPECT().DeadLetterCount().Return(int64(0), nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody:   `{"requeued":1}`,
+		},
+		{
+			name:   "Not in dead-letter queue",
+			number: "12345678903",
+			setupMock: func() {
+				mockRepo.EXPECT().RequeueDeadLetter("12345678903").Return(handler.ErrDeadLetterNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"dead letter not found"}`,
+		},
+		{
+			name:   "Database error",
+			number: "12345678903",
+			setupMock: func() {
+				mockRepo.EXPECT().RequeueDeadLetter("12345678903").Return(fmt.Errorf("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"internal server error"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMock()
+
+			req := httptest.NewRequest("POST", "/api/admin/dead-letters/"+tt.number+"/requeue", nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("number", tt.number)
+			req = req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+			rr := httptest.NewRecorder()
+
+			h.RequeueDeadLetter(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+		})
+	}
+}
+
+func TestHandler_RequeueAllDeadLetters(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	svc := service.NewGofemartService(mockRepo, "http://localhost:8081")
+	h := handler.NewHandler(svc)
+
+	mockRepo.EXPECT().RequeueAllDeadLetters().Return(int64(3), nil)
+	mockRepo.EXPECT().DeadLetterCount().Return(int64(0), nil)
+
+	req := httptest.NewRequest("POST", "/api/admin/dead-letters/requeue", nil)
+	rr := httptest.NewRecorder()
+
+	h.RequeueAllDeadLetters(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `{"requeued":3}`, rr.Body.String())
+}
@@ -0,0 +1,131 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+	"go-musthave-diploma-tpl/internal/gophermart/middleware"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+	"go-musthave-diploma-tpl/internal/gophermart/notify"
+	"go-musthave-diploma-tpl/internal/gophermart/service"
+	mocks "go-musthave-diploma-tpl/internal/gophermart/service/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+type nopNotifier struct{}
+
+func (nopNotifier) Send(context.Context, notify.Message) error { return nil }
+
+func TestSetEmailHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	svc := service.NewGofemartService(mockRepo, "http://localhost:8081")
+	h := handler.NewHandler(svc)
+
+	send := func(body string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("PUT", "/api/user/email", strings.NewReader(body))
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "1"))
+		rr := httptest.NewRecorder()
+		h.SetEmail(rr, req)
+		return rr
+	}
+
+	// без канала доставки подтвердить почту невозможно
+	assert.Equal(t, http.StatusServiceUnavailable, send(`{"email":"alice@example.com"}`).Code)
+
+	svc.SetNotifier(nopNotifier{}, 0)
+
+	mockRepo.EXPECT().SetUserEmail(1, "alice@example.com", gomock.Any(), gomock.Any()).Return(nil)
+	assert.Equal(t, http.StatusAccepted, send(`{"email":"alice@example.com"}`).Code)
+
+	mockRepo.EXPECT().SetUserEmail(1, "bob@example.com", gomock.Any(), gomock.Any()).Return(handler.ErrUserNotFound)
+	assert.Equal(t, http.StatusNotFound, send(`{"email":"bob@example.com"}`).Code)
+
+	assert.Equal(t, http.StatusBadRequest, send(`{"email":"not-an-email"}`).Code)
+	assert.Equal(t, http.StatusBadRequest, send(`{"email":`).Code)
+}
+
+func TestVerifyEmailHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	h := handler.NewHandler(service.NewGofemartService(mockRepo, "http://localhost:8081"))
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name: "Verified",
+			body: `{"token":"abc"}`,
+			mockSetup: func() {
+				mockRepo.EXPECT().VerifyEmail(1, gomock.Any()).
+					Return(&models.User{ID: 1, Login: "alice", Email: "alice@example.com"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Invalid token",
+			body: `{"token":"abc"}`,
+			mockSetup: func() {
+				mockRepo.EXPECT().VerifyEmail(1, gomock.Any()).Return(nil, handler.ErrEmailTokenInvalid)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Email taken",
+			body: `{"token":"abc"}`,
+			mockSetup: func() {
+				mockRepo.EXPECT().VerifyEmail(1, gomock.Any()).Return(nil, handler.ErrEmailTaken)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "Empty token",
+			body:           `{}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("POST", "/api/user/email/verify", strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "1"))
+			rr := httptest.NewRecorder()
+			h.VerifyEmail(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestRemoveEmailHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	h := handler.NewHandler(service.NewGofemartService(mockRepo, "http://localhost:8081"))
+
+	mockRepo.EXPECT().RemoveUserEmail(1).Return(nil)
+
+	req := httptest.NewRequest("DELETE", "/api/user/email", nil)
+	req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "1"))
+	rr := httptest.NewRecorder()
+	h.RemoveEmail(rr, req)
+
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+}
@@ -0,0 +1,91 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"go-musthave-diploma-tpl/internal/gophermart/events"
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+	"go-musthave-diploma-tpl/internal/gophermart/middleware"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+	"go-musthave-diploma-tpl/internal/gophermart/service"
+	mocks "go-musthave-diploma-tpl/internal/gophermart/service/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestEventsHandler_ResumeAndStream(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	svc := service.NewGofemartService(mockRepo, "http://localhost:8081")
+	broker := events.NewBroker("", nil, 0, zap.NewNop().Sugar())
+	svc.SetEventBroker(broker)
+	h := handler.NewHandler(svc)
+
+	orderEvent := models.UserEvent{ID: 6, UserID: 1, Type: models.UserEventOrder, Data: json.RawMessage(`{"number": "12345678903", "status": "PROCESSED"}`)}
+	balanceEvent := models.UserEvent{ID: 7, UserID: 1, Type: models.UserEventBalance, Data: json.RawMessage(`{"current":500,"withdrawn":0}`)}
+
+	mockRepo.EXPECT().UserEventsSince(1, int64(5), gomock.Any()).
+		DoAndReturn(func(int, int64, int) ([]models.UserEvent, error) {
+			// пока идёт догон, то же событие приходит и через подписку - повторно оно не отдаётся
+			broker.Publish(orderEvent)
+			broker.Publish(balanceEvent)
+			broker.Close()
+			return []models.UserEvent{orderEvent}, nil
+		})
+
+	req := httptest.NewRequest("GET", "/api/user/events", nil)
+	req.Header.Set("Last-Event-ID", "5")
+	req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "1"))
+	rr := httptest.NewRecorder()
+	h.Events(rr, req)
+
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "text/event-stream", rr.Header().Get("Content-Type"))
+	assert.Equal(t,
+		"id: 6\nevent: order\ndata: {\"number\":\"12345678903\",\"status\":\"PROCESSED\"}\n\n"+
+			"id: 7\nevent: balance\ndata: {\"current\":500,\"withdrawn\":0}\n\n",
+		rr.Body.String())
+}
+
+func TestEventsHandler_Errors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	withoutBroker := handler.NewHandler(service.NewGofemartService(mockRepo, "http://localhost:8081"))
+
+	tests := []struct {
+		name           string
+		userID         string
+		lastEventID    string
+		expectedStatus int
+	}{
+		{name: "Unauthenticated", expectedStatus: http.StatusUnauthorized},
+		{name: "Invalid Last-Event-ID", userID: "1", lastEventID: "abc", expectedStatus: http.StatusBadRequest},
+		{name: "Broker not configured", userID: "1", expectedStatus: http.StatusServiceUnavailable},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/user/events", nil)
+			if tt.lastEventID != "" {
+				req.Header.Set("Last-Event-ID", tt.lastEventID)
+			}
+			if tt.userID != "" {
+				req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, tt.userID))
+			}
+			rr := httptest.NewRecorder()
+			withoutBroker.Events(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
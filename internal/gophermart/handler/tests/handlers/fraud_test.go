@@ -0,0 +1,162 @@
+package tests
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"go-musthave-diploma-tpl/internal/gophermart/fraud"
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+	"go-musthave-diploma-tpl/internal/gophermart/middleware"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+	"go-musthave-diploma-tpl/internal/gophermart/service"
+	mocks "go-musthave-diploma-tpl/internal/gophermart/service/mocks"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// fraudStore - активность для правил антифрода без базы
+type fraudStore struct {
+	activity models.FraudActivity
+}
+
+func (s *fraudStore) FraudActivity(context.Context, int, time.Time) (models.FraudActivity, error) {
+	return s.activity, nil
+}
+
+func (s *fraudStore) FraudEventTime(context.Context, int, string, time.Time, int) (*time.Time, error) {
+	return nil, nil
+}
+
+func (s *fraudStore) CreateFraudFlags(context.Context, []models.FraudFlag) error { return nil }
+
+func TestHandlers_FraudRules(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	svc := service.NewGofemartService(mockRepo, "http://localhost:8081")
+	svc.SetFraudEngine(fraud.NewEngine(fraud.DefaultRules(), &fraudStore{
+		activity: models.FraudActivity{Uploads: 100, Withdrawals: 20},
+	}, zap.NewNop().Sugar()))
+	h := handler.NewHandler(svc)
+
+	// загрузка сверх лимита откладывается, в репозиторий не доходит
+	req := httptest.NewRequest("POST", "/api/user/orders", strings.NewReader("12345678903"))
+	req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "1"))
+	rr := httptest.NewRecorder()
+	h.CreateOrder(rr, req)
+
+	assert.Equal(t, http.StatusTooManyRequests, rr.Code)
+	assert.Equal(t, "900", rr.Header().Get("Retry-After"))
+
+	// частые списания блокируются
+	req = httptest.NewRequest("POST", "/api/user/balance/withdraw", bytes.NewBufferString(`{"order":"2377225624","sum":10}`))
+	req.Header.Set("Content-Type", "application/json")
+	req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "1"))
+	rr = httptest.NewRecorder()
+	h.Withdraw(rr, req)
+
+	assert.Equal(t, http.StatusForbidden, rr.Code)
+}
+
+func TestReviewFraudFlagHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	h := handler.NewHandler(service.NewGofemartService(mockRepo, "http://localhost:8081"))
+
+	tests := []struct {
+		name           string
+		flagID         string
+		body           string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name:   "Dismissed",
+			flagID: "3",
+			body:   `{"status":"DISMISSED","note":" family account "}`,
+			mockSetup: func() {
+				mockRepo.EXPECT().ReviewFraudFlag(int64(3), 1, models.FraudReview{Status: models.FraudFlagDismissed, Note: "family account"}).
+					Return(&models.FraudFlag{ID: 3, UserID: 5, Status: models.FraudFlagDismissed}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Back to open is not a review",
+			flagID:         "3",
+			body:           `{"status":"OPEN"}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:   "Unknown flag",
+			flagID: "404",
+			body:   `{"status":"CONFIRMED"}`,
+			mockSetup: func() {
+				mockRepo.EXPECT().ReviewFraudFlag(int64(404), 1, gomock.Any()).Return(nil, handler.ErrFraudFlagNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:   "Already reviewed",
+			flagID: "3",
+			body:   `{"status":"CONFIRMED"}`,
+			mockSetup: func() {
+				mockRepo.EXPECT().ReviewFraudFlag(int64(3), 1, gomock.Any()).Return(nil, handler.ErrFraudFlagAlreadyReviewed)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("POST", "/api/admin/fraud-flags/"+tt.flagID+"/review", strings.NewReader(tt.body))
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("id", tt.flagID)
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			ctx = context.WithValue(ctx, middleware.UserIDKey, "1")
+			rr := httptest.NewRecorder()
+			h.ReviewFraudFlag(rr, req.WithContext(ctx))
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestFraudFlagsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	h := handler.NewHandler(service.NewGofemartService(mockRepo, "http://localhost:8081"))
+
+	mockRepo.EXPECT().FraudFlags(gomock.Any()).
+		DoAndReturn(func(filter models.FraudFlagFilter) ([]models.FraudFlag, string, error) {
+			assert.Equal(t, []string{models.FraudFlagOpen}, filter.Statuses)
+			assert.Equal(t, 5, filter.UserID)
+			assert.Equal(t, models.DefaultPageLimit, filter.Limit)
+			return []models.FraudFlag{{ID: 1, UserID: 5, Status: models.FraudFlagOpen}}, "", nil
+		})
+
+	req := httptest.NewRequest("GET", "/api/admin/fraud-flags?status=open&user_id=5", nil)
+	rr := httptest.NewRecorder()
+	h.FraudFlags(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+
+	req = httptest.NewRequest("GET", "/api/admin/fraud-flags?status=NEW", nil)
+	rr = httptest.NewRecorder()
+	h.FraudFlags(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
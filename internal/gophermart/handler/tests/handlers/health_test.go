@@ -0,0 +1,87 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"go-musthave-diploma-tpl/internal/gophermart/breaker"
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+	"go-musthave-diploma-tpl/internal/gophermart/service"
+	mocks "go-musthave-diploma-tpl/internal/gophermart/service/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+type fakeLeaderReporter struct {
+	instance string
+	leader   string
+}
+
+func (f fakeLeaderReporter) InstanceID() string { return f.instance }
+func (f fakeLeaderReporter) LeaderID() string   { return f.leader }
+
+func TestHandler_Health(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	tests := []struct {
+		name         string
+		setupBreaker func() *breaker.Breaker
+		leader       service.LeaderReporter
+		expectedBody string
+	}{
+		{
+			name:         "Without breaker",
+			setupBreaker: func() *breaker.Breaker { return nil },
+			expectedBody: `{"status":"ok"}`,
+		},
+		{
+			name: "Breaker closed",
+			setupBreaker: func() *breaker.Breaker {
+				return breaker.New("accrual", breaker.Config{FailureThreshold: 1, CoolDown: time.Hour}, nil)
+			},
+			expectedBody: `{"status":"ok","accrual_breaker":"closed"}`,
+		},
+		{
+			name: "Breaker open",
+			setupBreaker: func() *breaker.Breaker {
+				b := breaker.New("accrual", breaker.Config{FailureThreshold: 1, CoolDown: time.Hour}, nil)
+				b.Failure()
+				return b
+			},
+			expectedBody: `{"status":"degraded","accrual_breaker":"open"}`,
+		},
+		{
+			name:         "Leader info",
+			setupBreaker: func() *breaker.Breaker { return nil },
+			leader:       fakeLeaderReporter{instance: "gophermart-1", leader: "gophermart-2"},
+			expectedBody: `{"status":"ok","instance":"gophermart-1","leader":"gophermart-2"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := mocks.NewMockGofemartRepo(ctrl)
+			svc := service.NewGofemartService(mockRepo, "http://localhost:8081")
+			if b := tt.setupBreaker(); b != nil {
+				svc.SetAccrualBreaker(b)
+			}
+			if tt.leader != nil {
+				svc.SetLeaderReporter(tt.leader)
+			}
+			h := handler.NewHandler(svc)
+
+			req := httptest.NewRequest("GET", "/api/health", nil)
+			rr := httptest.NewRecorder()
+
+			h.Health(rr, req)
+
+			assert.Equal(t, http.StatusOK, rr.Code)
+			assert.Equal(t, "application/json", rr.Header().Get("Content-Type"))
+			assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+		})
+	}
+}
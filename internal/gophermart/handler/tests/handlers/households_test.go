@@ -0,0 +1,245 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+	"go-musthave-diploma-tpl/internal/gophermart/middleware"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+	"go-musthave-diploma-tpl/internal/gophermart/service"
+	mocks "go-musthave-diploma-tpl/internal/gophermart/service/mocks"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCreateHouseholdHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	h := handler.NewHandler(service.NewGofemartService(mockRepo, "http://localhost:8081"))
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name: "Created",
+			body: `{"name":" Smiths "}`,
+			mockSetup: func() {
+				mockRepo.EXPECT().CreateHousehold(5, "Smiths").
+					Return(&models.Household{ID: 3, Name: "Smiths", OwnerID: 5}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Name is required",
+			body:           `{"name":"  "}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Already in household",
+			body: `{"name":"Smiths"}`,
+			mockSetup: func() {
+				mockRepo.EXPECT().CreateHousehold(5, "Smiths").Return(nil, handler.ErrAlreadyInHousehold)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("POST", "/api/user/household", strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "5"))
+			rr := httptest.NewRecorder()
+			h.CreateHousehold(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestInviteToHouseholdHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	h := handler.NewHandler(service.NewGofemartService(mockRepo, "http://localhost:8081"))
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name: "Invited",
+			body: `{"login":"bob"}`,
+			mockSetup: func() {
+				mockRepo.EXPECT().InviteToHousehold(5, "bob").
+					Return(&models.HouseholdInvite{ID: 11, HouseholdID: 3, UserID: 7, Status: models.InvitePending}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name: "Only owner invites",
+			body: `{"login":"bob"}`,
+			mockSetup: func() {
+				mockRepo.EXPECT().InviteToHousehold(5, "bob").Return(nil, handler.ErrForbidden)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name: "Unknown login",
+			body: `{"login":"nobody"}`,
+			mockSetup: func() {
+				mockRepo.EXPECT().InviteToHousehold(5, "nobody").Return(nil, handler.ErrUserNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "Invite already pending",
+			body: `{"login":"bob"}`,
+			mockSetup: func() {
+				mockRepo.EXPECT().InviteToHousehold(5, "bob").Return(nil, handler.ErrInviteExists)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("POST", "/api/user/household/invites", strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "5"))
+			rr := httptest.NewRecorder()
+			h.InviteToHousehold(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestAcceptHouseholdInviteHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	h := handler.NewHandler(service.NewGofemartService(mockRepo, "http://localhost:8081"))
+
+	mockRepo.EXPECT().RespondHouseholdInvite(7, 11, true).Return(nil, handler.ErrAlreadyInHousehold)
+
+	req := httptest.NewRequest("POST", "/api/user/household/invites/11/accept", nil)
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", "11")
+	ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+	ctx = context.WithValue(ctx, middleware.UserIDKey, "7")
+	rr := httptest.NewRecorder()
+	h.AcceptHouseholdInvite(rr, req.WithContext(ctx))
+
+	assert.Equal(t, http.StatusConflict, rr.Code)
+}
+
+func TestUpdateHouseholdMemberHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	h := handler.NewHandler(service.NewGofemartService(mockRepo, "http://localhost:8081"))
+
+	limit := 50.0
+	perms := models.MemberPermissions{CanWithdraw: true, WithdrawLimit: &limit}
+	mockRepo.EXPECT().UpdateHouseholdMember(5, 7, perms).
+		Return(&models.HouseholdMember{UserID: 7, Role: models.HouseholdRoleMember, CanWithdraw: true, WithdrawLimit: &limit}, nil)
+
+	for body, status := range map[string]int{
+		`{"can_withdraw":true,"withdraw_limit":50}`: http.StatusOK,
+		`{"can_withdraw":true,"withdraw_limit":-1}`: http.StatusBadRequest,
+	} {
+		req := httptest.NewRequest("PUT", "/api/user/household/members/7", strings.NewReader(body))
+		rctx := chi.NewRouteContext()
+		rctx.URLParams.Add("id", "7")
+		ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+		ctx = context.WithValue(ctx, middleware.UserIDKey, "5")
+		rr := httptest.NewRecorder()
+		h.UpdateHouseholdMember(rr, req.WithContext(ctx))
+
+		assert.Equal(t, status, rr.Code, body)
+	}
+}
+
+func TestHouseholdWalletHandlers(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	h := handler.NewHandler(service.NewGofemartService(mockRepo, "http://localhost:8081"))
+
+	t.Run("Household balance", func(t *testing.T) {
+		mockRepo.EXPECT().HouseholdBalance(7).Return(models.Balance{Current: 120, Withdrawn: 30}, nil)
+
+		req := httptest.NewRequest("GET", "/api/user/balance?wallet=household", nil)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "7"))
+		rr := httptest.NewRecorder()
+		h.GetBalance(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"current":120,"withdrawn":30}`, rr.Body.String())
+	})
+
+	t.Run("Balance outside household", func(t *testing.T) {
+		mockRepo.EXPECT().HouseholdBalance(7).Return(models.Balance{}, handler.ErrHouseholdNotFound)
+
+		req := httptest.NewRequest("GET", "/api/user/balance?wallet=household", nil)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "7"))
+		rr := httptest.NewRecorder()
+		h.GetBalance(rr, req)
+
+		assert.Equal(t, http.StatusNotFound, rr.Code)
+	})
+
+	t.Run("Unknown wallet", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/api/user/balance?wallet=savings", nil)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "7"))
+		rr := httptest.NewRecorder()
+		h.GetBalance(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+
+	withdraw := models.WithdrawBalance{Order: "2377225624", Sum: 751}
+	for name, tt := range map[string]struct {
+		err    error
+		status int
+	}{
+		"Household withdrawal":      {nil, http.StatusOK},
+		"Withdrawal not permitted":  {handler.ErrWithdrawNotPermitted, http.StatusForbidden},
+		"Household lacks funds":     {handler.ErrLackOfFunds, http.StatusPaymentRequired},
+		"Withdrawal outside family": {handler.ErrHouseholdNotFound, http.StatusNotFound},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mockRepo.EXPECT().WithdrawFromHousehold(7, withdraw).Return(tt.err)
+
+			req := httptest.NewRequest("POST", "/api/user/balance/withdraw?wallet=household",
+				strings.NewReader(`{"order":"2377225624","sum":751}`))
+			req.Header.Set("Content-Type", "application/json")
+			req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "7"))
+			rr := httptest.NewRecorder()
+			h.Withdraw(rr, req)
+
+			assert.Equal(t, tt.status, rr.Code)
+		})
+	}
+}
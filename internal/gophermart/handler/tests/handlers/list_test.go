@@ -0,0 +1,137 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+	"go-musthave-diploma-tpl/internal/gophermart/middleware"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+	"go-musthave-diploma-tpl/internal/gophermart/service"
+	mocks "go-musthave-diploma-tpl/internal/gophermart/service/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGetOrdersHandler_Page(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	h := handler.NewHandler(service.NewGofemartService(mockRepo, "http://localhost:8081"))
+
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockRepo.EXPECT().GetOrdersPage(1, models.ListParams{
+		Limit:     2,
+		Statuses:  []string{"NEW", "PROCESSED"},
+		From:      &from,
+		Ascending: true,
+	}).Return([]models.Order{{Number: "111", Status: "NEW"}, {Number: "222", Status: "PROCESSED"}}, "next-cursor", nil)
+
+	req := httptest.NewRequest("GET", "/api/user/orders?limit=2&status=new,processed&from=2026-01-01T00:00:00Z&order=asc", nil)
+	req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "1"))
+	rr := httptest.NewRecorder()
+	h.GetOrders(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Equal(t, "next-cursor", rr.Header().Get("X-Next-Cursor"))
+	assert.Contains(t, rr.Header().Get("Link"), "cursor=next-cursor")
+	assert.Contains(t, rr.Header().Get("Link"), `rel="next"`)
+
+	var orders []models.Order
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &orders))
+	assert.Len(t, orders, 2)
+}
+
+func TestGetOrdersHandler_PageEmpty(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	h := handler.NewHandler(service.NewGofemartService(mockRepo, "http://localhost:8081"))
+
+	mockRepo.EXPECT().GetOrdersPage(1, models.ListParams{Limit: models.DefaultPageLimit, Statuses: []string{"INVALID"}}).
+		Return([]models.Order{}, "", nil)
+
+	req := httptest.NewRequest("GET", "/api/user/orders?status=INVALID", nil)
+	req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "1"))
+	rr := httptest.NewRecorder()
+	h.GetOrders(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	assert.Empty(t, rr.Header().Get("Link"))
+	assert.Equal(t, "[]", strings.TrimSpace(rr.Body.String()))
+}
+
+func TestGetOrdersHandler_PageBadRequest(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	h := handler.NewHandler(service.NewGofemartService(mockRepo, "http://localhost:8081"))
+
+	for _, query := range []string{
+		"limit=abc",
+		"limit=5000",
+		"status=UNKNOWN",
+		"from=yesterday",
+		"from=2026-02-01T00:00:00Z&to=2026-01-01T00:00:00Z",
+		"order=sideways",
+		"cursor=not-a-cursor",
+	} {
+		t.Run(query, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/user/orders?"+query, nil)
+			req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "1"))
+			rr := httptest.NewRecorder()
+			h.GetOrders(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+		})
+	}
+}
+
+func TestWithdrawalsHandler_Page(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	h := handler.NewHandler(service.NewGofemartService(mockRepo, "http://localhost:8081"))
+
+	cursor := models.EncodeCursor(time.Now(), 3)
+	mockRepo.EXPECT().WithdrawalsPage(1, models.ListParams{Limit: 1, Cursor: cursor}).
+		Return([]models.WithdrawBalance{}, "", nil)
+
+	req := httptest.NewRequest("GET", "/api/user/withdrawals?limit=1&cursor="+cursor, nil)
+	req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "1"))
+	rr := httptest.NewRecorder()
+	h.Withdrawals(rr, req)
+
+	// пустая страница - [], как и у заказов
+	assert.Equal(t, http.StatusOK, rr.Code)
+	assert.JSONEq(t, `[]`, rr.Body.String())
+}
+
+func TestWithdrawalsHandler_UnrelatedQuery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	h := handler.NewHandler(service.NewGofemartService(mockRepo, "http://localhost:8081"))
+
+	// параметр для сброса кеша не включает постраничный режим
+	mockRepo.EXPECT().Withdrawals(1).Return([]models.WithdrawBalance{}, nil)
+
+	req := httptest.NewRequest("GET", "/api/user/withdrawals?_=1700000000", nil)
+	req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "1"))
+	rr := httptest.NewRecorder()
+	h.Withdrawals(rr, req)
+
+	assert.Equal(t, http.StatusNoContent, rr.Code)
+}
@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+	"go-musthave-diploma-tpl/internal/gophermart/middleware"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+	"go-musthave-diploma-tpl/internal/gophermart/service"
+	mocks "go-musthave-diploma-tpl/internal/gophermart/service/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNotificationsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	h := handler.NewHandler(service.NewGofemartService(mockRepo, "http://localhost:8081"))
+
+	tests := []struct {
+		name           string
+		query          string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name: "Default limit",
+			mockSetup: func() {
+				mockRepo.EXPECT().Notifications(1, false, models.DefaultPageLimit).
+					Return(&models.Notifications{Unread: 1, Notifications: []models.Notification{{ID: 1}}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:  "Unread only",
+			query: "?unread=true&limit=5",
+			mockSetup: func() {
+				mockRepo.EXPECT().Notifications(1, true, 5).
+					Return(&models.Notifications{Notifications: []models.Notification{}}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Invalid unread flag",
+			query:          "?unread=maybe",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid limit",
+			query:          "?limit=0",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("GET", "/api/user/notifications"+tt.query, nil)
+			req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "1"))
+			rr := httptest.NewRecorder()
+			h.Notifications(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestReadNotificationsHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	h := handler.NewHandler(service.NewGofemartService(mockRepo, "http://localhost:8081"))
+
+	t.Run("Selected", func(t *testing.T) {
+		mockRepo.EXPECT().MarkNotificationsRead(1, []int64{3, 5}).Return(2, nil)
+
+		req := httptest.NewRequest("POST", "/api/user/notifications/read", strings.NewReader(`{"ids":[3,5]}`))
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "1"))
+		rr := httptest.NewRecorder()
+		h.ReadNotifications(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"unread":2}`, rr.Body.String())
+	})
+
+	t.Run("All without body", func(t *testing.T) {
+		mockRepo.EXPECT().MarkNotificationsRead(1, nil).Return(0, nil)
+
+		req := httptest.NewRequest("POST", "/api/user/notifications/read", nil)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "1"))
+		rr := httptest.NewRecorder()
+		h.ReadNotifications(rr, req)
+
+		assert.Equal(t, http.StatusOK, rr.Code)
+		assert.JSONEq(t, `{"unread":0}`, rr.Body.String())
+	})
+
+	t.Run("Invalid JSON", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/api/user/notifications/read", strings.NewReader(`{"ids":`))
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "1"))
+		rr := httptest.NewRecorder()
+		h.ReadNotifications(rr, req)
+
+		assert.Equal(t, http.StatusBadRequest, rr.Code)
+	})
+}
@@ -0,0 +1,94 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+	"go-musthave-diploma-tpl/internal/gophermart/middleware"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+	"go-musthave-diploma-tpl/internal/gophermart/service"
+	mocks "go-musthave-diploma-tpl/internal/gophermart/service/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCreateOrdersHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	h := handler.NewHandler(service.NewGofemartService(mockRepo, "http://localhost:8081"))
+
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+	}{
+		{name: "JSON array", contentType: "application/json; charset=utf-8", body: `["12345678903", "2377225624", "1"]`},
+		{name: "Newline-delimited", contentType: "text/plain", body: "12345678903\r\n2377225624\n\n1\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo.EXPECT().CreateOrders(1, []string{"12345678903", "2377225624"}).
+				Return(map[string]string{"12345678903": "accepted", "2377225624": "conflict"}, nil)
+
+			req := httptest.NewRequest("POST", "/api/user/orders/batch", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "1"))
+			rr := httptest.NewRecorder()
+			h.CreateOrders(rr, req)
+
+			require.Equal(t, http.StatusOK, rr.Code)
+			var results []models.BatchOrderResult
+			require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &results))
+			assert.Equal(t, []models.BatchOrderResult{
+				{Number: "12345678903", Result: models.BatchResultAccepted},
+				{Number: "2377225624", Result: models.BatchResultConflict},
+				{Number: "1", Result: models.BatchResultInvalid},
+			}, results)
+		})
+	}
+}
+
+func TestCreateOrdersHandler_Errors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	h := handler.NewHandler(service.NewGofemartService(mockRepo, "http://localhost:8081"))
+
+	tests := []struct {
+		name           string
+		userID         string
+		contentType    string
+		body           string
+		expectedStatus int
+	}{
+		{name: "Unauthenticated", body: "12345678903", expectedStatus: http.StatusUnauthorized},
+		{name: "Malformed JSON", userID: "1", contentType: "application/json", body: `["123"`, expectedStatus: http.StatusBadRequest},
+		{name: "Empty body", userID: "1", contentType: "text/plain", body: "\n\n", expectedStatus: http.StatusBadRequest},
+		{name: "Too many numbers", userID: "1", contentType: "text/plain", body: strings.Repeat("1\n", models.MaxBatchSize+1), expectedStatus: http.StatusRequestEntityTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("POST", "/api/user/orders/batch", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			if tt.userID != "" {
+				req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, tt.userID))
+			}
+			rr := httptest.NewRecorder()
+			h.CreateOrders(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+	"go-musthave-diploma-tpl/internal/gophermart/middleware"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+	"go-musthave-diploma-tpl/internal/gophermart/service"
+	mocks "go-musthave-diploma-tpl/internal/gophermart/service/mocks"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHandler_GetOrder(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	svc := service.NewGofemartService(mockRepo, "http://localhost:8081")
+	h := handler.NewHandler(svc)
+
+	uploadedAt := time.Date(2026, 10, 1, 10, 0, 0, 0, time.UTC)
+	processedAt := time.Date(2026, 10, 1, 10, 5, 0, 0, time.UTC)
+
+	tests := []struct {
+		name           string
+		userID         string
+		setupMock      func()
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:   "Order with history",
+			userID: "1",
+			setupMock: func() {
+				mockRepo.EXPECT().GetOrder(1, "12345678903").Return(&models.OrderDetails{
+					Order: models.Order{
+						Number:      "12345678903",
+						Status:      models.OrderStatusProcessed,
+						Accrual:     500,
+						UploadedAt:  uploadedAt,
+						ProcessedAt: &processedAt,
+					},
+					History: []models.OrderStatusChange{
+						{Status: models.OrderStatusNew, ChangedAt: uploadedAt},
+						{From: models.OrderStatusNew, Status: models.OrderStatusProcessed, Accrual: 500, ChangedAt: processedAt},
+					},
+				}, nil)
+			},
+			expectedStatus: http.StatusOK,
+			expectedBody: `{
+				"number":"12345678903",
+				"status":"PROCESSED",
+				"accrual":500,
+				"uploaded_at":"2026-10-01T10:00:00Z",
+				"processed_at":"2026-10-01T10:05:00Z",
+				"history":[
+					{"status":"NEW","changed_at":"2026-10-01T10:00:00Z"},
+					{"from":"NEW","status":"PROCESSED","accrual":500,"changed_at":"2026-10-01T10:05:00Z"}
+				]
+			}`,
+		},
+		{
+			name:   "Unknown or foreign order",
+			userID: "1",
+			setupMock: func() {
+				mockRepo.EXPECT().GetOrder(1, "12345678903").Return(nil, handler.ErrOrderNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+			expectedBody:   `{"error":"order not found"}`,
+		},
+		{
+			name:   "Database error",
+			userID: "1",
+			setupMock: func() {
+				mockRepo.EXPECT().GetOrder(1, "12345678903").Return(nil, fmt.Errorf("database error"))
+			},
+			expectedStatus: http.StatusInternalServerError,
+			expectedBody:   `{"error":"internal server error"}`,
+		},
+		{
+			name:           "User not authenticated",
+			setupMock:      func() {},
+			expectedStatus: http.StatusUnauthorized,
+			expectedBody:   `{"error":"user is not authenticated"}`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.setupMock()
+
+			req := httptest.NewRequest("GET", "/api/user/orders/12345678903", nil)
+			rctx := chi.NewRouteContext()
+			rctx.URLParams.Add("number", "12345678903")
+			ctx := context.WithValue(req.Context(), chi.RouteCtxKey, rctx)
+			if tt.userID != "" {
+				ctx = context.WithValue(ctx, middleware.UserIDKey, tt.userID)
+			}
+			req = req.WithContext(ctx)
+			rr := httptest.NewRecorder()
+
+			h.GetOrder(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+			assert.JSONEq(t, tt.expectedBody, rr.Body.String())
+		})
+	}
+}
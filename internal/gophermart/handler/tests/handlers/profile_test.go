@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+	"go-musthave-diploma-tpl/internal/gophermart/middleware"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+	"go-musthave-diploma-tpl/internal/gophermart/service"
+	mocks "go-musthave-diploma-tpl/internal/gophermart/service/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMeHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	h := handler.NewHandler(service.NewGofemartService(mockRepo, "http://localhost:8081"))
+
+	t.Run("Profile", func(t *testing.T) {
+		registered := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+		mockRepo.EXPECT().Profile(1, gomock.Any()).Return(&models.Profile{
+			ID:           1,
+			Login:        "alice",
+			RegisteredAt: registered,
+			Orders:       map[string]int{models.OrderStatusProcessed: 3},
+			Accrued:      250,
+			Withdrawn:    40.5,
+		}, nil)
+
+		req := httptest.NewRequest("GET", "/api/user/me?months=6", nil)
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "1"))
+		rr := httptest.NewRecorder()
+		h.Me(rr, req)
+
+		require.Equal(t, http.StatusOK, rr.Code)
+		var profile models.Profile
+		require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &profile))
+		assert.Equal(t, "alice", profile.Login)
+		assert.True(t, registered.Equal(profile.RegisteredAt))
+		assert.Equal(t, 3, profile.Orders[models.OrderStatusProcessed])
+		assert.Equal(t, 0, profile.Orders[models.OrderStatusNew])
+		assert.Len(t, profile.Monthly, 6)
+	})
+
+	for name, query := range map[string]string{
+		"Months is not a number": "?months=all",
+		"Months out of range":    "?months=121",
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/api/user/me"+query, nil)
+			req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "1"))
+			rr := httptest.NewRecorder()
+			h.Me(rr, req)
+
+			assert.Equal(t, http.StatusBadRequest, rr.Code)
+		})
+	}
+
+	t.Run("Not authenticated", func(t *testing.T) {
+		rr := httptest.NewRecorder()
+		h.Me(rr, httptest.NewRequest("GET", "/api/user/me", nil))
+
+		assert.Equal(t, http.StatusUnauthorized, rr.Code)
+	})
+}
@@ -0,0 +1,198 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+	"go-musthave-diploma-tpl/internal/gophermart/middleware"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+	"go-musthave-diploma-tpl/internal/gophermart/ratelimit"
+	"go-musthave-diploma-tpl/internal/gophermart/service"
+	mocks "go-musthave-diploma-tpl/internal/gophermart/service/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRedeemPromoCodeHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	h := handler.NewHandler(service.NewGofemartService(mockRepo, "http://localhost:8081"))
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name: "Redeemed",
+			body: `{"code":"spring"}`,
+			mockSetup: func() {
+				mockRepo.EXPECT().RedeemPromoCode(1, "SPRING").
+					Return(&models.PromoRedemption{Code: "SPRING", Amount: 100, RedeemedAt: time.Now()}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Empty code",
+			body:           `{"code":""}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid JSON",
+			body:           `{`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Unknown code",
+			body: `{"code":"nope"}`,
+			mockSetup: func() {
+				mockRepo.EXPECT().RedeemPromoCode(1, "NOPE").Return(nil, handler.ErrPromoCodeNotFound)
+			},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name: "Expired",
+			body: `{"code":"old"}`,
+			mockSetup: func() {
+				mockRepo.EXPECT().RedeemPromoCode(1, "OLD").Return(nil, handler.ErrPromoCodeExpired)
+			},
+			expectedStatus: http.StatusGone,
+		},
+		{
+			name: "Already redeemed",
+			body: `{"code":"spring"}`,
+			mockSetup: func() {
+				mockRepo.EXPECT().RedeemPromoCode(1, "SPRING").Return(nil, handler.ErrPromoCodeLimitReached)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("POST", "/api/user/balance/redeem", strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "1"))
+			rr := httptest.NewRecorder()
+			h.RedeemPromoCode(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestRedeemPromoCodeHandler_TooManyAttempts(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	svc := service.NewGofemartService(mockRepo, "http://localhost:8081")
+	svc.SetPromoLimiter(ratelimit.New(ratelimit.Config{Limit: 1, Window: time.Hour}))
+	h := handler.NewHandler(svc)
+
+	mockRepo.EXPECT().RedeemPromoCode(1, "GUESS").Return(nil, handler.ErrPromoCodeNotFound)
+
+	for _, expected := range []int{http.StatusNotFound, http.StatusTooManyRequests} {
+		req := httptest.NewRequest("POST", "/api/user/balance/redeem", strings.NewReader(`{"code":"guess"}`))
+		req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "1"))
+		rr := httptest.NewRecorder()
+		h.RedeemPromoCode(rr, req)
+
+		assert.Equal(t, expected, rr.Code)
+	}
+}
+
+func TestCreatePromoCodeHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	h := handler.NewHandler(service.NewGofemartService(mockRepo, "http://localhost:8081"))
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name: "Created",
+			body: `{"code":"spring","value":100,"max_redemptions":1000,"per_user_limit":1}`,
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					CreatePromoCode(1, models.PromoCode{Code: "SPRING", Value: 100, MaxRedemptions: 1000, PerUserLimit: 1}).
+					Return(&models.PromoCode{ID: 1, Code: "SPRING", Value: 100, MaxRedemptions: 1000, PerUserLimit: 1}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Negative value",
+			body:           `{"code":"spring","value":-1,"per_user_limit":1}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Duplicate code",
+			body: `{"code":"spring","value":100,"per_user_limit":1}`,
+			mockSetup: func() {
+				mockRepo.EXPECT().CreatePromoCode(1, gomock.Any()).Return(nil, handler.ErrPromoCodeExists)
+			},
+			expectedStatus: http.StatusConflict,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("POST", "/api/admin/promo-codes", strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "1"))
+			rr := httptest.NewRecorder()
+			h.CreatePromoCode(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestBalanceHistoryHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	h := handler.NewHandler(service.NewGofemartService(mockRepo, "http://localhost:8081"))
+
+	mockRepo.EXPECT().BalanceHistory(1, 20).Return([]models.BalanceEntry{
+		{Kind: models.AdjustmentPromo, Amount: 100, Reference: "SPRING", CreatedAt: time.Now()},
+	}, nil)
+
+	req := httptest.NewRequest("GET", "/api/user/balance/history?limit=20", nil)
+	req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "1"))
+	rr := httptest.NewRecorder()
+	h.BalanceHistory(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var entries []models.BalanceEntry
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &entries))
+	assert.Len(t, entries, 1)
+
+	req = httptest.NewRequest("GET", "/api/user/balance/history?limit=abc", nil)
+	req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "1"))
+	rr = httptest.NewRecorder()
+	h.BalanceHistory(rr, req)
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
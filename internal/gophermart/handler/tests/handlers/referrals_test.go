@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+	"go-musthave-diploma-tpl/internal/gophermart/middleware"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+	"go-musthave-diploma-tpl/internal/gophermart/service"
+	mocks "go-musthave-diploma-tpl/internal/gophermart/service/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHandler_RegisterWithReferralCode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	h := handler.NewHandler(service.NewGofemartService(mockRepo, "http://localhost:8081"))
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name: "Registered by code",
+			body: `{"login":"friend","password":"secret","referral_code":"abc123"}`,
+			mockSetup: func() {
+				mockRepo.EXPECT().CreateReferredUser("friend", "secret", "abc123").
+					Return(&models.User{ID: 2, Login: "friend"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name: "Unknown code",
+			body: `{"login":"friend","password":"secret","referral_code":"nope"}`,
+			mockSetup: func() {
+				mockRepo.EXPECT().CreateReferredUser("friend", "secret", "nope").
+					Return(nil, handler.ErrInvalidReferralCode)
+			},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name: "Empty code is a plain registration",
+			body: `{"login":"friend","password":"secret","referral_code":" "}`,
+			mockSetup: func() {
+				mockRepo.EXPECT().CreateUser("friend", "secret").
+					Return(&models.User{ID: 2, Login: "friend"}, nil)
+			},
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("POST", "/api/user/register", strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", "application/json")
+			rr := httptest.NewRecorder()
+			h.Register(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestHandler_Referrals(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	h := handler.NewHandler(service.NewGofemartService(mockRepo, "http://localhost:8081"))
+
+	mockRepo.EXPECT().ReferralStats(1).
+		Return(&models.ReferralStats{Code: "abc123", Invited: 2, Rewarded: 1, Earned: 100, Referrals: []models.Referral{}}, nil)
+
+	req := httptest.NewRequest("GET", "/api/user/referrals", nil)
+	req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "1"))
+	rr := httptest.NewRecorder()
+	h.Referrals(rr, req)
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var stats models.ReferralStats
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &stats))
+	assert.Equal(t, "abc123", stats.Code)
+	assert.Equal(t, 2, stats.Invited)
+}
@@ -0,0 +1,208 @@
+package tests
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+	"go-musthave-diploma-tpl/internal/gophermart/middleware"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+	"go-musthave-diploma-tpl/internal/gophermart/service"
+	mocks "go-musthave-diploma-tpl/internal/gophermart/service/mocks"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func withWebhookID(req *http.Request, id string) *http.Request {
+	rctx := chi.NewRouteContext()
+	rctx.URLParams.Add("id", id)
+	return req.WithContext(context.WithValue(req.Context(), chi.RouteCtxKey, rctx))
+}
+
+func TestCreateWebhookHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	h := handler.NewHandler(service.NewGofemartService(mockRepo, "http://localhost:8081"))
+
+	tests := []struct {
+		name           string
+		body           string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name: "Created",
+			body: `{"url":"https://partner.example/hook","events":["order.processed","withdrawal.created"]}`,
+			mockSetup: func() {
+				mockRepo.EXPECT().
+					CreateWebhook(1, "https://partner.example/hook", []string{"order.processed", "withdrawal.created"}).
+					Return(&models.Webhook{ID: 1, URL: "https://partner.example/hook", Secret: "abc", Active: true}, nil)
+			},
+			expectedStatus: http.StatusCreated,
+		},
+		{
+			name:           "Invalid URL",
+			body:           `{"url":"ftp://partner.example","events":["order.processed"]}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Unknown event",
+			body:           `{"url":"https://partner.example/hook","events":["order.lost"]}`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "Invalid JSON",
+			body:           `{`,
+			mockSetup:      func() {},
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("POST", "/api/user/webhooks", strings.NewReader(tt.body))
+			req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "1"))
+			rr := httptest.NewRecorder()
+			h.CreateWebhook(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestCreateWebhookHandler_ReturnsSecret(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	h := handler.NewHandler(service.NewGofemartService(mockRepo, "http://localhost:8081"))
+
+	mockRepo.EXPECT().CreateWebhook(1, "https://partner.example/hook", []string{"order.invalid"}).
+		Return(&models.Webhook{ID: 5, URL: "https://partner.example/hook", Secret: "s3cr3t", Events: []string{"order.invalid"}, Active: true}, nil)
+
+	req := httptest.NewRequest("POST", "/api/user/webhooks", strings.NewReader(`{"url":"https://partner.example/hook","events":["order.invalid"]}`))
+	req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "1"))
+	rr := httptest.NewRecorder()
+	h.CreateWebhook(rr, req)
+
+	require.Equal(t, http.StatusCreated, rr.Code)
+	var webhook models.Webhook
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &webhook))
+	assert.Equal(t, 5, webhook.ID)
+	assert.Equal(t, "s3cr3t", webhook.Secret)
+}
+
+func TestDeleteWebhookHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	h := handler.NewHandler(service.NewGofemartService(mockRepo, "http://localhost:8081"))
+
+	tests := []struct {
+		name           string
+		id             string
+		mockSetup      func()
+		expectedStatus int
+	}{
+		{
+			name:           "Deleted",
+			id:             "5",
+			mockSetup:      func() { mockRepo.EXPECT().DeleteWebhook(1, 5).Return(nil) },
+			expectedStatus: http.StatusNoContent,
+		},
+		{
+			name:           "Not found",
+			id:             "6",
+			mockSetup:      func() { mockRepo.EXPECT().DeleteWebhook(1, 6).Return(handler.ErrWebhookNotFound) },
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Invalid id",
+			id:             "abc",
+			mockSetup:      func() {},
+			expectedStatus: http.StatusNotFound,
+		},
+		{
+			name:           "Database error",
+			id:             "7",
+			mockSetup:      func() { mockRepo.EXPECT().DeleteWebhook(1, 7).Return(fmt.Errorf("database error")) },
+			expectedStatus: http.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.mockSetup()
+
+			req := httptest.NewRequest("DELETE", "/api/user/webhooks/"+tt.id, nil)
+			req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "1"))
+			req = withWebhookID(req, tt.id)
+			rr := httptest.NewRecorder()
+			h.DeleteWebhook(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
+
+func TestWebhookDeliveriesHandler(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	h := handler.NewHandler(service.NewGofemartService(mockRepo, "http://localhost:8081"))
+
+	code := http.StatusOK
+	mockRepo.EXPECT().WebhookDeliveries(1, 5, 10).
+		Return([]models.WebhookDelivery{{ID: 1, WebhookID: 5, Event: "order.processed", Payload: json.RawMessage(`{}`), Status: models.WebhookDeliveryDelivered, Attempts: 1, LastStatusCode: &code}}, nil)
+	mockRepo.EXPECT().WebhookDeliveries(1, 6, models.DefaultPageLimit).Return(nil, handler.ErrWebhookNotFound)
+
+	req := httptest.NewRequest("GET", "/api/user/webhooks/5/deliveries?limit=10", nil)
+	req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "1"))
+	rr := httptest.NewRecorder()
+	h.WebhookDeliveries(rr, withWebhookID(req, "5"))
+
+	require.Equal(t, http.StatusOK, rr.Code)
+	var deliveries []models.WebhookDelivery
+	require.NoError(t, json.Unmarshal(rr.Body.Bytes(), &deliveries))
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, models.WebhookDeliveryDelivered, deliveries[0].Status)
+
+	req = httptest.NewRequest("GET", "/api/user/webhooks/6/deliveries", nil)
+	req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "1"))
+	rr = httptest.NewRecorder()
+	h.WebhookDeliveries(rr, withWebhookID(req, "6"))
+
+	assert.Equal(t, http.StatusNotFound, rr.Code)
+}
+
+func TestCreateWebhookHandler_PrivateTarget(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	h := handler.NewHandler(service.NewGofemartService(mockRepo, "http://localhost:8081"))
+
+	// адрес метаданных облака не регистрируется
+	req := httptest.NewRequest("POST", "/api/user/webhooks", strings.NewReader(`{"url":"http://169.254.169.254/latest","events":["order.invalid"]}`))
+	req = req.WithContext(context.WithValue(req.Context(), middleware.UserIDKey, "1"))
+	rr := httptest.NewRecorder()
+	h.CreateWebhook(rr, req)
+
+	assert.Equal(t, http.StatusBadRequest, rr.Code)
+}
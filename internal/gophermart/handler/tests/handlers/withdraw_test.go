@@ -122,14 +122,8 @@ func TestWithdrawHandler(t *testing.T) {
 				Order: "",
 				Sum:   751,
 			},
-			mockSetup: func(mockRepo *mocks.MockGofemartRepo) {
-				mockRepo.EXPECT().
-					Withdraw(1, models.WithdrawBalance{
-						Order: "",
-						Sum:   751,
-					}).
-					Return(handler.ErrInvalidOrderNumber)
-			},
+			// пустой номер отсекается проверкой до обращения к репозиторию
+			mockSetup:      func(mockRepo *mocks.MockGofemartRepo) {},
 			expectedStatus: http.StatusUnprocessableEntity,
 			expectedBody:   handler.ErrInvalidOrderNumber.Error(),
 		},
@@ -0,0 +1,206 @@
+package leader
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// ListenerLockID - ключ advisory lock, который держит лидер OrderListener
+const ListenerLockID int64 = 7_300_001
+
+// Elector - выбор лидера среди инстансов через Postgres advisory lock.
+// Лок живёт, пока жива сессия, поэтому падение лидера автоматически освобождает его
+type Elector struct {
+	db         *sql.DB
+	lockID     int64
+	instanceID string
+	interval   time.Duration
+	logger     *zap.SugaredLogger
+
+	mu       sync.RWMutex
+	isLeader bool
+	leaderID string
+}
+
+func NewElector(db *sql.DB, lockID int64, instanceID string, interval time.Duration, logger *zap.SugaredLogger) *Elector {
+	if interval <= 0 {
+		interval = 5 * time.Second
+	}
+	return &Elector{
+		db:         db,
+		lockID:     lockID,
+		instanceID: instanceID,
+		interval:   interval,
+		logger:     logger,
+	}
+}
+
+func (e *Elector) InstanceID() string {
+	return e.instanceID
+}
+
+func (e *Elector) IsLeader() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.isLeader
+}
+
+// LeaderID - идентификатор текущего лидера, пустая строка если лидер неизвестен
+func (e *Elector) LeaderID() string {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.leaderID
+}
+
+// Run - борется за лидерство до отмены ctx.
+// onElected запускается с контекстом, который отменяется при потере лидерства
+func (e *Elector) Run(ctx context.Context, onElected func(ctx context.Context)) {
+	for {
+		if ctx.Err() != nil {
+			return
+		}
+
+		conn, acquired, err := e.tryAcquire(ctx)
+		if err != nil {
+			e.logger.Warnf("Leader election failed: %v", err)
+		}
+
+		if acquired {
+			e.lead(ctx, conn, onElected)
+		} else {
+			e.refreshLeader(ctx)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(e.interval):
+		}
+	}
+}
+
+func (e *Elector) tryAcquire(ctx context.Context) (*sql.Conn, bool, error) {
+	// advisory lock привязан к сессии, поэтому держим отдельное соединение
+	conn, err := e.db.Conn(ctx)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get connection: %w", err)
+	}
+
+	var acquired bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, e.lockID).Scan(&acquired); err != nil {
+		conn.Close()
+		return nil, false, fmt.Errorf("failed to try advisory lock: %w", err)
+	}
+	if !acquired {
+		conn.Close()
+		return nil, false, nil
+	}
+
+	if err := e.heartbeat(ctx, conn); err != nil {
+		e.release(conn)
+		return nil, false, err
+	}
+
+	return conn, true, nil
+}
+
+func (e *Elector) lead(ctx context.Context, conn *sql.Conn, onElected func(ctx context.Context)) {
+	e.setLeader(true, e.instanceID)
+	e.logger.Infof("Instance %s became leader", e.instanceID)
+
+	leaderCtx, cancel := context.WithCancel(ctx)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		onElected(leaderCtx)
+	}()
+
+	ticker := time.NewTicker(e.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			cancel()
+			<-done
+			e.setLeader(false, "")
+			e.release(conn)
+			e.logger.Infof("Instance %s released leadership", e.instanceID)
+			return
+		case <-done:
+			// работа лидера завершилась сама - отдаём лидерство другим
+			cancel()
+			e.setLeader(false, "")
+			e.release(conn)
+			e.logger.Warnf("Leader work of instance %s exited, leadership released", e.instanceID)
+			return
+		case <-ticker.C:
+			if err := e.heartbeat(ctx, conn); err != nil {
+				if ctx.Err() != nil {
+					continue
+				}
+				// соединение с локом потеряно - лок уже мог достаться другому
+				cancel()
+				<-done
+				e.setLeader(false, "")
+				e.release(conn)
+				e.logger.Warnf("Instance %s lost leadership: %v", e.instanceID, err)
+				return
+			}
+		}
+	}
+}
+
+func (e *Elector) heartbeat(ctx context.Context, conn *sql.Conn) error {
+	_, err := conn.ExecContext(ctx, `
+        INSERT INTO listener_leader (id, instance_id, acquired_at, heartbeat_at)
+        VALUES (1, $1, NOW(), NOW())
+        ON CONFLICT (id) DO UPDATE
+        SET instance_id = EXCLUDED.instance_id,
+            acquired_at = CASE
+                WHEN listener_leader.instance_id = EXCLUDED.instance_id THEN listener_leader.acquired_at
+                ELSE EXCLUDED.acquired_at
+            END,
+            heartbeat_at = NOW()`, e.instanceID)
+	if err != nil {
+		return fmt.Errorf("failed to write leader heartbeat: %w", err)
+	}
+	return nil
+}
+
+// refreshLeader - читает, кто сейчас лидер; лидер без свежего heartbeat считается мёртвым
+func (e *Elector) refreshLeader(ctx context.Context) {
+	var leaderID string
+	err := e.db.QueryRowContext(ctx, `
+        SELECT instance_id FROM listener_leader
+        WHERE id = 1 AND heartbeat_at > NOW() - make_interval(secs => $1)`,
+		(3 * e.interval).Seconds()).Scan(&leaderID)
+	if err != nil && err != sql.ErrNoRows {
+		e.logger.Warnf("Failed to read current leader: %v", err)
+		return
+	}
+	e.setLeader(false, leaderID)
+}
+
+func (e *Elector) release(conn *sql.Conn) {
+	// контекст родителя уже может быть отменён, а лок нужно снять
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := conn.ExecContext(ctx, `SELECT pg_advisory_unlock($1)`, e.lockID); err != nil {
+		e.logger.Warnf("Failed to release advisory lock: %v", err)
+	}
+	conn.Close()
+}
+
+func (e *Elector) setLeader(isLeader bool, leaderID string) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.isLeader = isLeader
+	e.leaderID = leaderID
+}
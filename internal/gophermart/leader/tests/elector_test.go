@@ -0,0 +1,89 @@
+package tests
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"go-musthave-diploma-tpl/internal/gophermart/leader"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func TestElector_BecomesLeader(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT pg_try_advisory_lock`).
+		WithArgs(leader.ListenerLockID).
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(true))
+	mock.ExpectExec(`INSERT INTO listener_leader`).
+		WithArgs("gophermart-1").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`SELECT pg_advisory_unlock`).
+		WithArgs(leader.ListenerLockID).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	e := leader.NewElector(db, leader.ListenerLockID, "gophermart-1", time.Hour, zap.NewNop().Sugar())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	elected := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		defer close(stopped)
+		e.Run(ctx, func(leaderCtx context.Context) {
+			close(elected)
+			<-leaderCtx.Done()
+		})
+	}()
+
+	select {
+	case <-elected:
+	case <-time.After(time.Second):
+		t.Fatal("instance was not elected")
+	}
+	assert.True(t, e.IsLeader())
+	assert.Equal(t, "gophermart-1", e.LeaderID())
+
+	cancel()
+	<-stopped
+
+	assert.False(t, e.IsLeader())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestElector_Follower(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT pg_try_advisory_lock`).
+		WithArgs(leader.ListenerLockID).
+		WillReturnRows(sqlmock.NewRows([]string{"pg_try_advisory_lock"}).AddRow(false))
+	mock.ExpectQuery(`SELECT instance_id FROM listener_leader`).
+		WillReturnRows(sqlmock.NewRows([]string{"instance_id"}).AddRow("gophermart-2"))
+
+	e := leader.NewElector(db, leader.ListenerLockID, "gophermart-1", time.Hour, zap.NewNop().Sugar())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		e.Run(ctx, func(context.Context) {
+			t.Error("follower must not run leader work")
+		})
+	}()
+
+	assert.Eventually(t, func() bool { return e.LeaderID() == "gophermart-2" }, time.Second, 5*time.Millisecond)
+	assert.False(t, e.IsLeader())
+	assert.Equal(t, "gophermart-1", e.InstanceID())
+
+	cancel()
+	<-stopped
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
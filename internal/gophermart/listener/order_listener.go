@@ -4,32 +4,146 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"net/http"
-	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"go-musthave-diploma-tpl/internal/gophermart/accrualclient"
+	"go-musthave-diploma-tpl/internal/gophermart/breaker"
+	"go-musthave-diploma-tpl/internal/gophermart/leader"
+	"go-musthave-diploma-tpl/internal/gophermart/metrics"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+	"go-musthave-diploma-tpl/internal/gophermart/notify"
+
 	"github.com/jackc/pgx/v4"
 	"github.com/jackc/pgx/v4/pgxpool"
 	"go.uber.org/zap"
 )
 
+const (
+	// пауза между опросами accrual по заказу, который ещё не в финальном статусе
+	pollInterval = 2 * time.Second
+	// пауза перед переподключением LISTEN, растёт вдвое до максимума
+	minReconnectBackoff = time.Second
+	maxReconnectBackoff = 30 * time.Second
+)
+
+// Config - настройки обработки заказов
+type Config struct {
+	// идентификатор инстанса для выбора лидера
+	InstanceID string
+	// число воркеров, разбирающих очередь order_jobs
+	Workers int
+	// на сколько задача блокируется за воркером
+	VisibilityTimeout time.Duration
+	// после стольких неудачных попыток заказ уходит в order_dead_letters
+	MaxAttempts int
+	// сколько Stop ждёт завершения заказов в обработке
+	DrainTimeout time.Duration
+	// как часто искать зависшие заказы
+	ReconcileInterval time.Duration
+	// заказ считается зависшим, если не обновлялся дольше
+	StaleAfter time.Duration
+	// через сколько неизрасходованные баллы сгорают, 0 - не сгорают
+	PointsTTL time.Duration
+	// как часто сжигать баллы
+	PointsExpiryInterval time.Duration
+}
+
+// OrderStore - смена статуса заказа с проверкой допустимости перехода
+type OrderStore interface {
+	UpdateOrderStatus(ctx context.Context, orderID int, status string, accrual float64) error
+}
+
+// AuditRecorder - журнал аудита для действий системы
+type AuditRecorder interface {
+	RecordAudit(ctx context.Context, event models.AuditEvent) error
+}
+
+// PointsExpirer - сгорание баллов, начисленных раньше before
+type PointsExpirer interface {
+	ExpirePoints(ctx context.Context, before time.Time) (int, error)
+}
+
+// UserNotifier - письма пользователю о заказах
+type UserNotifier interface {
+	NotifyUser(ctx context.Context, userID int, kind string, data notify.Data) error
+}
+
 type OrderListener struct {
-	dbURI                string
-	logger               *zap.SugaredLogger
-	db                   *sql.DB
-	accrualSystemAddress string
+	dbURI   string
+	cfg     Config
+	logger  *zap.SugaredLogger
+	db      *sql.DB
+	queue   *JobQueue
+	orders  OrderStore
+	accrual accrualclient.Client
+	breaker *breaker.Breaker
+	elector *leader.Elector
+	audit   AuditRecorder
+	notify  UserNotifier
+	expirer PointsExpirer
+	// подсказка воркерам, что в очереди появились задачи
+	wake chan struct{}
+
+	// отменяется в Stop: новые задачи больше не берутся
+	cancel context.CancelFunc
+	// контекст заказов в обработке, отменяется только по истечении DrainTimeout
+	workCtx    context.Context
+	cancelWork context.CancelFunc
+	// все горутины слушателя, включая воркеров
+	wg       sync.WaitGroup
+	inFlight atomic.Int64
 }
 
-func NewOrderListener(dbURI, accrualSystemAddress string, logger *zap.SugaredLogger) *OrderListener {
+func NewOrderListener(dbURI string, cfg Config, orders OrderStore, accrual accrualclient.Client, cb *breaker.Breaker, logger *zap.SugaredLogger) *OrderListener {
+	if cfg.Workers <= 0 {
+		cfg.Workers = 4
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 10
+	}
+	if cfg.DrainTimeout <= 0 {
+		cfg.DrainTimeout = 10 * time.Second
+	}
+	if cfg.ReconcileInterval <= 0 {
+		cfg.ReconcileInterval = time.Minute
+	}
+	if cfg.StaleAfter <= 0 {
+		cfg.StaleAfter = 5 * time.Minute
+	}
+	if cfg.PointsExpiryInterval <= 0 {
+		cfg.PointsExpiryInterval = time.Hour
+	}
 	return &OrderListener{
-		dbURI:                dbURI,
-		accrualSystemAddress: accrualSystemAddress,
-		logger:               logger,
+		dbURI:   dbURI,
+		cfg:     cfg,
+		orders:  orders,
+		accrual: accrual,
+		breaker: cb,
+		logger:  logger,
+		wake:    make(chan struct{}, cfg.Workers),
 	}
 }
 
+// SetAuditLog - журнал аудита смены статусов и переноса заказов в DLQ
+func (ol *OrderListener) SetAuditLog(a AuditRecorder) {
+	ol.audit = a
+}
+
+// SetNotifier - письма пользователю о заказах в финальном статусе
+func (ol *OrderListener) SetNotifier(n UserNotifier) {
+	ol.notify = n
+}
+
+// SetPointsExpirer - сгорание баллов старше PointsTTL, работает только у лидера
+func (ol *OrderListener) SetPointsExpirer(e PointsExpirer) {
+	ol.expirer = e
+}
+
 func (ol *OrderListener) Start(ctx context.Context) {
 	// убираем кавычки, если они есть
 	dsn := strings.Trim(ol.dbURI, `"`)
@@ -46,214 +160,451 @@ func (ol *OrderListener) Start(ctx context.Context) {
 	}
 	ol.logger.Info("Database connection successful")
 
-	// грузим существующие NEW-заказы и сразу запускаем их обработку
-	go ol.loadExistingOrders(ctx)
+	ol.queue = NewJobQueue(ol.db, ol.cfg.VisibilityTimeout)
+
+	ctx, ol.cancel = context.WithCancel(ctx)
+	ol.workCtx, ol.cancelWork = context.WithCancel(context.WithoutCancel(ctx))
+
+	// заказы обрабатывает только инстанс-лидер
+	ol.elector = leader.NewElector(ol.db, leader.ListenerLockID, ol.cfg.InstanceID, 5*time.Second, ol.logger)
+	ol.wg.Add(1)
+	go func() {
+		defer ol.wg.Done()
+		ol.elector.Run(ctx, ol.lead)
+	}()
+}
+
+// Elector - выбор лидера, доступен после Start
+func (ol *OrderListener) Elector() *leader.Elector {
+	return ol.elector
+}
+
+// lead - работа лидера, ctx отменяется при потере лидерства
+func (ol *OrderListener) lead(ctx context.Context) {
+	var wg sync.WaitGroup
+	for i := 0; i < ol.cfg.Workers; i++ {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			ol.runWorker(ctx, fmt.Sprintf("%s/%d", ol.cfg.InstanceID, n))
+		}(i)
+	}
 
-	// слушаем нотификации новых заказов
-	go ol.listenNotifications(ctx)
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		ol.runDeadLetterMetrics(ctx)
+	}()
+	go func() {
+		defer wg.Done()
+		ol.runReconciliation(ctx)
+	}()
+	if ol.expirer != nil && ol.cfg.PointsTTL > 0 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ol.runPointsExpiry(ctx)
+		}()
+	}
+
+	// слушаем нотификации новых заказов, после каждого подключения догоняем пропущенное
+	ol.listenNotifications(ctx)
+	wg.Wait()
 }
 
 // --------------------------------------------
 // ЗАГРУЗКА И СЛУШАТЕЛЬ НОВЫХ ЗАКАЗОВ
 // --------------------------------------------
 
-func (ol *OrderListener) loadExistingOrders(ctx context.Context) {
-	ol.logger.Info("Loading existing NEW orders...")
+// enqueueExistingOrders - догоняющий скан незавершённых (NEW/PROCESSING) заказов без задачи
+func (ol *OrderListener) enqueueExistingOrders(ctx context.Context) {
+	ol.logger.Info("Enqueueing existing unfinished orders...")
 
-	rows, err := ol.db.QueryContext(ctx,
-		`SELECT uid, user_id, number, status, uploaded_at 
-         FROM orders WHERE status='NEW' ORDER BY uploaded_at ASC`)
+	n, err := ol.queue.EnqueuePending(ctx)
 	if err != nil {
 		ol.logger.Errorf("load failed: %v", err)
 		return
 	}
-	defer rows.Close()
 
-	count := 0
-	for rows.Next() {
-		var job Job
-		if err := rows.Scan(&job.OrderID, &job.UserID, &job.Number, &job.Status, &job.CreatedAt); err != nil {
-			ol.logger.Errorf("row scan failed: %v", err)
-			continue
+	ol.logger.Infof("Existing orders enqueued: %d", n)
+	ol.notifyWorkers()
+}
+
+// listenNotifications - держит LISTEN new_orders, переподключаясь с backoff при обрывах
+func (ol *OrderListener) listenNotifications(ctx context.Context) {
+	backoff := minReconnectBackoff
+
+	for ctx.Err() == nil {
+		err := ol.listenOnce(ctx, func() {
+			backoff = minReconnectBackoff
+		})
+		if ctx.Err() != nil {
+			break
 		}
-		job.Attempt = 0
 
-		// ВАЖНО: запускаем обработку заказа в отдельной горутине, без WorkerPool
-		go func(j Job) {
-			if err := ol.processOrder(ctx, j); err != nil {
-				ol.logger.Errorf("failed to process existing order %s: %v", j.Number, err)
-			}
-		}(job)
+		ol.logger.Warnf("LISTEN connection lost, reconnecting in %s: %v", backoff, err)
+		sleepContext(ctx, backoff)
 
-		count++
-	}
-
-	if err := rows.Err(); err != nil {
-		ol.logger.Errorf("rows iteration failed: %v", err)
+		backoff *= 2
+		if backoff > maxReconnectBackoff {
+			backoff = maxReconnectBackoff
+		}
 	}
 
-	ol.logger.Infof("Existing orders loaded: %d", count)
+	ol.logger.Infof("Listen context canceled, stop listening")
 }
 
-func (ol *OrderListener) listenNotifications(ctx context.Context) {
+// listenOnce - одно подключение: LISTEN, догоняющий скан и ожидание нотификаций до первой ошибки
+func (ol *OrderListener) listenOnce(ctx context.Context, onConnected func()) error {
 	cfg, err := pgxpool.ParseConfig(strings.Trim(ol.dbURI, `"`))
 	if err != nil {
-		ol.logger.Errorf("failed to parse pgx config: %v", err)
-		return
+		return fmt.Errorf("failed to parse pgx config: %w", err)
 	}
 
 	conn, err := pgx.ConnectConfig(ctx, cfg.ConnConfig)
 	if err != nil {
-		ol.logger.Errorf("failed to connect to Postgres for LISTEN: %v", err)
-		return
+		return fmt.Errorf("failed to connect to Postgres for LISTEN: %w", err)
 	}
-	defer conn.Close(ctx)
+	defer conn.Close(context.Background())
 
 	if _, err := conn.Exec(ctx, "LISTEN new_orders"); err != nil {
-		ol.logger.Errorf("failed LISTEN: %v", err)
-		return
+		return fmt.Errorf("failed LISTEN: %w", err)
 	}
 
 	ol.logger.Info("Listening for new_orders notifications")
+	onConnected()
+
+	// пока соединения не было, нотификации терялись - догоняем по таблице
+	ol.enqueueExistingOrders(ctx)
 
 	for {
 		n, err := conn.WaitForNotification(ctx)
 		if err != nil {
-			// если контекст отменён — выходим
-			if ctx.Err() != nil {
-				ol.logger.Infof("Listen context canceled, stop listening")
-				return
-			}
-			ol.logger.Warnf("WaitForNotification error, retrying in 5s: %v", err)
-			time.Sleep(5 * time.Second)
-			continue
+			return fmt.Errorf("WaitForNotification: %w", err)
 		}
 
-		var job Job
-		if err := json.Unmarshal([]byte(n.Payload), &job); err != nil {
-			ol.logger.Warnf("Failed to parse notification payload: %v", err)
-			continue
-		}
+		// сама задача уже лежит в order_jobs, нотификация - только сигнал проснуться
+		ol.logger.Infof("New order notification received: %s", n.Payload)
+		ol.notifyWorkers()
+	}
+}
 
-		job.Attempt = 0
-		ol.logger.Infof("New order notification received: %s", job.Number)
+// runReconciliation - периодически возвращает в очередь заказы, зависшие в нефинальном статусе
+func (ol *OrderListener) runReconciliation(ctx context.Context) {
+	ticker := time.NewTicker(ol.cfg.ReconcileInterval)
+	defer ticker.Stop()
 
-		// Снова — запускаем обработку без WorkerPool
-		go func(j Job) {
-			if err := ol.processOrder(ctx, j); err != nil {
-				ol.logger.Errorf("failed to process notified order %s: %v", j.Number, err)
-			}
-		}(job)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			ol.reconcile(ctx)
+		}
 	}
 }
 
-// --------------------------------------------
-// ОБРАБОТКА ЗАКАЗА
-// --------------------------------------------
+func (ol *OrderListener) reconcile(ctx context.Context) {
+	recovered, err := ol.queue.RecoverStale(ctx, ol.cfg.StaleAfter)
+	if err != nil {
+		if ctx.Err() == nil {
+			ol.logger.Errorf("reconciliation failed: %v", err)
+		}
+		return
+	}
 
-func (ol *OrderListener) processOrder(ctx context.Context, job Job) error {
-	ol.logger.Infof("Start processing order %s (uid=%d)", job.Number, job.OrderID)
+	var total int64
+	for _, n := range recovered {
+		total += n
+	}
+	if total == 0 {
+		return
+	}
+
+	ol.logger.Warnf("Reconciliation recovered %d stale orders (older than %s): %v", total, ol.cfg.StaleAfter, recovered)
+	ol.notifyWorkers()
+}
+
+// runPointsExpiry - периодически сжигает баллы старше PointsTTL
+func (ol *OrderListener) runPointsExpiry(ctx context.Context) {
+	ticker := time.NewTicker(ol.cfg.PointsExpiryInterval)
+	defer ticker.Stop()
 
 	for {
 		select {
 		case <-ctx.Done():
-			ol.logger.Infof("Processing cancelled for order %s", job.Number)
-			return fmt.Errorf("cancelled")
+			return
+		case <-ticker.C:
+			ol.expirePoints(ctx)
+		}
+	}
+}
+
+func (ol *OrderListener) expirePoints(ctx context.Context) {
+	n, err := ol.expirer.ExpirePoints(ctx, time.Now().Add(-ol.cfg.PointsTTL))
+	if err != nil {
+		if ctx.Err() == nil {
+			ol.logger.Errorf("points expiry failed: %v", err)
+		}
+		return
+	}
+	if n > 0 {
+		ol.logger.Infof("Points older than %s expired for %d users", ol.cfg.PointsTTL, n)
+	}
+}
+
+func (ol *OrderListener) notifyWorkers() {
+	for i := 0; i < cap(ol.wake); i++ {
+		select {
+		case ol.wake <- struct{}{}:
 		default:
+			return
 		}
+	}
+}
 
-		result, err := ol.queryAccrualService(ctx, job.Number)
-		if err != nil {
-			ol.logger.Warnf("Accrual service error for order %s: %v", job.Number, err)
-			time.Sleep(2 * time.Second)
+// --------------------------------------------
+// ВОРКЕРЫ ОЧЕРЕДИ
+// --------------------------------------------
+
+func (ol *OrderListener) runWorker(ctx context.Context, workerID string) {
+	for ctx.Err() == nil {
+		// пока автомат открыт - паркуемся, а не долбим accrual
+		if ol.breaker.State() == breaker.StateOpen {
+			if err := ol.breaker.Wait(ctx); err != nil {
+				return
+			}
 			continue
 		}
 
-		if result != nil {
-			ol.logger.Infof("Accrual result for order %s: %+v", job.Number, result)
-			if err := ol.updateOrderStatus(ctx, job.OrderID, result.Status, result.Accrual); err != nil {
-				ol.logger.Errorf("failed to update order %s: %v", job.Number, err)
-				return err
+		job, err := ol.queue.Claim(ctx, workerID)
+		if err != nil {
+			if ctx.Err() == nil {
+				ol.logger.Errorf("worker %s: %v", workerID, err)
 			}
+			sleepContext(ctx, pollInterval)
+			continue
+		}
 
-			if result.Status == "PROCESSED" || result.Status == "INVALID" {
-				ol.logger.Infof("Order %s reached final status %s", job.Number, result.Status)
-				return nil
+		if job == nil {
+			// очередь пуста - ждём нотификацию или следующего тика
+			select {
+			case <-ctx.Done():
+			case <-ol.wake:
+			case <-time.After(pollInterval):
 			}
+			continue
 		}
 
-		time.Sleep(2 * time.Second)
+		// взятый заказ доводим до конца даже при остановке, в пределах DrainTimeout
+		ol.inFlight.Add(1)
+		ol.processJob(ol.workCtx, *job)
+		ol.inFlight.Add(-1)
 	}
 }
 
 // --------------------------------------------
-// HTTP запрос к Accrual
+// ОБРАБОТКА ЗАКАЗА
 // --------------------------------------------
 
-func (ol *OrderListener) queryAccrualService(ctx context.Context, number string) (*AccrualResponse, error) {
-	client := http.Client{Timeout: 20 * time.Second}
+// processJob - один опрос accrual по заказу из очереди
+func (ol *OrderListener) processJob(ctx context.Context, job Job) {
+	// в half-open пропускается один пробный запрос, остальные ждут
+	if err := ol.breaker.Allow(); err != nil {
+		ol.reschedule(ctx, job, pollInterval)
+		return
+	}
 
-	addr := ol.accrualSystemAddress
-	if !strings.HasPrefix(addr, "http") {
-		addr = "http://" + addr
+	result, err := ol.accrual.GetOrder(ctx, job.Number)
+	if ctx.Err() != nil {
+		// задачу заберут после истечения блокировки
+		ol.breaker.Release()
+		return
+	}
+	if accrualclient.IsUnavailable(err) {
+		ol.breaker.Failure()
+		ol.logger.Warnf("Accrual service error for order %s: %v", job.Number, err)
+		ol.fail(ctx, job, err)
+		return
 	}
+	ol.breaker.Success()
 
-	url := fmt.Sprintf("%s/api/orders/%s", addr, number)
-	ol.logger.Infof("Querying accrual service: %s", url)
+	var rateErr *accrualclient.RateLimitError
+	switch {
+	case errors.As(err, &rateErr):
+		ol.logger.Warnf("Rate limited, retrying after %s", rateErr.RetryAfter)
+		ol.reschedule(ctx, job, rateErr.RetryAfter)
+		return
+	case errors.Is(err, accrualclient.ErrNotRegistered):
+		ol.logger.Infof("Accrual service: order %s not yet registered", job.Number)
+		ol.reschedule(ctx, job, pollInterval)
+		return
+	}
 
-	resp, err := client.Get(url)
+	ol.logger.Infof("Accrual result for order %s: %+v", job.Number, result)
+	status, err := models.OrderStatusFromAccrual(result.Status)
 	if err != nil {
-		return nil, fmt.Errorf("http request failed: %w", err)
+		ol.logger.Errorf("order %s: %v", job.Number, err)
+		ol.fail(ctx, job, err)
+		return
 	}
-	defer resp.Body.Close()
 
-	switch resp.StatusCode {
-	case http.StatusOK:
-		var r AccrualResponse
-		if err := json.NewDecoder(resp.Body).Decode(&r); err != nil {
-			return nil, fmt.Errorf("failed to decode accrual response: %w", err)
+	if err := ol.orders.UpdateOrderStatus(ctx, job.OrderID, status, result.Accrual); err != nil {
+		if errors.Is(err, models.ErrInvalidStatusTransition) {
+			// заказ уже в финальном статусе, запоздавший ответ accrual игнорируем
+			ol.logger.Warnf("order %s: %v", job.Number, err)
+			ol.complete(ctx, job)
+			return
 		}
-		return &r, nil
+		ol.logger.Errorf("failed to update order %s: %v", job.Number, err)
+		ol.fail(ctx, job, err)
+		return
+	}
+	ol.logger.Infof("Order %d updated: status=%s, accrual=%.2f", job.OrderID, status, result.Accrual)
+	ol.recordAudit(ctx, models.AuditOrderStatusChanged, job.Number, map[string]any{
+		"status":  status,
+		"accrual": result.Accrual,
+	})
+
+	if models.IsFinalOrderStatus(status) {
+		ol.logger.Infof("Order %s reached final status %s", job.Number, status)
+		ol.notifyUser(ctx, job, status, result.Accrual)
+		ol.complete(ctx, job)
+		return
+	}
+
+	ol.reschedule(ctx, job, pollInterval)
+}
+
+func (ol *OrderListener) complete(ctx context.Context, job Job) {
+	if err := ol.queue.Complete(ctx, job.ID); err != nil {
+		ol.logger.Errorf("%v", err)
+	}
+}
+
+func (ol *OrderListener) reschedule(ctx context.Context, job Job, delay time.Duration) {
+	if err := ol.queue.Reschedule(ctx, job.ID, delay); err != nil {
+		ol.logger.Errorf("%v", err)
+	}
+}
 
-	case http.StatusTooManyRequests:
-		ra := resp.Header.Get("Retry-After")
-		sec, _ := strconv.Atoi(ra)
-		if sec == 0 {
-			sec = 60
+func (ol *OrderListener) fail(ctx context.Context, job Job, cause error) {
+	if job.Attempt+1 < ol.cfg.MaxAttempts {
+		if err := ol.queue.Fail(ctx, job.ID, cause, retryDelay(job.Attempt)); err != nil {
+			ol.logger.Errorf("%v", err)
 		}
-		ol.logger.Warnf("Rate limited, retrying after %d seconds", sec)
-		time.Sleep(time.Duration(sec) * time.Second)
-		return nil, fmt.Errorf("rate limit")
+		return
+	}
+
+	if err := ol.queue.DeadLetter(ctx, job.ID, cause); err != nil {
+		ol.logger.Errorf("%v", err)
+		return
+	}
+	ol.logger.Errorf("Order %s moved to dead-letter queue after %d attempts: %v", job.Number, job.Attempt+1, cause)
+	ol.recordAudit(ctx, models.AuditOrderDeadLettered, job.Number, map[string]any{
+		"attempts": job.Attempt + 1,
+		"error":    cause.Error(),
+	})
+	ol.refreshDeadLetterMetrics(ctx)
+}
 
-	case http.StatusNoContent:
-		ol.logger.Infof("Accrual service: order %s not yet registered", number)
-		return nil, nil
+// --------------------------------------------
+// МЕТРИКИ
+// --------------------------------------------
 
-	default:
-		return nil, fmt.Errorf("unexpected status: %d", resp.StatusCode)
+// runDeadLetterMetrics - периодически обновляет размер DLQ, его меняют и админы через API
+func (ol *OrderListener) runDeadLetterMetrics(ctx context.Context) {
+	ticker := time.NewTicker(30 * time.Second)
+	defer ticker.Stop()
+
+	for {
+		ol.refreshDeadLetterMetrics(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
 	}
 }
 
-func (ol *OrderListener) updateOrderStatus(ctx context.Context, uid int, status string, accrual float64) error {
-	res, err := ol.db.ExecContext(ctx,
-		`UPDATE orders SET status=$1, accrual=$2, uploaded_at=NOW() WHERE uid=$3`,
-		status, accrual, uid)
+func (ol *OrderListener) refreshDeadLetterMetrics(ctx context.Context) {
+	n, err := ol.queue.DeadLetterCount(ctx)
 	if err != nil {
-		return fmt.Errorf("db update failed: %w", err)
+		if ctx.Err() == nil {
+			ol.logger.Warnf("%v", err)
+		}
+		return
 	}
+	metrics.DeadLetterQueueSize.Set(n)
+}
 
-	n, _ := res.RowsAffected()
-	if n == 0 {
-		return fmt.Errorf("order not found uid=%d", uid)
-	}
+// sleepContext - пауза, прерываемая отменой контекста
+func sleepContext(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
 
-	ol.logger.Infof("Order %d updated: status=%s, accrual=%.2f", uid, status, accrual)
-	return nil
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
 }
 
+// Stop - перестаёт брать новые задачи, ждёт заказы в обработке не дольше DrainTimeout
+// и только потом закрывает соединения
 func (ol *OrderListener) Stop() {
+	if ol.cancel != nil {
+		ol.logger.Infof("Stopping order listener, in-flight orders: %d", ol.inFlight.Load())
+		ol.cancel()
+
+		done := make(chan struct{})
+		go func() {
+			ol.wg.Wait()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+			ol.logger.Info("In-flight orders drained")
+		case <-time.After(ol.cfg.DrainTimeout):
+			ol.logger.Warnf("Drain timeout %s exceeded, aborting %d in-flight orders", ol.cfg.DrainTimeout, ol.inFlight.Load())
+			ol.cancelWork()
+			<-done
+		}
+		ol.cancelWork()
+	}
+
 	if ol.db != nil {
 		ol.db.Close()
 	}
 	ol.logger.Info("Order listener stopped")
 }
+
+// notifyUser - письмо о заказе в финальном статусе, ошибка только логируется
+func (ol *OrderListener) notifyUser(ctx context.Context, job Job, status string, accrual float64) {
+	kind := models.NotificationKind(status)
+	if ol.notify == nil || kind == "" {
+		return
+	}
+	data := notify.Data{Order: job.Number, Amount: accrual}
+	if err := ol.notify.NotifyUser(context.WithoutCancel(ctx), job.UserID, kind, data); err != nil {
+		ol.logger.Errorf("notify order %s: %v", job.Number, err)
+	}
+}
+
+// recordAudit - запись действия системы по заказу, ошибка только логируется
+func (ol *OrderListener) recordAudit(ctx context.Context, action, number string, payload map[string]any) {
+	if ol.audit == nil {
+		return
+	}
+
+	raw, err := json.Marshal(payload)
+	if err != nil {
+		ol.logger.Errorf("audit %s: %v", action, err)
+		return
+	}
+
+	event := models.AuditEvent{Action: action, Target: models.OrderTarget(number), Payload: raw}
+	if err := ol.audit.RecordAudit(context.WithoutCancel(ctx), event); err != nil {
+		ol.logger.Errorf("audit %s: %v", action, err)
+	}
+}
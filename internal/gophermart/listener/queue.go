@@ -0,0 +1,187 @@
+package listener
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// JobQueue - очередь задач на опрос accrual в таблице order_jobs.
+// Задача забирается с FOR UPDATE SKIP LOCKED и блокируется на время visibility;
+// если воркер умер, по истечении блокировки задачу заберёт другой
+type JobQueue struct {
+	db         *sql.DB
+	visibility time.Duration
+}
+
+func NewJobQueue(db *sql.DB, visibility time.Duration) *JobQueue {
+	if visibility <= 0 {
+		visibility = time.Minute
+	}
+	return &JobQueue{db: db, visibility: visibility}
+}
+
+// Claim - забирает готовую к выполнению задачу, nil если задач нет
+func (q *JobQueue) Claim(ctx context.Context, workerID string) (*Job, error) {
+	var job Job
+	err := q.db.QueryRowContext(ctx, `
+        WITH claimed AS (
+            UPDATE order_jobs
+            SET locked_until = NOW() + make_interval(secs => $1),
+                locked_by = $2
+            WHERE id = (
+                SELECT id FROM order_jobs
+                WHERE run_at <= NOW()
+                    AND (locked_until IS NULL OR locked_until < NOW())
+                ORDER BY run_at
+                LIMIT 1
+                FOR UPDATE SKIP LOCKED
+            )
+            RETURNING id, order_id, attempts
+        )
+        SELECT c.id, c.order_id, o.user_id, o.number, o.status, c.attempts, o.uploaded_at
+        FROM claimed c
+        JOIN orders o ON o.uid = c.order_id`,
+		q.visibility.Seconds(), workerID,
+	).Scan(&job.ID, &job.OrderID, &job.UserID, &job.Number, &job.Status, &job.Attempt, &job.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim job: %w", err)
+	}
+	return &job, nil
+}
+
+// Complete - заказ в финальном статусе, задача больше не нужна
+func (q *JobQueue) Complete(ctx context.Context, jobID int) error {
+	if _, err := q.db.ExecContext(ctx, `DELETE FROM order_jobs WHERE id = $1`, jobID); err != nil {
+		return fmt.Errorf("failed to complete job %d: %w", jobID, err)
+	}
+	return nil
+}
+
+// Reschedule - повторить опрос через delay, не считая это ошибкой
+func (q *JobQueue) Reschedule(ctx context.Context, jobID int, delay time.Duration) error {
+	_, err := q.db.ExecContext(ctx, `
+        UPDATE order_jobs
+        SET run_at = NOW() + make_interval(secs => $2),
+            locked_until = NULL,
+            locked_by = NULL
+        WHERE id = $1`, jobID, delay.Seconds())
+	if err != nil {
+		return fmt.Errorf("failed to reschedule job %d: %w", jobID, err)
+	}
+	return nil
+}
+
+// Fail - попытка завершилась ошибкой, повтор через delay
+func (q *JobQueue) Fail(ctx context.Context, jobID int, cause error, delay time.Duration) error {
+	_, err := q.db.ExecContext(ctx, `
+        UPDATE order_jobs
+        SET attempts = attempts + 1,
+            last_error = $2,
+            run_at = NOW() + make_interval(secs => $3),
+            locked_until = NULL,
+            locked_by = NULL
+        WHERE id = $1`, jobID, cause.Error(), delay.Seconds())
+	if err != nil {
+		return fmt.Errorf("failed to fail job %d: %w", jobID, err)
+	}
+	return nil
+}
+
+// DeadLetter - переносит задачу в order_dead_letters, обработка прекращается до ручного requeue
+func (q *JobQueue) DeadLetter(ctx context.Context, jobID int, cause error) error {
+	_, err := q.db.ExecContext(ctx, `
+        WITH moved AS (
+            DELETE FROM order_jobs WHERE id = $1
+            RETURNING order_id, attempts
+        )
+        INSERT INTO order_dead_letters (order_id, last_error, attempts)
+        SELECT order_id, $2, attempts + 1 FROM moved
+        ON CONFLICT (order_id) DO UPDATE
+        SET last_error = EXCLUDED.last_error,
+            attempts = EXCLUDED.attempts,
+            dead_at = NOW()`, jobID, cause.Error())
+	if err != nil {
+		return fmt.Errorf("failed to dead-letter job %d: %w", jobID, err)
+	}
+	return nil
+}
+
+// DeadLetterCount - размер очереди недоставленных заказов
+func (q *JobQueue) DeadLetterCount(ctx context.Context) (int64, error) {
+	var n int64
+	if err := q.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM order_dead_letters`).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count dead letters: %w", err)
+	}
+	return n, nil
+}
+
+// EnqueuePending - ставит в очередь незавершённые заказы, у которых нет задачи и которые не в DLQ
+func (q *JobQueue) EnqueuePending(ctx context.Context) (int64, error) {
+	res, err := q.db.ExecContext(ctx, `
+        INSERT INTO order_jobs (order_id)
+        SELECT o.uid FROM orders o
+        WHERE o.status IN ('NEW', 'PROCESSING')
+            AND NOT EXISTS (SELECT 1 FROM order_dead_letters d WHERE d.order_id = o.uid)
+        ON CONFLICT (order_id) DO NOTHING`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to enqueue pending orders: %w", err)
+	}
+	return res.RowsAffected()
+}
+
+// RecoverStale - ставит в очередь незавершённые заказы без задачи, которые не обновлялись дольше staleAfter.
+// Возвращает число восстановленных заказов по статусам
+func (q *JobQueue) RecoverStale(ctx context.Context, staleAfter time.Duration) (map[string]int64, error) {
+	rows, err := q.db.QueryContext(ctx, `
+        WITH recovered AS (
+            INSERT INTO order_jobs (order_id)
+            SELECT o.uid FROM orders o
+            WHERE o.status IN ('NEW', 'PROCESSING')
+                AND o.updated_at < NOW() - make_interval(secs => $1)
+                AND NOT EXISTS (SELECT 1 FROM order_dead_letters d WHERE d.order_id = o.uid)
+            ON CONFLICT (order_id) DO NOTHING
+            RETURNING order_id
+        )
+        SELECT o.status, COUNT(*)
+        FROM recovered r
+        JOIN orders o ON o.uid = r.order_id
+        GROUP BY o.status`, staleAfter.Seconds())
+	if err != nil {
+		return nil, fmt.Errorf("failed to recover stale orders: %w", err)
+	}
+	defer rows.Close()
+
+	recovered := make(map[string]int64)
+	for rows.Next() {
+		var status string
+		var n int64
+		if err := rows.Scan(&status, &n); err != nil {
+			return nil, fmt.Errorf("failed to scan recovered orders: %w", err)
+		}
+		recovered[status] = n
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to recover stale orders: %w", err)
+	}
+	return recovered, nil
+}
+
+// retryDelay - экспоненциальная пауза между неудачными попытками
+func retryDelay(attempt int) time.Duration {
+	const maxDelay = 5 * time.Minute
+
+	delay := 2 * time.Second
+	for i := 0; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay
+}
@@ -0,0 +1,131 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-musthave-diploma-tpl/internal/gophermart/listener"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJobQueue_Claim(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	queue := listener.NewJobQueue(db, 30*time.Second)
+	uploadedAt := time.Now()
+
+	mock.ExpectQuery(`WITH claimed AS \(\s*UPDATE order_jobs.*FOR UPDATE SKIP LOCKED`).
+		WithArgs(30.0, "worker-1").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "user_id", "number", "status", "attempts", "uploaded_at"}).
+			AddRow(7, 42, 1, "12345678903", "NEW", 2, uploadedAt))
+
+	job, err := queue.Claim(context.Background(), "worker-1")
+	require.NoError(t, err)
+	require.NotNil(t, job)
+	assert.Equal(t, listener.Job{
+		ID:        7,
+		OrderID:   42,
+		UserID:    1,
+		Number:    "12345678903",
+		Status:    "NEW",
+		Attempt:   2,
+		CreatedAt: uploadedAt,
+	}, *job)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJobQueue_ClaimEmpty(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`WITH claimed AS`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "order_id", "user_id", "number", "status", "attempts", "uploaded_at"}))
+
+	job, err := listener.NewJobQueue(db, time.Minute).Claim(context.Background(), "worker-1")
+	assert.NoError(t, err)
+	assert.Nil(t, job)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJobQueue_Lifecycle(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	queue := listener.NewJobQueue(db, time.Minute)
+	ctx := context.Background()
+
+	mock.ExpectExec(`UPDATE order_jobs\s+SET run_at`).
+		WithArgs(7, 2.0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`UPDATE order_jobs\s+SET attempts = attempts \+ 1`).
+		WithArgs(7, "accrual is down", 8.0).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM order_jobs WHERE id = \$1`).
+		WithArgs(7).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	require.NoError(t, queue.Reschedule(ctx, 7, 2*time.Second))
+	require.NoError(t, queue.Fail(ctx, 7, errors.New("accrual is down"), 8*time.Second))
+	require.NoError(t, queue.Complete(ctx, 7))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJobQueue_EnqueuePending(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(`INSERT INTO order_jobs \(order_id\)\s+SELECT o.uid FROM orders o.*NOT EXISTS \(SELECT 1 FROM order_dead_letters`).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	n, err := listener.NewJobQueue(db, time.Minute).EnqueuePending(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJobQueue_DeadLetter(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectExec(`WITH moved AS \(\s*DELETE FROM order_jobs WHERE id = \$1.*INSERT INTO order_dead_letters`).
+		WithArgs(7, "order not found uid=42").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM order_dead_letters`).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+
+	queue := listener.NewJobQueue(db, time.Minute)
+	require.NoError(t, queue.DeadLetter(context.Background(), 7, errors.New("order not found uid=42")))
+
+	n, err := queue.DeadLetterCount(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestJobQueue_RecoverStale(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(`WITH recovered AS \(\s*INSERT INTO order_jobs.*updated_at < NOW\(\) - make_interval\(secs => \$1\)`).
+		WithArgs(300.0).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "count"}).
+			AddRow("NEW", 1).
+			AddRow("PROCESSING", 2))
+
+	recovered, err := listener.NewJobQueue(db, time.Minute).RecoverStale(context.Background(), 5*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int64{"NEW": 1, "PROCESSING": 2}, recovered)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -3,6 +3,7 @@ package listener
 import "time"
 
 type Job struct {
+	ID        int       `json:"-"`
 	OrderID   int       `json:"order_id"`
 	UserID    int       `json:"user_id"`
 	Number    string    `json:"number"`
@@ -10,9 +11,3 @@ type Job struct {
 	Attempt   int       `json:"attempt"`
 	CreatedAt time.Time `json:"created_at"`
 }
-
-type AccrualResponse struct {
-	Order   int64   `json:"order"`
-	Status  string  `json:"status"`
-	Accrual float64 `json:"accrual,omitempty"`
-}
@@ -0,0 +1,11 @@
+package metrics
+
+import (
+	"expvar"
+)
+
+// метрики публикуются через expvar и отдаются админским эндпоинтом /api/admin/metrics
+var (
+	// размер очереди недоставленных заказов
+	DeadLetterQueueSize = expvar.NewInt("gophermart_dead_letter_queue_size")
+)
@@ -65,6 +65,27 @@ func AccessCookieMiddleware(repo *service.GofemartService) func(http.Handler) ht
 	}
 }
 
+// AdminMiddleware - пропускает только администраторов, ставится после AccessCookieMiddleware
+func AdminMiddleware(svc *service.GofemartService) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			userIDStr, err := GetUserID(r.Context())
+			if err != nil {
+				http.Error(w, "authentication required", http.StatusUnauthorized)
+				return
+			}
+
+			userID, err := strconv.Atoi(userIDStr)
+			if err != nil || !svc.IsAdmin(userID) {
+				http.Error(w, "forbidden", http.StatusForbidden)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
 // SetEncryptedCookie - публичная функция для установки куки из хендлеров
 // Используется только при успешной регистрации/логине
 func SetEncryptedCookie(w http.ResponseWriter, userID string) {
@@ -27,6 +27,11 @@ func (w *responseWriter) Write(b []byte) (int, error) {
 	return size, err
 }
 
+// Unwrap - доступ к исходному ResponseWriter для http.ResponseController (Flush в SSE)
+func (w *responseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
 func LoggerMiddleware() func(http.Handler) http.Handler {
 	log := logger.NewHTTPLogger()
 
@@ -0,0 +1,75 @@
+package tests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	middlewareDir "go-musthave-diploma-tpl/internal/gophermart/middleware"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+	"go-musthave-diploma-tpl/internal/gophermart/service"
+	serviceMocks "go-musthave-diploma-tpl/internal/gophermart/service/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAdminMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		admins         []string
+		setupMock      func(mockRepo *serviceMocks.MockGofemartRepo)
+		expectedStatus int
+	}{
+		{
+			name:   "Admin passes",
+			admins: []string{"support"},
+			setupMock: func(mockRepo *serviceMocks.MockGofemartRepo) {
+				mockRepo.EXPECT().GetUserByID(1).Return(&models.User{ID: 1, Login: "support"}, nil).Times(2)
+			},
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:   "Regular user is forbidden",
+			admins: []string{"support"},
+			setupMock: func(mockRepo *serviceMocks.MockGofemartRepo) {
+				mockRepo.EXPECT().GetUserByID(1).Return(&models.User{ID: 1, Login: "customer"}, nil).Times(2)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:   "No admins configured",
+			admins: nil,
+			setupMock: func(mockRepo *serviceMocks.MockGofemartRepo) {
+				mockRepo.EXPECT().GetUserByID(1).Return(&models.User{ID: 1, Login: "support"}, nil)
+			},
+			expectedStatus: http.StatusForbidden,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := serviceMocks.NewMockGofemartRepo(ctrl)
+			svc := service.NewGofemartService(mockRepo, "http://localhost:8081")
+			svc.SetAdminLogins(tt.admins)
+			tt.setupMock(mockRepo)
+
+			handler := middlewareDir.AccessCookieMiddleware(svc)(
+				middlewareDir.AdminMiddleware(svc)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+					w.WriteHeader(http.StatusOK)
+				})))
+
+			req := httptest.NewRequest("GET", "/api/admin/dead-letters", nil)
+			encrypted, _ := middlewareDir.Encrypt("1")
+			req.AddCookie(&http.Cookie{Name: "userID", Value: encrypted})
+			rr := httptest.NewRecorder()
+
+			handler.ServeHTTP(rr, req)
+
+			assert.Equal(t, tt.expectedStatus, rr.Code)
+		})
+	}
+}
@@ -0,0 +1 @@
+ALTER TABLE orders DROP COLUMN IF EXISTS processed_at;
\ No newline at end of file
@@ -0,0 +1,4 @@
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS processed_at TIMESTAMP WITH TIME ZONE;
+
+-- раньше время финального статуса затирало uploaded_at
+UPDATE orders SET processed_at = uploaded_at WHERE status IN ('PROCESSED', 'INVALID');
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_withdrawals_user_processed_at;
+
+DROP INDEX IF EXISTS idx_orders_user_uploaded_at;
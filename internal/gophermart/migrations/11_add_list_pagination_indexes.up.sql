@@ -0,0 +1,3 @@
+CREATE INDEX IF NOT EXISTS idx_orders_user_uploaded_at ON orders(user_id, uploaded_at, uid);
+
+CREATE INDEX IF NOT EXISTS idx_withdrawals_user_processed_at ON withdrawals(user_id, processed_at, uid);
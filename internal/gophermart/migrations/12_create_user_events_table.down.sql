@@ -0,0 +1,10 @@
+DROP TRIGGER IF EXISTS trg_notify_withdrawal ON withdrawals;
+DROP FUNCTION IF EXISTS notify_withdrawal();
+
+DROP TRIGGER IF EXISTS trg_notify_order_status_change ON order_status_history;
+DROP FUNCTION IF EXISTS notify_order_status_change();
+
+DROP FUNCTION IF EXISTS publish_balance_event(INTEGER);
+DROP FUNCTION IF EXISTS publish_user_event(INTEGER, TEXT, JSONB);
+
+DROP TABLE IF EXISTS user_events;
@@ -0,0 +1,88 @@
+-- журнал событий пользователя для SSE, id служит Last-Event-ID
+CREATE TABLE IF NOT EXISTS user_events (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    type VARCHAR(32) NOT NULL,
+    data JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_events_user_id ON user_events(user_id, id);
+CREATE INDEX IF NOT EXISTS idx_user_events_created_at ON user_events(created_at);
+
+-- записывает событие и рассылает его инстансам через канал user_events
+CREATE OR REPLACE FUNCTION publish_user_event(p_user_id INTEGER, p_type TEXT, p_data JSONB) RETURNS void AS $$
+DECLARE ev user_events;
+BEGIN
+INSERT INTO user_events (user_id, type, data)
+VALUES (p_user_id, p_type, p_data)
+RETURNING * INTO ev;
+PERFORM pg_notify('user_events', json_build_object(
+    'id',
+    ev.id,
+    'user_id',
+    ev.user_id,
+    'type',
+    ev.type,
+    'data',
+    ev.data,
+    'created_at',
+    ev.created_at
+)::text);
+END;
+$$ LANGUAGE plpgsql;
+
+-- событие с текущим балансом пользователя
+CREATE OR REPLACE FUNCTION publish_balance_event(p_user_id INTEGER) RETURNS void AS $$
+DECLARE accrued NUMERIC;
+DECLARE withdrawn NUMERIC;
+BEGIN
+SELECT COALESCE(SUM(accrual), 0) INTO accrued FROM orders WHERE user_id = p_user_id AND status = 'PROCESSED';
+SELECT COALESCE(SUM(sum), 0) INTO withdrawn FROM withdrawals WHERE user_id = p_user_id;
+PERFORM publish_user_event(p_user_id, 'balance', jsonb_build_object(
+    'current',
+    accrued - withdrawn,
+    'withdrawn',
+    withdrawn
+));
+END;
+$$ LANGUAGE plpgsql;
+
+-- каждый переход статуса заказа попадает в историю, поэтому события вешаются на неё
+CREATE OR REPLACE FUNCTION notify_order_status_change() RETURNS trigger AS $$
+DECLARE o orders;
+BEGIN
+SELECT * INTO o FROM orders WHERE uid = NEW.order_id;
+PERFORM publish_user_event(o.user_id, 'order', jsonb_build_object(
+    'number',
+    o.number,
+    'from',
+    NEW.from_status,
+    'status',
+    NEW.to_status,
+    'accrual',
+    NEW.accrual,
+    'changed_at',
+    NEW.changed_at
+));
+IF NEW.to_status = 'PROCESSED' AND COALESCE(NEW.accrual, 0) > 0 THEN
+    PERFORM publish_balance_event(o.user_id);
+END IF;
+RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+DROP TRIGGER IF EXISTS trg_notify_order_status_change ON order_status_history;
+CREATE TRIGGER trg_notify_order_status_change
+AFTER
+INSERT ON order_status_history FOR EACH ROW EXECUTE FUNCTION notify_order_status_change();
+
+CREATE OR REPLACE FUNCTION notify_withdrawal() RETURNS trigger AS $$
+BEGIN
+PERFORM publish_balance_event(NEW.user_id);
+RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+DROP TRIGGER IF EXISTS trg_notify_withdrawal ON withdrawals;
+CREATE TRIGGER trg_notify_withdrawal
+AFTER
+INSERT ON withdrawals FOR EACH ROW EXECUTE FUNCTION notify_withdrawal();
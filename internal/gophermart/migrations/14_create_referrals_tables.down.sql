@@ -0,0 +1,24 @@
+DROP TRIGGER IF EXISTS trg_notify_balance_adjustment ON balance_adjustments;
+DROP FUNCTION IF EXISTS notify_balance_adjustment();
+
+CREATE OR REPLACE FUNCTION publish_balance_event(p_user_id INTEGER) RETURNS void AS $$
+DECLARE accrued NUMERIC;
+DECLARE withdrawn NUMERIC;
+BEGIN
+SELECT COALESCE(SUM(accrual), 0) INTO accrued FROM orders WHERE user_id = p_user_id AND status = 'PROCESSED';
+SELECT COALESCE(SUM(sum), 0) INTO withdrawn FROM withdrawals WHERE user_id = p_user_id;
+PERFORM publish_user_event(p_user_id, 'balance', jsonb_build_object(
+    'current',
+    accrued - withdrawn,
+    'withdrawn',
+    withdrawn
+));
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TABLE IF EXISTS referrals;
+
+DROP INDEX IF EXISTS idx_users_referral_code;
+ALTER TABLE users DROP COLUMN IF EXISTS referral_code;
+
+DROP TABLE IF EXISTS balance_adjustments;
@@ -0,0 +1,55 @@
+-- начисления и корректировки баланса помимо заказов: бонусы, промокоды, ручные правки
+CREATE TABLE IF NOT EXISTS balance_adjustments (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    amount NUMERIC(10,2) NOT NULL,
+    kind VARCHAR(32) NOT NULL,
+    reference TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_balance_adjustments_user_id ON balance_adjustments(user_id);
+
+-- код выдаётся каждому пользователю, включая уже зарегистрированных
+ALTER TABLE users ADD COLUMN IF NOT EXISTS referral_code VARCHAR(16) NOT NULL DEFAULT substr(md5(random()::text || clock_timestamp()::text), 1, 10);
+CREATE UNIQUE INDEX IF NOT EXISTS idx_users_referral_code ON users(referral_code);
+
+CREATE TABLE IF NOT EXISTS referrals (
+    id SERIAL PRIMARY KEY,
+    referrer_id INTEGER NOT NULL REFERENCES users(id),
+    referred_id INTEGER NOT NULL UNIQUE REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    -- бонусы начислены после первого обработанного заказа приглашённого
+    rewarded_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE INDEX IF NOT EXISTS idx_referrals_referrer_id ON referrals(referrer_id);
+
+-- баланс в событиях SSE учитывает корректировки
+CREATE OR REPLACE FUNCTION publish_balance_event(p_user_id INTEGER) RETURNS void AS $$
+DECLARE accrued NUMERIC;
+DECLARE adjusted NUMERIC;
+DECLARE withdrawn NUMERIC;
+BEGIN
+SELECT COALESCE(SUM(accrual), 0) INTO accrued FROM orders WHERE user_id = p_user_id AND status = 'PROCESSED';
+SELECT COALESCE(SUM(amount), 0) INTO adjusted FROM balance_adjustments WHERE user_id = p_user_id;
+SELECT COALESCE(SUM(sum), 0) INTO withdrawn FROM withdrawals WHERE user_id = p_user_id;
+PERFORM publish_user_event(p_user_id, 'balance', jsonb_build_object(
+    'current',
+    accrued + adjusted - withdrawn,
+    'withdrawn',
+    withdrawn
+));
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION notify_balance_adjustment() RETURNS trigger AS $$
+BEGIN
+PERFORM publish_balance_event(NEW.user_id);
+RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+DROP TRIGGER IF EXISTS trg_notify_balance_adjustment ON balance_adjustments;
+CREATE TRIGGER trg_notify_balance_adjustment
+AFTER
+INSERT ON balance_adjustments FOR EACH ROW EXECUTE FUNCTION notify_balance_adjustment();
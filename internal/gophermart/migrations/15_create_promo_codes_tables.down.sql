@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_balance_adjustments_user_created;
+DROP TABLE IF EXISTS promo_redemptions;
+DROP TABLE IF EXISTS promo_codes;
@@ -0,0 +1,2 @@
+ALTER TABLE balance_adjustments DROP COLUMN IF EXISTS created_by;
+ALTER TABLE balance_adjustments DROP COLUMN IF EXISTS reason;
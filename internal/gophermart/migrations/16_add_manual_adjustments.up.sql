@@ -0,0 +1,3 @@
+-- ручные корректировки: кто и почему изменил баланс
+ALTER TABLE balance_adjustments ADD COLUMN IF NOT EXISTS reason TEXT;
+ALTER TABLE balance_adjustments ADD COLUMN IF NOT EXISTS created_by INTEGER REFERENCES users(id);
@@ -0,0 +1,19 @@
+-- срабатывания правил антифрода для разбора администраторами
+CREATE TABLE IF NOT EXISTS fraud_flags (
+    id BIGSERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    rule VARCHAR(64) NOT NULL,
+    event VARCHAR(32) NOT NULL,
+    action VARCHAR(16) NOT NULL,
+    details JSONB,
+    status VARCHAR(16) NOT NULL DEFAULT 'OPEN',
+    reviewed_by INTEGER REFERENCES users(id),
+    review_note TEXT,
+    reviewed_at TIMESTAMP WITH TIME ZONE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_fraud_flags_created ON fraud_flags(created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS idx_fraud_flags_user ON fraud_flags(user_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_fraud_flags_open ON fraud_flags(created_at DESC) WHERE status = 'OPEN';
+
@@ -0,0 +1,23 @@
+CREATE OR REPLACE FUNCTION publish_balance_event(p_user_id INTEGER) RETURNS void AS $$
+DECLARE accrued NUMERIC;
+DECLARE adjusted NUMERIC;
+DECLARE withdrawn NUMERIC;
+BEGIN
+SELECT COALESCE(SUM(accrual), 0) INTO accrued FROM orders WHERE user_id = p_user_id AND status = 'PROCESSED';
+SELECT COALESCE(SUM(amount), 0) INTO adjusted FROM balance_adjustments WHERE user_id = p_user_id;
+SELECT COALESCE(SUM(sum), 0) INTO withdrawn FROM withdrawals WHERE user_id = p_user_id;
+PERFORM publish_user_event(p_user_id, 'balance', jsonb_build_object(
+    'current',
+    accrued + adjusted - withdrawn,
+    'withdrawn',
+    withdrawn
+));
+END;
+$$ LANGUAGE plpgsql;
+
+ALTER TABLE withdrawals DROP COLUMN IF EXISTS household_id;
+ALTER TABLE orders DROP COLUMN IF EXISTS household_id;
+
+DROP TABLE IF EXISTS household_invites;
+DROP TABLE IF EXISTS household_members;
+DROP TABLE IF EXISTS households;
@@ -0,0 +1,58 @@
+-- семьи с общим кошельком баллов
+CREATE TABLE IF NOT EXISTS households (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) NOT NULL,
+    owner_id INTEGER NOT NULL REFERENCES users(id),
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- пользователь состоит не более чем в одной семье
+CREATE TABLE IF NOT EXISTS household_members (
+    household_id INTEGER NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL UNIQUE REFERENCES users(id),
+    role VARCHAR(16) NOT NULL DEFAULT 'member',
+    can_withdraw BOOLEAN NOT NULL DEFAULT FALSE,
+    -- максимальная сумма одного списания, NULL - без ограничения
+    withdraw_limit NUMERIC(10,2),
+    share_accruals BOOLEAN NOT NULL DEFAULT FALSE,
+    joined_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (household_id, user_id)
+);
+
+CREATE TABLE IF NOT EXISTS household_invites (
+    id SERIAL PRIMARY KEY,
+    household_id INTEGER NOT NULL REFERENCES households(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id),
+    invited_by INTEGER NOT NULL REFERENCES users(id),
+    status VARCHAR(16) NOT NULL DEFAULT 'PENDING',
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    responded_at TIMESTAMP WITH TIME ZONE
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_household_invites_pending ON household_invites(household_id, user_id) WHERE status = 'PENDING';
+CREATE INDEX IF NOT EXISTS idx_household_invites_user ON household_invites(user_id, created_at DESC);
+
+-- NULL - личный кошелёк, иначе начисление или списание относится к общему кошельку семьи
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS household_id INTEGER REFERENCES households(id);
+ALTER TABLE withdrawals ADD COLUMN IF NOT EXISTS household_id INTEGER REFERENCES households(id);
+
+CREATE INDEX IF NOT EXISTS idx_orders_household_id ON orders(household_id) WHERE household_id IS NOT NULL;
+CREATE INDEX IF NOT EXISTS idx_withdrawals_household_id ON withdrawals(household_id, processed_at DESC) WHERE household_id IS NOT NULL;
+
+-- баланс в событиях SSE - личный кошелёк
+CREATE OR REPLACE FUNCTION publish_balance_event(p_user_id INTEGER) RETURNS void AS $$
+DECLARE accrued NUMERIC;
+DECLARE adjusted NUMERIC;
+DECLARE withdrawn NUMERIC;
+BEGIN
+SELECT COALESCE(SUM(accrual), 0) INTO accrued FROM orders WHERE user_id = p_user_id AND status = 'PROCESSED' AND household_id IS NULL;
+SELECT COALESCE(SUM(amount), 0) INTO adjusted FROM balance_adjustments WHERE user_id = p_user_id;
+SELECT COALESCE(SUM(sum), 0) INTO withdrawn FROM withdrawals WHERE user_id = p_user_id AND household_id IS NULL;
+PERFORM publish_user_event(p_user_id, 'balance', jsonb_build_object(
+    'current',
+    accrued + adjusted - withdrawn,
+    'withdrawn',
+    withdrawn
+));
+END;
+$$ LANGUAGE plpgsql;
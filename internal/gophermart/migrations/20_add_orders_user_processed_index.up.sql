@@ -0,0 +1,2 @@
+-- помесячные начисления пользователя в профиле без чтения строк таблицы
+CREATE INDEX IF NOT EXISTS idx_orders_user_processed ON orders(user_id, processed_at) INCLUDE (accrual) WHERE status = 'PROCESSED';
@@ -0,0 +1,20 @@
+CREATE OR REPLACE FUNCTION publish_user_event(p_user_id INTEGER, p_type TEXT, p_data JSONB) RETURNS void AS $$
+DECLARE ev user_events;
+BEGIN
+INSERT INTO user_events (user_id, type, data)
+VALUES (p_user_id, p_type, p_data)
+RETURNING * INTO ev;
+PERFORM pg_notify('user_events', json_build_object(
+    'id',
+    ev.id,
+    'user_id',
+    ev.user_id,
+    'type',
+    ev.type,
+    'data',
+    ev.data,
+    'created_at',
+    ev.created_at
+)::text);
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,25 @@
+-- id события выделяется при INSERT, а виден он после COMMIT. Без блокировки событие
+-- с меньшим id могло стать видимым после большего и потеряться для Last-Event-ID.
+-- Блокировка на пользователя держится до конца транзакции, поэтому id событий
+-- одного пользователя выдаются в порядке фиксации
+CREATE OR REPLACE FUNCTION publish_user_event(p_user_id INTEGER, p_type TEXT, p_data JSONB) RETURNS void AS $$
+DECLARE ev user_events;
+BEGIN
+PERFORM pg_advisory_xact_lock(hashtext('user_events'), p_user_id);
+INSERT INTO user_events (user_id, type, data)
+VALUES (p_user_id, p_type, p_data)
+RETURNING * INTO ev;
+PERFORM pg_notify('user_events', json_build_object(
+    'id',
+    ev.id,
+    'user_id',
+    ev.user_id,
+    'type',
+    ev.type,
+    'data',
+    ev.data,
+    'created_at',
+    ev.created_at
+)::text);
+END;
+$$ LANGUAGE plpgsql;
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_fraud_flags_open_rule;
+ALTER TABLE fraud_flags DROP COLUMN IF EXISTS last_hit_at;
+ALTER TABLE fraud_flags DROP COLUMN IF EXISTS hits;
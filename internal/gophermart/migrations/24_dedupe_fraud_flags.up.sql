@@ -0,0 +1,28 @@
+-- повторные срабатывания правила копятся в открытом флаге, а не создают новые
+ALTER TABLE fraud_flags ADD COLUMN IF NOT EXISTS hits INTEGER NOT NULL DEFAULT 1;
+ALTER TABLE fraud_flags ADD COLUMN IF NOT EXISTS last_hit_at TIMESTAMP WITH TIME ZONE;
+UPDATE fraud_flags SET last_hit_at = created_at WHERE last_hit_at IS NULL;
+ALTER TABLE fraud_flags ALTER COLUMN last_hit_at SET DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE fraud_flags ALTER COLUMN last_hit_at SET NOT NULL;
+
+-- накопившиеся дубли сворачиваются в самый ранний открытый флаг
+WITH dup AS (
+    SELECT id,
+        MIN(id) OVER w AS keep_id,
+        COUNT(*) OVER w AS total,
+        MAX(created_at) OVER w AS last_hit
+    FROM fraud_flags
+    WHERE status = 'OPEN'
+    WINDOW w AS (PARTITION BY user_id, rule)
+)
+UPDATE fraud_flags f
+SET hits = dup.total, last_hit_at = dup.last_hit
+FROM dup
+WHERE f.id = dup.id AND dup.id = dup.keep_id AND dup.total > 1;
+
+DELETE FROM fraud_flags f
+USING fraud_flags keep
+WHERE f.status = 'OPEN' AND keep.status = 'OPEN'
+    AND keep.user_id = f.user_id AND keep.rule = f.rule AND keep.id < f.id;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_fraud_flags_open_rule ON fraud_flags(user_id, rule) WHERE status = 'OPEN';
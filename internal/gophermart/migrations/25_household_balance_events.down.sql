@@ -0,0 +1,31 @@
+CREATE OR REPLACE FUNCTION notify_withdrawal() RETURNS trigger AS $$
+BEGIN
+PERFORM publish_balance_event(NEW.user_id);
+RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION notify_order_status_change() RETURNS trigger AS $$
+DECLARE o orders;
+BEGIN
+SELECT * INTO o FROM orders WHERE uid = NEW.order_id;
+PERFORM publish_user_event(o.user_id, 'order', jsonb_build_object(
+    'number',
+    o.number,
+    'from',
+    NEW.from_status,
+    'status',
+    NEW.to_status,
+    'accrual',
+    NEW.accrual,
+    'changed_at',
+    NEW.changed_at
+));
+IF NEW.to_status = 'PROCESSED' AND COALESCE(NEW.accrual, 0) > 0 THEN
+    PERFORM publish_balance_event(o.user_id);
+END IF;
+RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP FUNCTION IF EXISTS publish_household_balance_event(INTEGER);
@@ -0,0 +1,61 @@
+-- баланс общего кошелька семьи рассылается всем участникам,
+-- считается как householdBalance в репозитории
+CREATE OR REPLACE FUNCTION publish_household_balance_event(p_household_id INTEGER) RETURNS void AS $$
+DECLARE accrued NUMERIC;
+DECLARE withdrawn NUMERIC;
+DECLARE member_id INTEGER;
+BEGIN
+SELECT COALESCE(SUM(accrual), 0) INTO accrued FROM orders WHERE household_id = p_household_id AND status = 'PROCESSED';
+SELECT COALESCE(SUM(sum), 0) INTO withdrawn FROM withdrawals WHERE household_id = p_household_id;
+FOR member_id IN SELECT user_id FROM household_members WHERE household_id = p_household_id ORDER BY user_id LOOP
+    PERFORM publish_user_event(member_id, 'household_balance', jsonb_build_object(
+        'household_id',
+        p_household_id,
+        'current',
+        accrued - withdrawn,
+        'withdrawn',
+        withdrawn
+    ));
+END LOOP;
+END;
+$$ LANGUAGE plpgsql;
+
+-- начисление в общий кошелёк не меняет личный баланс
+CREATE OR REPLACE FUNCTION notify_order_status_change() RETURNS trigger AS $$
+DECLARE o orders;
+BEGIN
+SELECT * INTO o FROM orders WHERE uid = NEW.order_id;
+PERFORM publish_user_event(o.user_id, 'order', jsonb_build_object(
+    'number',
+    o.number,
+    'from',
+    NEW.from_status,
+    'status',
+    NEW.to_status,
+    'accrual',
+    NEW.accrual,
+    'changed_at',
+    NEW.changed_at
+));
+IF NEW.to_status = 'PROCESSED' AND COALESCE(NEW.accrual, 0) > 0 THEN
+    IF o.household_id IS NULL THEN
+        PERFORM publish_balance_event(o.user_id);
+    ELSE
+        PERFORM publish_household_balance_event(o.household_id);
+    END IF;
+END IF;
+RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+-- списание из общего кошелька не меняет личный баланс
+CREATE OR REPLACE FUNCTION notify_withdrawal() RETURNS trigger AS $$
+BEGIN
+IF NEW.household_id IS NULL THEN
+    PERFORM publish_balance_event(NEW.user_id);
+ELSE
+    PERFORM publish_household_balance_event(NEW.household_id);
+END IF;
+RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
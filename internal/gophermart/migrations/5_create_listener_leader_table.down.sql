@@ -0,0 +1 @@
+DROP TABLE IF EXISTS listener_leader;
\ No newline at end of file
@@ -0,0 +1,6 @@
+CREATE TABLE IF NOT EXISTS listener_leader (
+    id INTEGER PRIMARY KEY,
+    instance_id VARCHAR(255) NOT NULL,
+    acquired_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    heartbeat_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_order_jobs_run_at;
+
+DROP TABLE IF EXISTS order_jobs;
\ No newline at end of file
@@ -0,0 +1,17 @@
+CREATE TABLE IF NOT EXISTS order_jobs (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL UNIQUE REFERENCES orders(uid) ON DELETE CASCADE,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    run_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    locked_until TIMESTAMP WITH TIME ZONE,
+    locked_by VARCHAR(255),
+    last_error TEXT,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_jobs_run_at ON order_jobs(run_at);
+
+-- заказы, загруженные до появления очереди
+INSERT INTO order_jobs (order_id)
+SELECT uid FROM orders WHERE status IN ('NEW', 'PROCESSING', 'REGISTERED')
+ON CONFLICT (order_id) DO NOTHING;
@@ -0,0 +1,7 @@
+CREATE TABLE IF NOT EXISTS order_dead_letters (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL UNIQUE REFERENCES orders(uid) ON DELETE CASCADE,
+    last_error TEXT NOT NULL,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    dead_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_orders_status_updated_at;
+
+ALTER TABLE orders DROP COLUMN IF EXISTS updated_at;
\ No newline at end of file
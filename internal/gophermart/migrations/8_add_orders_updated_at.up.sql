@@ -0,0 +1,5 @@
+ALTER TABLE orders ADD COLUMN IF NOT EXISTS updated_at TIMESTAMP WITH TIME ZONE DEFAULT CURRENT_TIMESTAMP;
+
+UPDATE orders SET updated_at = uploaded_at;
+
+CREATE INDEX IF NOT EXISTS idx_orders_status_updated_at ON orders(status, updated_at);
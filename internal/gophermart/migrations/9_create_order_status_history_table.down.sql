@@ -0,0 +1,3 @@
+DROP INDEX IF EXISTS idx_order_status_history_order_id;
+
+DROP TABLE IF EXISTS order_status_history;
\ No newline at end of file
@@ -0,0 +1,20 @@
+-- REGISTERED - статус системы начислений, в gophermart это PROCESSING
+UPDATE orders SET status = 'PROCESSING' WHERE status = 'REGISTERED';
+
+CREATE TABLE IF NOT EXISTS order_status_history (
+    id SERIAL PRIMARY KEY,
+    order_id INTEGER NOT NULL REFERENCES orders(uid) ON DELETE CASCADE,
+    from_status VARCHAR(50),
+    to_status VARCHAR(50) NOT NULL,
+    accrual NUMERIC(10,2) DEFAULT 0,
+    changed_at TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS idx_order_status_history_order_id ON order_status_history(order_id);
+
+-- история для уже загруженных заказов: загрузка и последний известный переход
+INSERT INTO order_status_history (order_id, from_status, to_status, accrual, changed_at)
+SELECT uid, NULL, 'NEW', 0, uploaded_at FROM orders;
+
+INSERT INTO order_status_history (order_id, from_status, to_status, accrual, changed_at)
+SELECT uid, 'NEW', status, accrual, updated_at FROM orders WHERE status <> 'NEW';
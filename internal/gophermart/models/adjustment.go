@@ -0,0 +1,59 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var ErrInvalidAdjustment = errors.New("invalid balance adjustment")
+
+// направления ручной корректировки
+const (
+	AdjustmentCredit = "credit"
+	AdjustmentDebit  = "debit"
+)
+
+// MaxAdjustmentReasonLength - ограничение длины причины корректировки
+const MaxAdjustmentReasonLength = 500
+
+// AdjustmentRequest - ручная корректировка баланса сотрудником поддержки
+type AdjustmentRequest struct {
+	Type   string  `json:"type"`
+	Amount float64 `json:"amount"`
+	Reason string  `json:"reason"`
+}
+
+// BalanceAdjustment - запись ленты корректировок. Amount со знаком: списание отрицательное.
+// AdminID и AdminLogin заполнены только для ручных корректировок
+type BalanceAdjustment struct {
+	ID         int64     `json:"id" db:"id"`
+	UserID     int       `json:"user_id" db:"user_id"`
+	Amount     float64   `json:"amount" db:"amount"`
+	Kind       string    `json:"kind" db:"kind"`
+	Reason     string    `json:"reason,omitempty" db:"reason"`
+	AdminID    *int      `json:"admin_id,omitempty" db:"created_by"`
+	AdminLogin string    `json:"admin_login,omitempty" db:"login"`
+	CreatedAt  time.Time `json:"created_at" db:"created_at"`
+}
+
+// ValidateAdjustment - проверка запроса и сумма со знаком
+func ValidateAdjustment(req AdjustmentRequest) (float64, error) {
+	if req.Amount <= 0 {
+		return 0, fmt.Errorf("%w: amount must be positive", ErrInvalidAdjustment)
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" || len(reason) > MaxAdjustmentReasonLength {
+		return 0, fmt.Errorf("%w: reason is required and must be at most %d characters", ErrInvalidAdjustment, MaxAdjustmentReasonLength)
+	}
+
+	switch req.Type {
+	case AdjustmentCredit:
+		return req.Amount, nil
+	case AdjustmentDebit:
+		return -req.Amount, nil
+	default:
+		return 0, fmt.Errorf("%w: type must be %s or %s", ErrInvalidAdjustment, AdjustmentCredit, AdjustmentDebit)
+	}
+}
@@ -0,0 +1,57 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+)
+
+var ErrAuditUnavailable = errors.New("audit log is unavailable")
+
+// действия, которые пишутся в журнал аудита
+const (
+	AuditUserRegistered     = "user.registered"
+	AuditUserLogin          = "user.login"
+	AuditUserLoginFailed    = "user.login_failed"
+	AuditOrderUploaded      = "order.uploaded"
+	AuditOrdersBatch        = "order.batch_uploaded"
+	AuditOrderStatusChanged = "order.status_changed"
+	AuditOrderDeadLettered  = "order.dead_lettered"
+	AuditOrderRequeued      = "order.requeued"
+	AuditWithdrawal         = "balance.withdrawn"
+	AuditBalanceAdjusted    = "balance.adjusted"
+	AuditPromoCreated       = "promo.created"
+	AuditPromoRedeemed      = "promo.redeemed"
+	AuditFraudFlagReviewed  = "fraud.flag_reviewed"
+	AuditHouseholdCreated   = "household.created"
+	AuditHouseholdJoined    = "household.member_joined"
+	AuditHouseholdUpdated   = "household.member_updated"
+	AuditHouseholdRemoved   = "household.member_removed"
+)
+
+// AuditEvent - запись журнала аудита. ActorID пустой для действий системы и неаутентифицированных запросов
+type AuditEvent struct {
+	ID        int64           `json:"id" db:"id"`
+	ActorID   *int            `json:"actor_id,omitempty" db:"actor_id"`
+	Action    string          `json:"action" db:"action"`
+	Target    string          `json:"target,omitempty" db:"target"`
+	IP        string          `json:"ip,omitempty" db:"ip"`
+	UserAgent string          `json:"user_agent,omitempty" db:"user_agent"`
+	Payload   json.RawMessage `json:"payload,omitempty" db:"payload"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+}
+
+// AuditFilter - выборка журнала аудита: пользователь как автор или цель, действие и параметры страницы
+type AuditFilter struct {
+	UserID int
+	Action string
+	ListParams
+}
+
+// цели записей аудита
+func UserTarget(userID int) string     { return "user:" + strconv.Itoa(userID) }
+func OrderTarget(number string) string { return "order:" + number }
+func LoginTarget(login string) string  { return "login:" + login }
+func PromoTarget(code string) string   { return "promo:" + code }
+func HouseholdTarget(id int) string    { return "household:" + strconv.Itoa(id) }
@@ -10,7 +10,31 @@ type Balance struct {
 }
 
 type WithdrawBalance struct {
+	UID         int       `json:"-" db:"uid"`
 	Order       string    `json:"order" db:"order_number"`
 	Sum         float64   `json:"sum" db:"sum"`
 	ProcessedAt time.Time `json:"processed_at" db:"processed_at"`
 }
+
+// виды корректировок баланса
+const (
+	AdjustmentReferral = "referral"
+	AdjustmentPromo    = "promo"
+	AdjustmentManual   = "manual"
+	// сгорание неизрасходованных баллов, сумма отрицательная
+	AdjustmentExpiry = "expiry"
+)
+
+// виды записей истории баланса помимо корректировок
+const (
+	BalanceEntryAccrual    = "accrual"
+	BalanceEntryWithdrawal = "withdrawal"
+)
+
+// BalanceEntry - движение по счёту: начисление за заказ, корректировка или списание (отрицательная сумма)
+type BalanceEntry struct {
+	Kind      string    `json:"kind" db:"kind"`
+	Amount    float64   `json:"amount" db:"amount"`
+	Reference string    `json:"reference,omitempty" db:"reference"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
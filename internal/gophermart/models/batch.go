@@ -0,0 +1,25 @@
+package models
+
+import "errors"
+
+var (
+	ErrEmptyBatch    = errors.New("batch is empty")
+	ErrBatchTooLarge = errors.New("batch is too large")
+)
+
+// MaxBatchSize - максимум номеров в одной пакетной загрузке
+const MaxBatchSize = 1000
+
+// результаты загрузки отдельного номера в пакете
+const (
+	BatchResultAccepted  = "accepted"
+	BatchResultDuplicate = "duplicate"
+	BatchResultConflict  = "conflict"
+	BatchResultInvalid   = "invalid"
+)
+
+// BatchOrderResult - результат загрузки одного номера из пакета
+type BatchOrderResult struct {
+	Number string `json:"number"`
+	Result string `json:"result"`
+}
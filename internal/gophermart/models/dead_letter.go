@@ -0,0 +1,16 @@
+package models
+
+import (
+	"time"
+)
+
+// DeadLetter - заказ, обработка которого окончательно провалилась
+type DeadLetter struct {
+	OrderID   int       `json:"-" db:"order_id"`
+	Number    string    `json:"number" db:"number"`
+	UserID    int       `json:"user_id" db:"user_id"`
+	Status    string    `json:"status" db:"status"`
+	LastError string    `json:"last_error" db:"last_error"`
+	Attempts  int       `json:"attempts" db:"attempts"`
+	DeadAt    time.Time `json:"dead_at" db:"dead_at"`
+}
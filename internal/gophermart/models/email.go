@@ -0,0 +1,47 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+)
+
+var (
+	ErrInvalidEmail        = errors.New("invalid email")
+	ErrNotifierUnavailable = errors.New("notifications are unavailable")
+)
+
+// MaxEmailLength - ограничение длины адреса по RFC 5321
+const MaxEmailLength = 254
+
+// EmailRequest - новая почта пользователя
+type EmailRequest struct {
+	Email string `json:"email"`
+}
+
+// VerifyEmailRequest - код подтверждения из письма
+type VerifyEmailRequest struct {
+	Token string `json:"token"`
+}
+
+// NormalizeEmail - адрес без пробелов по краям, домен в нижнем регистре
+func NormalizeEmail(email string) string {
+	email = strings.TrimSpace(email)
+	if at := strings.LastIndex(email, "@"); at >= 0 {
+		email = email[:at] + strings.ToLower(email[at:])
+	}
+	return email
+}
+
+// ValidateEmail - адрес без отображаемого имени, например user@example.com
+func ValidateEmail(email string) error {
+	if email == "" || len(email) > MaxEmailLength {
+		return fmt.Errorf("%w: must be 1 to %d characters", ErrInvalidEmail, MaxEmailLength)
+	}
+	addr, err := mail.ParseAddress(email)
+	if err != nil || addr.Address != email || addr.Name != "" {
+		return fmt.Errorf("%w: %q is not a plain address", ErrInvalidEmail, email)
+	}
+	return nil
+}
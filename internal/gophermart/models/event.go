@@ -0,0 +1,26 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+var ErrEventsUnavailable = errors.New("event stream is unavailable")
+
+// типы событий пользователя
+const (
+	UserEventOrder   = "order"
+	UserEventBalance = "balance"
+	// баланс общего кошелька семьи, приходит всем её участникам
+	UserEventHouseholdBalance = "household_balance"
+)
+
+// UserEvent - событие из журнала user_events, ID используется как Last-Event-ID
+type UserEvent struct {
+	ID        int64           `json:"id" db:"id"`
+	UserID    int             `json:"user_id" db:"user_id"`
+	Type      string          `json:"type" db:"type"`
+	Data      json.RawMessage `json:"data" db:"data"`
+	CreatedAt time.Time       `json:"created_at" db:"created_at"`
+}
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrActionBlocked       = errors.New("action blocked by fraud rules")
+	ErrActionDelayed       = errors.New("action delayed by fraud rules")
+	ErrInvalidFraudReview  = errors.New("invalid fraud flag review")
+	ErrInvalidFraudRuleSet = errors.New("invalid fraud rules")
+)
+
+// события, на которых проверяются правила
+const (
+	FraudEventOrderUpload = "order_upload"
+	FraudEventWithdrawal  = "withdrawal"
+)
+
+// реакции на сработавшее правило в порядке строгости
+const (
+	FraudActionAllow = "allow"
+	FraudActionFlag  = "flag"
+	FraudActionDelay = "delay"
+	FraudActionBlock = "block"
+)
+
+// статусы разбора флага
+const (
+	FraudFlagOpen      = "OPEN"
+	FraudFlagDismissed = "DISMISSED"
+	FraudFlagConfirmed = "CONFIRMED"
+)
+
+// FraudDelayError - действие отложено, повторить можно через RetryAfter
+type FraudDelayError struct {
+	RetryAfter time.Duration
+}
+
+func (e *FraudDelayError) Error() string {
+	return fmt.Sprintf("%s, retry after %s", ErrActionDelayed, e.RetryAfter)
+}
+
+func (e *FraudDelayError) Unwrap() error {
+	return ErrActionDelayed
+}
+
+// FraudActivity - активность пользователя за окно правила
+type FraudActivity struct {
+	// загружено заказов
+	Uploads int
+	// заказов в финальном статусе и из них INVALID
+	FinalOrders   int
+	InvalidOrders int
+	// списаний
+	Withdrawals int
+	// когда последний раз поступили баллы, nil - не поступали
+	LastAccrualAt *time.Time
+}
+
+// FraudFlag - срабатывание правила, ждёт разбора администратором.
+// Пока флаг открыт, повторные срабатывания того же правила учитываются в нём же
+type FraudFlag struct {
+	ID         int64           `json:"id" db:"id"`
+	UserID     int             `json:"user_id" db:"user_id"`
+	Rule       string          `json:"rule" db:"rule"`
+	Event      string          `json:"event" db:"event"`
+	Action     string          `json:"action" db:"action"`
+	Details    json.RawMessage `json:"details,omitempty" db:"details"`
+	Status     string          `json:"status" db:"status"`
+	ReviewedBy *int            `json:"reviewed_by,omitempty" db:"reviewed_by"`
+	ReviewNote string          `json:"review_note,omitempty" db:"review_note"`
+	ReviewedAt *time.Time      `json:"reviewed_at,omitempty" db:"reviewed_at"`
+	CreatedAt  time.Time       `json:"created_at" db:"created_at"`
+	// сколько раз правило сработало, пока флаг открыт, и когда последний раз
+	Hits      int       `json:"hits" db:"hits"`
+	LastHitAt time.Time `json:"last_hit_at" db:"last_hit_at"`
+}
+
+// FraudFlagFilter - выборка флагов: пользователь, статусы и параметры страницы
+type FraudFlagFilter struct {
+	UserID int
+	ListParams
+}
+
+// FraudReview - решение администратора по флагу
+type FraudReview struct {
+	Status string `json:"status"`
+	Note   string `json:"note"`
+}
+
+// ValidateFraudReview - флаг закрывается как ложное срабатывание или подтверждённое мошенничество
+func ValidateFraudReview(review FraudReview) error {
+	if review.Status != FraudFlagDismissed && review.Status != FraudFlagConfirmed {
+		return fmt.Errorf("%w: status must be %s or %s", ErrInvalidFraudReview, FraudFlagDismissed, FraudFlagConfirmed)
+	}
+	return nil
+}
@@ -0,0 +1,14 @@
+package models
+
+// Health - ответ эндпоинта проверки состояния
+type Health struct {
+	Status         string `json:"status"`
+	AccrualBreaker string `json:"accrual_breaker,omitempty"`
+	Instance       string `json:"instance,omitempty"`
+	Leader         string `json:"leader,omitempty"`
+}
+
+const (
+	HealthStatusOK       = "ok"
+	HealthStatusDegraded = "degraded"
+)
@@ -0,0 +1,127 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidHousehold = errors.New("invalid household")
+	ErrInvalidWallet    = errors.New("invalid wallet")
+)
+
+// кошельки, с которыми работают баланс и списания
+const (
+	WalletPersonal  = "personal"
+	WalletHousehold = "household"
+)
+
+// роли участников семьи
+const (
+	HouseholdRoleOwner  = "owner"
+	HouseholdRoleMember = "member"
+)
+
+// статусы приглашений в семью
+const (
+	InvitePending  = "PENDING"
+	InviteAccepted = "ACCEPTED"
+	InviteDeclined = "DECLINED"
+)
+
+// MaxHouseholdNameLength - ограничение длины названия семьи
+const MaxHouseholdNameLength = 100
+
+// Household - семья с общим кошельком
+type Household struct {
+	ID        int               `json:"id" db:"id"`
+	Name      string            `json:"name" db:"name"`
+	OwnerID   int               `json:"owner_id" db:"owner_id"`
+	CreatedAt time.Time         `json:"created_at" db:"created_at"`
+	Members   []HouseholdMember `json:"members,omitempty"`
+	Balance   *Balance          `json:"balance,omitempty"`
+}
+
+// HouseholdMember - участник семьи и его права на общий кошелёк.
+// WithdrawLimit - максимальная сумма одного списания, nil - без ограничения
+type HouseholdMember struct {
+	UserID        int       `json:"user_id" db:"user_id"`
+	Login         string    `json:"login" db:"login"`
+	Role          string    `json:"role" db:"role"`
+	CanWithdraw   bool      `json:"can_withdraw" db:"can_withdraw"`
+	WithdrawLimit *float64  `json:"withdraw_limit,omitempty" db:"withdraw_limit"`
+	ShareAccruals bool      `json:"share_accruals" db:"share_accruals"`
+	JoinedAt      time.Time `json:"joined_at" db:"joined_at"`
+}
+
+// HouseholdInvite - приглашение пользователя в семью
+type HouseholdInvite struct {
+	ID            int        `json:"id" db:"id"`
+	HouseholdID   int        `json:"household_id" db:"household_id"`
+	HouseholdName string     `json:"household_name" db:"name"`
+	UserID        int        `json:"user_id" db:"user_id"`
+	Login         string     `json:"login" db:"login"`
+	InvitedBy     int        `json:"invited_by" db:"invited_by"`
+	Status        string     `json:"status" db:"status"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	RespondedAt   *time.Time `json:"responded_at,omitempty" db:"responded_at"`
+}
+
+// HouseholdRequest - создание семьи
+type HouseholdRequest struct {
+	Name string `json:"name"`
+}
+
+// InviteRequest - приглашение по логину
+type InviteRequest struct {
+	Login string `json:"login"`
+}
+
+// MemberPermissions - права участника на общий кошелёк, задаёт владелец
+type MemberPermissions struct {
+	CanWithdraw   bool     `json:"can_withdraw"`
+	WithdrawLimit *float64 `json:"withdraw_limit,omitempty"`
+}
+
+// SharingRequest - зачислять ли начисления участника в общий кошелёк
+type SharingRequest struct {
+	ShareAccruals bool `json:"share_accruals"`
+}
+
+// HouseholdWithdrawal - списание из общего кошелька с автором
+type HouseholdWithdrawal struct {
+	WithdrawBalance
+	UserID int    `json:"user_id" db:"user_id"`
+	Login  string `json:"login" db:"login"`
+}
+
+// ValidateHouseholdName - проверка названия семьи
+func ValidateHouseholdName(name string) error {
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > MaxHouseholdNameLength {
+		return fmt.Errorf("%w: name is required and must be at most %d characters", ErrInvalidHousehold, MaxHouseholdNameLength)
+	}
+	return nil
+}
+
+// ValidateMemberPermissions - лимит списания положительный, если задан
+func ValidateMemberPermissions(p MemberPermissions) error {
+	if p.WithdrawLimit != nil && *p.WithdrawLimit <= 0 {
+		return fmt.Errorf("%w: withdraw limit must be positive", ErrInvalidHousehold)
+	}
+	return nil
+}
+
+// ParseWallet - кошелёк из параметра запроса, по умолчанию личный
+func ParseWallet(s string) (string, error) {
+	switch s {
+	case "", WalletPersonal:
+		return WalletPersonal, nil
+	case WalletHousehold:
+		return WalletHousehold, nil
+	default:
+		return "", fmt.Errorf("%w: must be %s or %s", ErrInvalidWallet, WalletPersonal, WalletHousehold)
+	}
+}
@@ -0,0 +1,63 @@
+package models
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidCursor     = errors.New("invalid cursor")
+	ErrInvalidListParams = errors.New("invalid list parameters")
+)
+
+const (
+	DefaultPageLimit = 100
+	MaxPageLimit     = 1000
+)
+
+// ListParams - постраничная выборка списков заказов и списаний
+type ListParams struct {
+	// размер страницы
+	Limit int
+	// позиция, с которой продолжать; пустая - с начала
+	Cursor string
+	// фильтр по статусам, только для заказов
+	Statuses []string
+	// полуинтервал дат [From, To)
+	From *time.Time
+	To   *time.Time
+	// по умолчанию сначала новые
+	Ascending bool
+}
+
+// EncodeCursor - непрозрачный курсор по ключу сортировки (время, id)
+func EncodeCursor(at time.Time, id int) string {
+	raw := at.UTC().Format(time.RFC3339Nano) + "|" + strconv.Itoa(id)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeCursor(cursor string) (time.Time, int, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+
+	at, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return time.Time{}, 0, ErrInvalidCursor
+	}
+
+	t, err := time.Parse(time.RFC3339Nano, at)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	n, err := strconv.Atoi(id)
+	if err != nil {
+		return time.Time{}, 0, fmt.Errorf("%w: %v", ErrInvalidCursor, err)
+	}
+	return t, n, nil
+}
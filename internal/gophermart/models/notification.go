@@ -0,0 +1,77 @@
+package models
+
+import (
+	"fmt"
+	"time"
+)
+
+// виды уведомлений во входящих пользователя
+const (
+	NotificationOrderProcessed     = "order.processed"
+	NotificationOrderInvalid       = "order.invalid"
+	NotificationWithdrawalComplete = "withdrawal.completed"
+	NotificationPointsExpired      = "points.expired"
+)
+
+// виды исходящих сообщений на почту пользователя
+const (
+	NotificationLargeWithdrawal   = "withdrawal.large"
+	NotificationPasswordReset     = "password.reset"
+	NotificationEmailVerification = "email.verification"
+)
+
+// NotificationKind - вид уведомления о смене статуса заказа, пустой если статус не финальный
+func NotificationKind(status string) string {
+	switch status {
+	case OrderStatusProcessed:
+		return NotificationOrderProcessed
+	case OrderStatusInvalid:
+		return NotificationOrderInvalid
+	default:
+		return ""
+	}
+}
+
+// NotificationData - подробности уведомления: номер заказа и сумма начисления или списания.
+// У сгорания баллов номера заказа нет
+type NotificationData struct {
+	Order  string  `json:"order,omitempty"`
+	Amount float64 `json:"amount"`
+}
+
+// Notification - уведомление во входящих пользователя. ReadAt пустой у непрочитанных
+type Notification struct {
+	ID        int64            `json:"id" db:"id"`
+	Kind      string           `json:"kind" db:"kind"`
+	Message   string           `json:"message"`
+	Data      NotificationData `json:"data" db:"payload"`
+	ReadAt    *time.Time       `json:"read_at,omitempty" db:"read_at"`
+	CreatedAt time.Time        `json:"created_at" db:"created_at"`
+}
+
+// Notifications - страница входящих и число непрочитанных
+type Notifications struct {
+	Unread        int            `json:"unread"`
+	Notifications []Notification `json:"notifications"`
+}
+
+// ReadNotificationsRequest - какие уведомления отметить прочитанными, пустой список - все
+type ReadNotificationsRequest struct {
+	IDs []int64 `json:"ids,omitempty"`
+}
+
+// NotificationMessage - текст уведомления для показа пользователю
+func NotificationMessage(kind string, data NotificationData) string {
+	switch kind {
+	case NotificationOrderProcessed:
+		return fmt.Sprintf("Order %s processed: %.2f points accrued", data.Order, data.Amount)
+	case NotificationOrderInvalid:
+		return fmt.Sprintf("Order %s was rejected by the accrual system", data.Order)
+	case NotificationWithdrawalComplete:
+		return fmt.Sprintf("%.2f points withdrawn for order %s", data.Amount, data.Order)
+	case NotificationPointsExpired:
+		return fmt.Sprintf("%.2f unused points expired", data.Amount)
+	default:
+		return kind
+	}
+}
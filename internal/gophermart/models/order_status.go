@@ -0,0 +1,68 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"go-musthave-diploma-tpl/internal/gophermart/accrualclient"
+)
+
+var (
+	ErrInvalidStatusTransition = errors.New("invalid order status transition")
+	ErrUnknownAccrualStatus    = errors.New("unknown accrual status")
+)
+
+// допустимые переходы статусов заказа, INVALID и PROCESSED - финальные
+var orderStatusTransitions = map[string][]string{
+	OrderStatusNew:        {OrderStatusProcessing, OrderStatusInvalid, OrderStatusProcessed},
+	OrderStatusProcessing: {OrderStatusInvalid, OrderStatusProcessed},
+	OrderStatusInvalid:    {},
+	OrderStatusProcessed:  {},
+}
+
+// OrderStatusFromAccrual - статус заказа по статусу расчёта в системе начислений.
+// REGISTERED означает, что заказ принят в расчёт, поэтому для пользователя он уже PROCESSING
+func OrderStatusFromAccrual(status string) (string, error) {
+	switch status {
+	case accrualclient.StatusRegistered, accrualclient.StatusProcessing:
+		return OrderStatusProcessing, nil
+	case accrualclient.StatusInvalid:
+		return OrderStatusInvalid, nil
+	case accrualclient.StatusProcessed:
+		return OrderStatusProcessed, nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnknownAccrualStatus, status)
+	}
+}
+
+// ValidateOrderStatusTransition - проверяет переход from -> to, повтор текущего статуса допустим
+func ValidateOrderStatusTransition(from, to string) error {
+	allowed, ok := orderStatusTransitions[from]
+	if !ok {
+		return fmt.Errorf("%w: unknown status %q", ErrInvalidStatusTransition, from)
+	}
+	if _, ok := orderStatusTransitions[to]; !ok {
+		return fmt.Errorf("%w: unknown status %q", ErrInvalidStatusTransition, to)
+	}
+	if from == to {
+		return nil
+	}
+
+	for _, status := range allowed {
+		if status == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s -> %s", ErrInvalidStatusTransition, from, to)
+}
+
+// IsKnownOrderStatus - статус из числа статусов заказа
+func IsKnownOrderStatus(status string) bool {
+	_, ok := orderStatusTransitions[status]
+	return ok
+}
+
+// IsFinalOrderStatus - после этого статуса заказ больше не меняется
+func IsFinalOrderStatus(status string) bool {
+	return status == OrderStatusInvalid || status == OrderStatusProcessed
+}
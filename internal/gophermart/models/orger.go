@@ -11,6 +11,22 @@ type Order struct {
 	Status     string    `json:"status" db:"status"`
 	Accrual    float64   `json:"accrual,omitempty" db:"accrual"`
 	UploadedAt time.Time `json:"uploaded_at" db:"uploaded_at"`
+	// время перехода в финальный статус
+	ProcessedAt *time.Time `json:"processed_at,omitempty" db:"processed_at"`
+}
+
+// OrderStatusChange - запись истории статусов заказа
+type OrderStatusChange struct {
+	From      string    `json:"from,omitempty" db:"from_status"`
+	Status    string    `json:"status" db:"to_status"`
+	Accrual   float64   `json:"accrual,omitempty" db:"accrual"`
+	ChangedAt time.Time `json:"changed_at" db:"changed_at"`
+}
+
+// OrderDetails - заказ с историей смены статусов
+type OrderDetails struct {
+	Order
+	History []OrderStatusChange `json:"history"`
 }
 
 // статусы заказов
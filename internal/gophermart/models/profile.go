@@ -0,0 +1,37 @@
+package models
+
+import (
+	"errors"
+	"time"
+)
+
+var ErrInvalidProfileMonths = errors.New("invalid number of months")
+
+// окно помесячной статистики профиля
+const (
+	DefaultProfileMonths = 12
+	MaxProfileMonths     = 120
+)
+
+// ProfileMonthFormat - формат месяца в помесячной статистике
+const ProfileMonthFormat = "2006-01"
+
+// Profile - профиль пользователя со статистикой за всё время.
+// Accrued и Withdrawn учитывают и общий кошелёк семьи
+type Profile struct {
+	ID           int              `json:"id"`
+	Login        string           `json:"login"`
+	RegisteredAt time.Time        `json:"registered_at"`
+	Orders       map[string]int   `json:"orders"`
+	Accrued      float64          `json:"accrued"`
+	Withdrawn    float64          `json:"withdrawn"`
+	Monthly      []MonthlyAccrual `json:"monthly"`
+}
+
+// MonthlyAccrual - начисления и списания пользователя за календарный месяц (UTC)
+type MonthlyAccrual struct {
+	Month     string  `json:"month"`
+	Orders    int     `json:"orders"`
+	Accrued   float64 `json:"accrued"`
+	Withdrawn float64 `json:"withdrawn"`
+}
@@ -0,0 +1,65 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+var (
+	ErrInvalidPromoCode      = errors.New("invalid promo code")
+	ErrTooManyRedeemAttempts = errors.New("too many promo code attempts")
+	// ErrPromoCodeNotFound - неизвестный код, только такие попытки учитываются в лимите перебора
+	ErrPromoCodeNotFound = errors.New("promo code not found")
+)
+
+// MaxPromoCodeLength - ограничение длины промокода
+const MaxPromoCodeLength = 64
+
+// PromoCode - промокод кампании на фиксированное число баллов
+type PromoCode struct {
+	ID    int     `json:"id" db:"id"`
+	Code  string  `json:"code" db:"code"`
+	Value float64 `json:"value" db:"value"`
+	// сколько раз код можно погасить всего, 0 - без ограничений
+	MaxRedemptions int `json:"max_redemptions" db:"max_redemptions"`
+	// сколько раз код может погасить один пользователь
+	PerUserLimit int        `json:"per_user_limit" db:"per_user_limit"`
+	Redeemed     int        `json:"redeemed" db:"redeemed"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty" db:"expires_at"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+}
+
+// PromoRedemption - результат погашения промокода
+type PromoRedemption struct {
+	Code       string    `json:"code"`
+	Amount     float64   `json:"amount"`
+	RedeemedAt time.Time `json:"redeemed_at"`
+}
+
+// NormalizePromoCode - коды регистронезависимы и хранятся в верхнем регистре
+func NormalizePromoCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
+
+// ValidatePromoCode - проверка параметров нового промокода
+func ValidatePromoCode(p PromoCode) error {
+	if p.Code == "" || len(p.Code) > MaxPromoCodeLength || strings.ContainsFunc(p.Code, unicode.IsSpace) {
+		return fmt.Errorf("%w: code must be non-empty and without spaces", ErrInvalidPromoCode)
+	}
+	if p.Value <= 0 {
+		return fmt.Errorf("%w: value must be positive", ErrInvalidPromoCode)
+	}
+	if p.MaxRedemptions < 0 {
+		return fmt.Errorf("%w: max_redemptions must not be negative", ErrInvalidPromoCode)
+	}
+	if p.PerUserLimit <= 0 {
+		return fmt.Errorf("%w: per_user_limit must be positive", ErrInvalidPromoCode)
+	}
+	if p.ExpiresAt != nil && !p.ExpiresAt.After(time.Now()) {
+		return fmt.Errorf("%w: expires_at must be in the future", ErrInvalidPromoCode)
+	}
+	return nil
+}
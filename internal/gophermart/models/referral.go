@@ -0,0 +1,26 @@
+package models
+
+import "time"
+
+// ReferralBonus - бонусы за первый обработанный заказ приглашённого
+type ReferralBonus struct {
+	// пригласившему
+	Referrer float64
+	// приглашённому
+	Referred float64
+}
+
+// Referral - приглашённый пользователь без логина
+type Referral struct {
+	JoinedAt   time.Time  `json:"joined_at" db:"created_at"`
+	RewardedAt *time.Time `json:"rewarded_at,omitempty" db:"rewarded_at"`
+}
+
+// ReferralStats - реферальный код пользователя и статистика приглашений
+type ReferralStats struct {
+	Code      string     `json:"code"`
+	Invited   int        `json:"invited"`
+	Rewarded  int        `json:"rewarded"`
+	Earned    float64    `json:"earned"`
+	Referrals []Referral `json:"referrals"`
+}
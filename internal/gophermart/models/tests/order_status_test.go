@@ -0,0 +1,70 @@
+package tests
+
+import (
+	"testing"
+
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrderStatusFromAccrual(t *testing.T) {
+	tests := []struct {
+		accrual  string
+		expected string
+	}{
+		{"REGISTERED", models.OrderStatusProcessing},
+		{"PROCESSING", models.OrderStatusProcessing},
+		{"INVALID", models.OrderStatusInvalid},
+		{"PROCESSED", models.OrderStatusProcessed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.accrual, func(t *testing.T) {
+			status, err := models.OrderStatusFromAccrual(tt.accrual)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, status)
+		})
+	}
+
+	_, err := models.OrderStatusFromAccrual("UNKNOWN")
+	assert.ErrorIs(t, err, models.ErrUnknownAccrualStatus)
+}
+
+func TestValidateOrderStatusTransition(t *testing.T) {
+	tests := []struct {
+		from    string
+		to      string
+		allowed bool
+	}{
+		{models.OrderStatusNew, models.OrderStatusProcessing, true},
+		{models.OrderStatusNew, models.OrderStatusProcessed, true},
+		{models.OrderStatusNew, models.OrderStatusInvalid, true},
+		{models.OrderStatusProcessing, models.OrderStatusProcessing, true},
+		{models.OrderStatusProcessing, models.OrderStatusProcessed, true},
+		{models.OrderStatusProcessing, models.OrderStatusNew, false},
+		{models.OrderStatusProcessed, models.OrderStatusProcessing, false},
+		{models.OrderStatusProcessed, models.OrderStatusInvalid, false},
+		{models.OrderStatusInvalid, models.OrderStatusProcessed, false},
+		{models.OrderStatusNew, "REGISTERED", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.from+"->"+tt.to, func(t *testing.T) {
+			err := models.ValidateOrderStatusTransition(tt.from, tt.to)
+			if tt.allowed {
+				assert.NoError(t, err)
+			} else {
+				assert.ErrorIs(t, err, models.ErrInvalidStatusTransition)
+			}
+		})
+	}
+}
+
+func TestIsFinalOrderStatus(t *testing.T) {
+	assert.False(t, models.IsFinalOrderStatus(models.OrderStatusNew))
+	assert.False(t, models.IsFinalOrderStatus(models.OrderStatusProcessing))
+	assert.True(t, models.IsFinalOrderStatus(models.OrderStatusInvalid))
+	assert.True(t, models.IsFinalOrderStatus(models.OrderStatusProcessed))
+}
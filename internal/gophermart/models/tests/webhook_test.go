@@ -0,0 +1,37 @@
+package tests
+
+import (
+	"testing"
+
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateWebhook_URL(t *testing.T) {
+	events := []string{models.WebhookEventOrderProcessed}
+
+	assert.NoError(t, models.ValidateWebhook("https://partner.example/hook", events))
+	assert.ErrorIs(t, models.ValidateWebhook("ftp://partner.example/hook", events), models.ErrInvalidWebhookURL)
+	assert.ErrorIs(t, models.ValidateWebhook("https:///hook", events), models.ErrInvalidWebhookURL)
+}
+
+func TestValidateWebhookTarget(t *testing.T) {
+	assert.NoError(t, models.ValidateWebhookTarget("https://partner.example/hook"))
+	assert.NoError(t, models.ValidateWebhookTarget("http://203.0.113.10:8080/hook"))
+
+	for _, url := range []string{
+		"http://localhost:8080/hook",
+		"http://127.0.0.1/hook",
+		"http://10.0.0.5/hook",
+		"http://192.168.1.1/hook",
+		"http://169.254.169.254/latest/meta-data/",
+		"http://100.64.0.1/hook",
+		"http://[::1]/hook",
+		"http://[fd00::1]/hook",
+		"http://[::ffff:127.0.0.1]/hook",
+		"http://0.0.0.0/hook",
+	} {
+		assert.ErrorIs(t, models.ValidateWebhookTarget(url), models.ErrInvalidWebhookURL, url)
+	}
+}
@@ -1,12 +1,18 @@
 package models
 
 import (
+	"errors"
 	"time"
 )
 
+// ErrInvalidLoginOrPassword - неверная пара логин/пароль, общая для сервиса и хендлеров
+var ErrInvalidLoginOrPassword = errors.New("invalid login or password")
+
 type RegisterRequest struct {
 	Login    string `json:"login"`
 	Password string `json:"password"`
+	// необязательный код пригласившего пользователя
+	ReferralCode string `json:"referral_code,omitempty"`
 }
 
 type User struct {
@@ -14,4 +20,12 @@ type User struct {
 	Login        string    `json:"login" db:"login"`
 	PasswordHash string    `json:"-" db:"password_hash"`
 	CreatedAt    time.Time `json:"-" db:"created_at"`
+	// необязательная почта, сообщения отправляются только на подтверждённую
+	Email           string     `json:"email,omitempty" db:"email"`
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty" db:"email_verified_at"`
+}
+
+// EmailVerified - почта указана и подтверждена
+func (u *User) EmailVerified() bool {
+	return u.Email != "" && u.EmailVerifiedAt != nil
 }
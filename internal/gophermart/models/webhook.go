@@ -0,0 +1,131 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"net/netip"
+	"net/url"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidWebhookURL   = errors.New("invalid webhook url")
+	ErrInvalidWebhookEvent = errors.New("invalid webhook event")
+)
+
+// события, на которые можно подписать вебхук
+const (
+	WebhookEventOrderProcessed    = "order.processed"
+	WebhookEventOrderInvalid      = "order.invalid"
+	WebhookEventWithdrawalCreated = "withdrawal.created"
+)
+
+// статусы доставки вебхука
+const (
+	WebhookDeliveryPending   = "PENDING"
+	WebhookDeliveryDelivered = "DELIVERED"
+	WebhookDeliveryFailed    = "FAILED"
+)
+
+var webhookEvents = map[string]bool{
+	WebhookEventOrderProcessed:    true,
+	WebhookEventOrderInvalid:      true,
+	WebhookEventWithdrawalCreated: true,
+}
+
+// Webhook - адрес, на который отправляются события пользователя.
+// Secret отдаётся только при создании, им подписывается тело запроса
+type Webhook struct {
+	ID        int       `json:"id" db:"id"`
+	UserID    int       `json:"-" db:"user_id"`
+	URL       string    `json:"url" db:"url"`
+	Secret    string    `json:"secret,omitempty" db:"secret"`
+	Events    []string  `json:"events" db:"events"`
+	Active    bool      `json:"active" db:"active"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// WebhookDelivery - попытки доставки одного события
+type WebhookDelivery struct {
+	ID             int64           `json:"id" db:"id"`
+	WebhookID      int             `json:"webhook_id" db:"webhook_id"`
+	Event          string          `json:"event" db:"event"`
+	Payload        json.RawMessage `json:"payload" db:"payload"`
+	Status         string          `json:"status" db:"status"`
+	Attempts       int             `json:"attempts" db:"attempts"`
+	LastStatusCode *int            `json:"last_status_code,omitempty" db:"last_status_code"`
+	LastError      *string         `json:"last_error,omitempty" db:"last_error"`
+	CreatedAt      time.Time       `json:"created_at" db:"created_at"`
+	DeliveredAt    *time.Time      `json:"delivered_at,omitempty" db:"delivered_at"`
+}
+
+// OrderWebhookEvent - событие вебхука для финального статуса заказа, пустая строка если события нет
+func OrderWebhookEvent(status string) string {
+	switch status {
+	case OrderStatusProcessed:
+		return WebhookEventOrderProcessed
+	case OrderStatusInvalid:
+		return WebhookEventOrderInvalid
+	default:
+		return ""
+	}
+}
+
+// nonPublicPrefixes - служебные диапазоны, не покрытые методами netip.Addr
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+	netip.MustParsePrefix("64:ff9b::/96"),
+}
+
+// IsPublicAddr - адрес в интернете, а не loopback, частная сеть, link-local
+// (включая метаданные облака 169.254.169.254) или другой служебный диапазон
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	if !addr.IsGlobalUnicast() || addr.IsPrivate() {
+		return false
+	}
+	for _, p := range nonPublicPrefixes {
+		if p.Contains(addr) {
+			return false
+		}
+	}
+	return true
+}
+
+// ValidateWebhook - http(s) адрес и хотя бы одно известное событие
+func ValidateWebhook(rawURL string, events []string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Hostname() == "" {
+		return ErrInvalidWebhookURL
+	}
+	if len(events) == 0 {
+		return ErrInvalidWebhookEvent
+	}
+	for _, event := range events {
+		if !webhookEvents[event] {
+			return ErrInvalidWebhookEvent
+		}
+	}
+	return nil
+}
+
+// ValidateWebhookTarget - адрес не указывает на localhost или IP вне интернета.
+// Имена хостов дополнительно проверяются при каждом соединении
+func ValidateWebhookTarget(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ErrInvalidWebhookURL
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return ErrInvalidWebhookURL
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !IsPublicAddr(addr) {
+		return ErrInvalidWebhookURL
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// FileNotifier - сообщения дописываются в файл построчно в JSON
+type FileNotifier struct {
+	mu   sync.Mutex
+	path string
+}
+
+func NewFile(path string) *FileNotifier {
+	return &FileNotifier{path: path}
+}
+
+type fileRecord struct {
+	Message
+	SentAt time.Time `json:"sent_at"`
+}
+
+func (n *FileNotifier) Send(_ context.Context, msg Message) error {
+	line, err := json.Marshal(fileRecord{Message: msg, SentAt: time.Now().UTC()})
+	if err != nil {
+		return err
+	}
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	f, err := os.OpenFile(n.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o600)
+	if err != nil {
+		return fmt.Errorf("open notification file: %w", err)
+	}
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		f.Close()
+		return fmt.Errorf("write notification: %w", err)
+	}
+	return f.Close()
+}
@@ -0,0 +1,21 @@
+package notify
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+)
+
+// LogNotifier - сообщения только пишутся в лог, для разработки и отладки
+type LogNotifier struct {
+	logger *zap.SugaredLogger
+}
+
+func NewLog(logger *zap.SugaredLogger) *LogNotifier {
+	return &LogNotifier{logger: logger}
+}
+
+func (n *LogNotifier) Send(_ context.Context, msg Message) error {
+	n.logger.Infow("notification", "to", msg.To, "subject", msg.Subject, "body", msg.Body)
+	return nil
+}
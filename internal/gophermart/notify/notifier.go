@@ -0,0 +1,55 @@
+package notify
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+)
+
+// Message - исходящее сообщение пользователю
+type Message struct {
+	To      string `json:"to"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+}
+
+// Notifier - канал доставки сообщений пользователям
+type Notifier interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// каналы доставки
+const (
+	KindLog  = "log"
+	KindFile = "file"
+	KindSMTP = "smtp"
+)
+
+// Config - выбор канала доставки и его настройки
+type Config struct {
+	Kind string
+	// файл для канала file
+	File string
+	SMTP SMTPConfig
+}
+
+// New - канал доставки по конфигурации, по умолчанию лог
+func New(cfg Config, logger *zap.SugaredLogger) (Notifier, error) {
+	switch cfg.Kind {
+	case "", KindLog:
+		return NewLog(logger), nil
+	case KindFile:
+		if cfg.File == "" {
+			return nil, fmt.Errorf("notifier %s: file is required", KindFile)
+		}
+		return NewFile(cfg.File), nil
+	case KindSMTP:
+		if cfg.SMTP.Addr == "" || cfg.SMTP.From == "" {
+			return nil, fmt.Errorf("notifier %s: address and sender are required", KindSMTP)
+		}
+		return NewSMTP(cfg.SMTP), nil
+	default:
+		return nil, fmt.Errorf("unknown notifier %q", cfg.Kind)
+	}
+}
@@ -0,0 +1,89 @@
+package notify
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+)
+
+var ErrUnknownTemplate = errors.New("unknown message template")
+
+// Data - подстановки в шаблоны сообщений, каждый шаблон использует свою часть полей
+type Data struct {
+	Login     string
+	Order     string
+	Amount    float64
+	Token     string
+	ExpiresAt time.Time
+}
+
+type messageTemplate struct {
+	subject *template.Template
+	body    *template.Template
+}
+
+func mustTemplate(kind, subject, body string) messageTemplate {
+	return messageTemplate{
+		subject: template.Must(template.New(kind + ".subject").Parse(subject)),
+		body:    template.Must(template.New(kind + ".body").Parse(body)),
+	}
+}
+
+var templates = map[string]messageTemplate{
+	models.NotificationOrderProcessed: mustTemplate(models.NotificationOrderProcessed,
+		`Order {{.Order}} processed`,
+		`Hello{{if .Login}}, {{.Login}}{{end}}!
+
+Order {{.Order}} has been processed: {{printf "%.2f" .Amount}} points were accrued.
+`),
+	models.NotificationOrderInvalid: mustTemplate(models.NotificationOrderInvalid,
+		`Order {{.Order}} rejected`,
+		`Hello{{if .Login}}, {{.Login}}{{end}}!
+
+Order {{.Order}} was rejected by the accrual system, no points were accrued.
+`),
+	models.NotificationLargeWithdrawal: mustTemplate(models.NotificationLargeWithdrawal,
+		`Large withdrawal: {{printf "%.2f" .Amount}} points`,
+		`Hello{{if .Login}}, {{.Login}}{{end}}!
+
+{{printf "%.2f" .Amount}} points were withdrawn for order {{.Order}}.
+If it wasn't you, change your password and contact support.
+`),
+	models.NotificationPasswordReset: mustTemplate(models.NotificationPasswordReset,
+		`Password reset`,
+		`Hello{{if .Login}}, {{.Login}}{{end}}!
+
+Use this code to reset your password: {{.Token}}
+The code expires at {{.ExpiresAt.UTC.Format "2006-01-02 15:04 MST"}}.
+If you didn't request a reset, ignore this message.
+`),
+	models.NotificationEmailVerification: mustTemplate(models.NotificationEmailVerification,
+		`Confirm your email`,
+		`Hello{{if .Login}}, {{.Login}}{{end}}!
+
+Use this code to confirm your email address: {{.Token}}
+The code expires at {{.ExpiresAt.UTC.Format "2006-01-02 15:04 MST"}}.
+`),
+}
+
+// Render - сообщение вида kind для адресата to
+func Render(kind, to string, data Data) (Message, error) {
+	tmpl, ok := templates[kind]
+	if !ok {
+		return Message{}, fmt.Errorf("%w: %s", ErrUnknownTemplate, kind)
+	}
+
+	var subject, body strings.Builder
+	if err := tmpl.subject.Execute(&subject, data); err != nil {
+		return Message{}, fmt.Errorf("render %s subject: %w", kind, err)
+	}
+	if err := tmpl.body.Execute(&body, data); err != nil {
+		return Message{}, fmt.Errorf("render %s body: %w", kind, err)
+	}
+
+	return Message{To: to, Subject: subject.String(), Body: body.String()}, nil
+}
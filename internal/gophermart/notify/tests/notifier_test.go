@@ -0,0 +1,231 @@
+package tests
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+	"go-musthave-diploma-tpl/internal/gophermart/notify"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+// smtpSession - то, что фейковый SMTP-сервер получил от клиента
+type smtpSession struct {
+	from string
+	rcpt []string
+	data string
+}
+
+// fakeSMTP - минимальный SMTP-сервер на одно соединение, без STARTTLS и авторизации
+func fakeSMTP(t *testing.T) (string, <-chan smtpSession) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { ln.Close() })
+
+	sessions := make(chan smtpSession, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		conn.SetDeadline(time.Now().Add(5 * time.Second))
+
+		r := bufio.NewReader(conn)
+		reply := func(s string) { conn.Write([]byte(s + "\r\n")) }
+
+		var s smtpSession
+		reply("220 localhost ESMTP")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			cmd := strings.TrimRight(line, "\r\n")
+			switch upper := strings.ToUpper(cmd); {
+			case strings.HasPrefix(upper, "EHLO"), strings.HasPrefix(upper, "HELO"):
+				reply("250 localhost")
+			case strings.HasPrefix(upper, "MAIL FROM:"):
+				s.from = strings.Trim(cmd[len("MAIL FROM:"):], "<>")
+				reply("250 OK")
+			case strings.HasPrefix(upper, "RCPT TO:"):
+				s.rcpt = append(s.rcpt, strings.Trim(cmd[len("RCPT TO:"):], "<>"))
+				reply("250 OK")
+			case upper == "DATA":
+				reply("354 end with <CRLF>.<CRLF>")
+				var data strings.Builder
+				for {
+					l, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if l == ".\r\n" {
+						break
+					}
+					data.WriteString(l)
+				}
+				s.data = data.String()
+				reply("250 OK")
+			case upper == "QUIT":
+				reply("221 bye")
+				sessions <- s
+				return
+			default:
+				reply("502 not implemented")
+			}
+		}
+	}()
+
+	return ln.Addr().String(), sessions
+}
+
+func TestSMTPNotifier_Send(t *testing.T) {
+	addr, sessions := fakeSMTP(t)
+
+	n := notify.NewSMTP(notify.SMTPConfig{Addr: addr, From: "noreply@gophermart.local", Timeout: 5 * time.Second})
+	msg, err := notify.Render(models.NotificationOrderProcessed, "alice@example.com", notify.Data{
+		Login:  "alice",
+		Order:  "12345678903",
+		Amount: 150,
+	})
+	require.NoError(t, err)
+	require.NoError(t, n.Send(context.Background(), msg))
+
+	select {
+	case s := <-sessions:
+		assert.Equal(t, "noreply@gophermart.local", s.from)
+		assert.Equal(t, []string{"alice@example.com"}, s.rcpt)
+		assert.Contains(t, s.data, "To: alice@example.com\r\n")
+		assert.Contains(t, s.data, "Subject: Order 12345678903 processed\r\n")
+		assert.Contains(t, s.data, "Content-Type: text/plain; charset=UTF-8\r\n")
+		assert.Contains(t, s.data, "Hello, alice!\r\n")
+		assert.Contains(t, s.data, "150.00 points were accrued")
+	case <-time.After(5 * time.Second):
+		t.Fatal("smtp server got no message")
+	}
+}
+
+func TestSMTPNotifier_InvalidMessage(t *testing.T) {
+	n := notify.NewSMTP(notify.SMTPConfig{Addr: "127.0.0.1:1", From: "noreply@gophermart.local"})
+
+	// письмо проверяется до соединения с сервером
+	err := n.Send(context.Background(), notify.Message{To: "not an address", Subject: "hi"})
+	assert.ErrorIs(t, err, notify.ErrInvalidMessage)
+
+	err = n.Send(context.Background(), notify.Message{To: "alice@example.com", Subject: "hi\r\nBcc: eve@example.com"})
+	assert.ErrorIs(t, err, notify.ErrInvalidMessage)
+}
+
+func TestFileNotifier_Send(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mail.jsonl")
+	n := notify.NewFile(path)
+
+	require.NoError(t, n.Send(context.Background(), notify.Message{To: "alice@example.com", Subject: "one", Body: "1"}))
+	require.NoError(t, n.Send(context.Background(), notify.Message{To: "bob@example.com", Subject: "two", Body: "2"}))
+
+	raw, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := strings.Split(strings.TrimSpace(string(raw)), "\n")
+	require.Len(t, lines, 2)
+
+	var rec struct {
+		notify.Message
+		SentAt time.Time `json:"sent_at"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(lines[1]), &rec))
+	assert.Equal(t, "bob@example.com", rec.To)
+	assert.Equal(t, "two", rec.Subject)
+	assert.False(t, rec.SentAt.IsZero())
+}
+
+func TestRender(t *testing.T) {
+	expires := time.Date(2026, 1, 2, 15, 4, 0, 0, time.UTC)
+	msg, err := notify.Render(models.NotificationEmailVerification, "alice@example.com", notify.Data{
+		Token:     "abc123",
+		ExpiresAt: expires,
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", msg.To)
+	assert.Equal(t, "Confirm your email", msg.Subject)
+	assert.Contains(t, msg.Body, "abc123")
+	assert.Contains(t, msg.Body, "2026-01-02 15:04 UTC")
+
+	_, err = notify.Render("unknown", "alice@example.com", notify.Data{})
+	assert.ErrorIs(t, err, notify.ErrUnknownTemplate)
+}
+
+func TestRender_Kinds(t *testing.T) {
+	data := notify.Data{
+		Login:     "alice",
+		Order:     "12345678903",
+		Amount:    1500,
+		Token:     "reset42",
+		ExpiresAt: time.Date(2026, 1, 2, 15, 4, 0, 0, time.UTC),
+	}
+
+	tests := []struct {
+		kind    string
+		subject string
+		body    []string
+	}{
+		{
+			kind:    models.NotificationOrderProcessed,
+			subject: "Order 12345678903 processed",
+			body:    []string{"Hello, alice!", "1500.00 points were accrued"},
+		},
+		{
+			kind:    models.NotificationOrderInvalid,
+			subject: "Order 12345678903 rejected",
+			body:    []string{"no points were accrued"},
+		},
+		{
+			kind:    models.NotificationLargeWithdrawal,
+			subject: "Large withdrawal: 1500.00 points",
+			body:    []string{"withdrawn for order 12345678903"},
+		},
+		{
+			kind:    models.NotificationPasswordReset,
+			subject: "Password reset",
+			body:    []string{"reset your password: reset42", "2026-01-02 15:04 UTC"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			msg, err := notify.Render(tt.kind, "alice@example.com", data)
+			require.NoError(t, err)
+			assert.Equal(t, tt.subject, msg.Subject)
+			for _, part := range tt.body {
+				assert.Contains(t, msg.Body, part)
+			}
+		})
+	}
+}
+
+func TestNew(t *testing.T) {
+	logger := zap.NewNop().Sugar()
+
+	n, err := notify.New(notify.Config{}, logger)
+	require.NoError(t, err)
+	assert.IsType(t, &notify.LogNotifier{}, n)
+	assert.NoError(t, n.Send(context.Background(), notify.Message{To: "alice@example.com"}))
+
+	_, err = notify.New(notify.Config{Kind: notify.KindFile}, logger)
+	assert.Error(t, err)
+	_, err = notify.New(notify.Config{Kind: notify.KindSMTP, SMTP: notify.SMTPConfig{Addr: "localhost:25"}}, logger)
+	assert.Error(t, err)
+	_, err = notify.New(notify.Config{Kind: "pigeon"}, logger)
+	assert.Error(t, err)
+}
@@ -0,0 +1,123 @@
+package ratelimit
+
+import (
+	"sync"
+	"time"
+)
+
+// Config - настройки ограничителя
+type Config struct {
+	// сколько событий допускается в окне
+	Limit int
+	// длина скользящего окна
+	Window time.Duration
+}
+
+// Limiter - ограничение числа событий по ключу в скользящем окне.
+// Allow только проверяет лимит, события учитываются через Add или Reserve.
+// Счётчики хранятся в памяти инстанса: при N репликах за окно допускается
+// до N*Limit событий по ключу
+type Limiter struct {
+	cfg Config
+
+	mu        sync.Mutex
+	hits      map[string][]time.Time
+	lastSweep time.Time
+}
+
+func New(cfg Config) *Limiter {
+	if cfg.Limit <= 0 {
+		cfg.Limit = 5
+	}
+	if cfg.Window <= 0 {
+		cfg.Window = 15 * time.Minute
+	}
+	return &Limiter{
+		cfg:       cfg,
+		hits:      make(map[string][]time.Time),
+		lastSweep: time.Now(),
+	}
+}
+
+// Allow - не исчерпан ли лимит по ключу
+func (l *Limiter) Allow(key string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return len(l.prune(key, time.Now())) < l.cfg.Limit
+}
+
+// Add - учитывает событие по ключу
+func (l *Limiter) Add(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	l.hits[key] = append(l.prune(key, now), now)
+
+	// ключи, к которым больше не обращаются, чистим не чаще раза в окно
+	if now.Sub(l.lastSweep) >= l.cfg.Window {
+		for k := range l.hits {
+			l.prune(k, now)
+		}
+		l.lastSweep = now
+	}
+}
+
+// Reserve - атомарно проверяет лимит и учитывает событие, чтобы параллельные
+// попытки не прошли проверку раньше, чем учтена хотя бы одна из них.
+// Вызов release отменяет событие, если попытка не должна учитываться
+func (l *Limiter) Reserve(key string) (release func(), ok bool) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := time.Now()
+	hits := l.prune(key, now)
+	if len(hits) >= l.cfg.Limit {
+		return func() {}, false
+	}
+	l.hits[key] = append(hits, now)
+
+	var once sync.Once
+	return func() {
+		once.Do(func() { l.remove(key, now) })
+	}, true
+}
+
+// remove - отменяет событие по ключу
+func (l *Limiter) remove(key string, at time.Time) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	hits := l.hits[key]
+	for i := len(hits) - 1; i >= 0; i-- {
+		if hits[i].Equal(at) {
+			hits = append(hits[:i], hits[i+1:]...)
+			break
+		}
+	}
+	if len(hits) == 0 {
+		delete(l.hits, key)
+		return
+	}
+	l.hits[key] = hits
+}
+
+// prune вызывается под мьютексом, убирает события старше окна
+func (l *Limiter) prune(key string, now time.Time) []time.Time {
+	hits := l.hits[key]
+	cutoff := now.Add(-l.cfg.Window)
+
+	i := 0
+	for i < len(hits) && !hits[i].After(cutoff) {
+		i++
+	}
+	hits = hits[i:]
+
+	if len(hits) == 0 {
+		delete(l.hits, key)
+		return nil
+	}
+	l.hits[key] = hits
+	return hits
+}
@@ -0,0 +1,52 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"go-musthave-diploma-tpl/internal/gophermart/ratelimit"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLimiter_BlocksAfterLimit(t *testing.T) {
+	l := ratelimit.New(ratelimit.Config{Limit: 3, Window: time.Hour})
+
+	for i := 0; i < 3; i++ {
+		assert.True(t, l.Allow("1"))
+		l.Add("1")
+	}
+	assert.False(t, l.Allow("1"))
+
+	// ключи независимы
+	assert.True(t, l.Allow("2"))
+}
+
+func TestLimiter_WindowExpires(t *testing.T) {
+	l := ratelimit.New(ratelimit.Config{Limit: 1, Window: 50 * time.Millisecond})
+
+	l.Add("1")
+	assert.False(t, l.Allow("1"))
+
+	time.Sleep(60 * time.Millisecond)
+	assert.True(t, l.Allow("1"))
+}
+
+func TestLimiter_Reserve(t *testing.T) {
+	l := ratelimit.New(ratelimit.Config{Limit: 2, Window: time.Hour})
+
+	release, ok := l.Reserve("1")
+	assert.True(t, ok)
+	_, ok = l.Reserve("1")
+	assert.True(t, ok)
+	_, ok = l.Reserve("1")
+	assert.False(t, ok)
+
+	// отменённое событие освобождает место, повторный release ничего не меняет
+	release()
+	release()
+	_, ok = l.Reserve("1")
+	assert.True(t, ok)
+	_, ok = l.Reserve("1")
+	assert.False(t, ok)
+}
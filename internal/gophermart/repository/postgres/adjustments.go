@@ -0,0 +1,102 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+)
+
+// CreateAdjustment - ручная корректировка баланса администратором.
+// Списание не может увести баланс в минус, строка пользователя блокируется на время проверки
+func (ps *PostgresStorage) CreateAdjustment(adminID, userID int, amount float64, reason string) (*models.BalanceAdjustment, error) {
+	tx, err := ps.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var id int
+	err = tx.QueryRow(`SELECT id FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return nil, handler.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to lock user: %w", err)
+	}
+
+	if amount < 0 {
+		// считаем баланс как в GetBalance
+		var balance float64
+		err = tx.QueryRow(`
+            SELECT
+                COALESCE((
+                    SELECT SUM(accrual)
+                    FROM orders
+                    WHERE user_id = $1 AND status = 'PROCESSED' AND household_id IS NULL
+                ), 0)
+                + COALESCE((
+                    SELECT SUM(amount)
+                    FROM balance_adjustments
+                    WHERE user_id = $1
+                ), 0)
+                - COALESCE((
+                    SELECT SUM(sum)
+                    FROM withdrawals
+                    WHERE user_id = $1 AND household_id IS NULL
+                ), 0) AS balance`, userID).Scan(&balance)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get balance: %w", err)
+		}
+		if balance+amount < 0 {
+			return nil, handler.ErrLackOfFunds
+		}
+	}
+
+	adj := &models.BalanceAdjustment{
+		UserID:  userID,
+		Amount:  amount,
+		Kind:    models.AdjustmentManual,
+		Reason:  reason,
+		AdminID: &adminID,
+	}
+	err = tx.QueryRow(`
+        INSERT INTO balance_adjustments (user_id, amount, kind, reason, created_by)
+        VALUES ($1, $2, $3, $4, $5)
+        RETURNING id, created_at`, userID, amount, models.AdjustmentManual, reason, adminID).
+		Scan(&adj.ID, &adj.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create adjustment: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return adj, nil
+}
+
+// Adjustments - корректировки баланса пользователя с автором, новые первыми
+func (ps *PostgresStorage) Adjustments(userID, limit int) ([]models.BalanceAdjustment, error) {
+	rows, err := ps.DB.Query(`
+        SELECT a.id, a.user_id, a.amount, a.kind, COALESCE(a.reason, ''), a.created_by, COALESCE(u.login, ''), a.created_at
+        FROM balance_adjustments a
+        LEFT JOIN users u ON u.id = a.created_by
+        WHERE a.user_id = $1
+        ORDER BY a.created_at DESC, a.id DESC
+        LIMIT $2`, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get adjustments: %w", err)
+	}
+	defer rows.Close()
+
+	adjustments := []models.BalanceAdjustment{}
+	for rows.Next() {
+		var a models.BalanceAdjustment
+		if err := rows.Scan(&a.ID, &a.UserID, &a.Amount, &a.Kind, &a.Reason, &a.AdminID, &a.AdminLogin, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		adjustments = append(adjustments, a)
+	}
+	return adjustments, rows.Err()
+}
@@ -0,0 +1,78 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+)
+
+// RecordAudit - добавляет запись в журнал аудита
+func (ps *PostgresStorage) RecordAudit(ctx context.Context, event models.AuditEvent) error {
+	var payload any
+	if len(event.Payload) > 0 {
+		payload = string(event.Payload)
+	}
+
+	_, err := ps.DB.ExecContext(ctx, `
+        INSERT INTO audit_events (actor_id, action, target, ip, user_agent, payload)
+        VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''), NULLIF($5, ''), $6::jsonb)`,
+		event.ActorID, event.Action, event.Target, event.IP, event.UserAgent, payload)
+	if err != nil {
+		return fmt.Errorf("failed to record audit event: %w", err)
+	}
+	return nil
+}
+
+// AuditEvents - страница журнала аудита с фильтрами и курсор следующей.
+// Фильтр по пользователю находит записи, где он автор или цель
+func (ps *PostgresStorage) AuditEvents(filter models.AuditFilter) ([]models.AuditEvent, string, error) {
+	query := `
+        SELECT id, actor_id, action, COALESCE(target, ''), COALESCE(ip, ''), COALESCE(user_agent, ''), payload, created_at
+        FROM audit_events
+        WHERE TRUE`
+	var args []any
+	if filter.UserID > 0 {
+		args = append(args, filter.UserID, models.UserTarget(filter.UserID))
+		query += ` AND (actor_id = $1 OR target = $2)`
+	}
+	if filter.Action != "" {
+		args = append(args, filter.Action)
+		query += fmt.Sprintf(` AND action = $%d`, len(args))
+	}
+
+	query, args, err := keysetQuery(query, args, filter.ListParams, "created_at", "id", "")
+	if err != nil {
+		return nil, "", err
+	}
+
+	rows, err := ps.DB.Query(query, args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get audit events: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.AuditEvent{}
+	for rows.Next() {
+		var e models.AuditEvent
+		var payload []byte
+		if err := rows.Scan(&e.ID, &e.ActorID, &e.Action, &e.Target, &e.IP, &e.UserAgent, &payload, &e.CreatedAt); err != nil {
+			return nil, "", err
+		}
+		e.Payload = payload
+		events = append(events, e)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	var nextCursor string
+	if len(events) > filter.Limit {
+		events = events[:filter.Limit]
+		last := events[len(events)-1]
+		nextCursor = models.EncodeCursor(last.CreatedAt, int(last.ID))
+	}
+
+	return events, nextCursor, nil
+}
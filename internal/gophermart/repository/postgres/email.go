@@ -0,0 +1,134 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+)
+
+// GetUserContact - логин и почта пользователя для исходящих сообщений
+func (ps *PostgresStorage) GetUserContact(userID int) (*models.User, error) {
+	user := models.User{ID: userID}
+	err := ps.DB.QueryRow(`
+        SELECT login, COALESCE(email, ''), email_verified_at
+        FROM users
+        WHERE id = $1`, userID).Scan(&user.Login, &user.Email, &user.EmailVerifiedAt)
+	if err == sql.ErrNoRows {
+		return nil, handler.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user contact: %w", err)
+	}
+	return &user, nil
+}
+
+// SetUserEmail - новая неподтверждённая почта и код её подтверждения.
+// Прежние неиспользованные коды пользователя удаляются
+func (ps *PostgresStorage) SetUserEmail(userID int, email, tokenHash string, expiresAt time.Time) error {
+	tx, err := ps.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+        UPDATE users SET email = $2, email_verified_at = NULL WHERE id = $1`, userID, email)
+	if err != nil {
+		return fmt.Errorf("failed to set email: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return handler.ErrUserNotFound
+	}
+
+	_, err = tx.Exec(`
+        DELETE FROM email_verifications WHERE user_id = $1 AND used_at IS NULL`, userID)
+	if err != nil {
+		return fmt.Errorf("failed to drop verification codes: %w", err)
+	}
+
+	_, err = tx.Exec(`
+        INSERT INTO email_verifications (user_id, email, token_hash, expires_at)
+        VALUES ($1, $2, $3, $4)`, userID, email, tokenHash, expiresAt)
+	if err != nil {
+		return fmt.Errorf("failed to create verification code: %w", err)
+	}
+
+	return tx.Commit()
+}
+
+// VerifyEmail - подтверждение почты кодом. Код одноразовый и действует,
+// только пока у пользователя та же почта, на которую он отправлен
+func (ps *PostgresStorage) VerifyEmail(userID int, tokenHash string) (*models.User, error) {
+	tx, err := ps.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var id int64
+	var email string
+	err = tx.QueryRow(`
+        SELECT id, email FROM email_verifications
+        WHERE token_hash = $1 AND user_id = $2 AND used_at IS NULL AND expires_at > NOW()
+        FOR UPDATE`, tokenHash, userID).Scan(&id, &email)
+	if err == sql.ErrNoRows {
+		return nil, handler.ErrEmailTokenInvalid
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get verification code: %w", err)
+	}
+
+	var taken bool
+	err = tx.QueryRow(`
+        SELECT EXISTS (
+            SELECT 1 FROM users
+            WHERE lower(email) = lower($1) AND email_verified_at IS NOT NULL AND id <> $2
+        )`, email, userID).Scan(&taken)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check email: %w", err)
+	}
+	if taken {
+		return nil, handler.ErrEmailTaken
+	}
+
+	user := models.User{ID: userID}
+	err = tx.QueryRow(`
+        UPDATE users SET email_verified_at = NOW()
+        WHERE id = $1 AND email = $2
+        RETURNING login, email, email_verified_at`, userID, email).Scan(&user.Login, &user.Email, &user.EmailVerifiedAt)
+	if err == sql.ErrNoRows {
+		return nil, handler.ErrEmailTokenInvalid
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify email: %w", err)
+	}
+
+	if _, err := tx.Exec(`UPDATE email_verifications SET used_at = NOW() WHERE id = $1`, id); err != nil {
+		return nil, fmt.Errorf("failed to use verification code: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// RemoveUserEmail - удаление почты, сообщения пользователю больше не отправляются
+func (ps *PostgresStorage) RemoveUserEmail(userID int) error {
+	tx, err := ps.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`UPDATE users SET email = NULL, email_verified_at = NULL WHERE id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to remove email: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM email_verifications WHERE user_id = $1 AND used_at IS NULL`, userID); err != nil {
+		return fmt.Errorf("failed to drop verification codes: %w", err)
+	}
+	return tx.Commit()
+}
@@ -0,0 +1,130 @@
+package postgres
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+)
+
+// expiredPointsQuery - баллы пользователя $1, начисленные до $2 и ещё не потраченные.
+// Списания и отрицательные корректировки, включая прошлые сгорания, гасят сначала самые старые начисления.
+// Общий кошелёк семьи не сгорает
+const expiredPointsQuery = `
+    SELECT
+        COALESCE((
+            SELECT SUM(accrual)
+            FROM orders
+            WHERE user_id = $1 AND status = 'PROCESSED' AND household_id IS NULL AND processed_at < $2
+        ), 0)
+        + COALESCE((
+            SELECT SUM(amount)
+            FROM balance_adjustments
+            WHERE user_id = $1 AND amount > 0 AND created_at < $2
+        ), 0)
+        + COALESCE((
+            SELECT SUM(amount)
+            FROM balance_adjustments
+            WHERE user_id = $1 AND amount < 0
+        ), 0)
+        - COALESCE((
+            SELECT SUM(sum)
+            FROM withdrawals
+            WHERE user_id = $1 AND household_id IS NULL
+        ), 0) AS expired`
+
+// ExpirePoints - сжигает баллы, начисленные раньше before и не потраченные до сих пор.
+// Сгорание пишется корректировкой с уведомлением во входящие, возвращает число пользователей со сгоревшими баллами
+func (ps *PostgresStorage) ExpirePoints(ctx context.Context, before time.Time) (int, error) {
+	rows, err := ps.DB.QueryContext(ctx, `
+        SELECT user_id
+        FROM (
+            SELECT user_id, accrual AS amount
+            FROM orders
+            WHERE status = 'PROCESSED' AND household_id IS NULL AND processed_at < $1
+            UNION ALL
+            SELECT user_id, amount
+            FROM balance_adjustments
+            WHERE amount > 0 AND created_at < $1
+            UNION ALL
+            SELECT user_id, amount
+            FROM balance_adjustments
+            WHERE amount < 0
+            UNION ALL
+            SELECT user_id, -sum
+            FROM withdrawals
+            WHERE household_id IS NULL
+        ) AS movements
+        GROUP BY user_id
+        HAVING SUM(amount) > 0
+        ORDER BY user_id`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to find expired points: %w", err)
+	}
+	defer rows.Close()
+
+	var userIDs []int
+	for rows.Next() {
+		var userID int
+		if err := rows.Scan(&userID); err != nil {
+			return 0, err
+		}
+		userIDs = append(userIDs, userID)
+	}
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	expired := 0
+	for _, userID := range userIDs {
+		amount, err := ps.expireUserPoints(ctx, userID, before)
+		if err != nil {
+			return expired, err
+		}
+		if amount > 0 {
+			expired++
+		}
+	}
+	return expired, nil
+}
+
+// expireUserPoints - сгорание баллов одного пользователя.
+// Строка пользователя блокируется, как в Withdraw, и сумма пересчитывается под блокировкой
+func (ps *PostgresStorage) expireUserPoints(ctx context.Context, userID int, before time.Time) (float64, error) {
+	tx, err := ps.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	var lockedID int
+	if err := tx.QueryRowContext(ctx, `SELECT id FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&lockedID); err != nil {
+		return 0, fmt.Errorf("failed to lock user: %w", err)
+	}
+
+	var amount float64
+	if err := tx.QueryRowContext(ctx, expiredPointsQuery, userID, before).Scan(&amount); err != nil {
+		return 0, fmt.Errorf("failed to get expired points: %w", err)
+	}
+	if amount <= 0 {
+		// баллы потрачены, пока шёл поиск
+		return 0, nil
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO balance_adjustments (user_id, amount, kind)
+        VALUES ($1, $2, $3)`, userID, -amount, models.AdjustmentExpiry)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create expiry adjustment: %w", err)
+	}
+
+	if err := notifyPointsExpired(ctx, tx, userID, amount); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return amount, nil
+}
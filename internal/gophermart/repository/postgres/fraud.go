@@ -0,0 +1,176 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+)
+
+// FraudActivity - активность пользователя начиная с since для правил антифрода
+func (ps *PostgresStorage) FraudActivity(ctx context.Context, userID int, since time.Time) (models.FraudActivity, error) {
+	var a models.FraudActivity
+	err := ps.DB.QueryRowContext(ctx, `
+        SELECT
+            o.uploads,
+            o.final,
+            o.invalid,
+            (SELECT COUNT(*) FROM withdrawals WHERE user_id = $1 AND processed_at >= $2),
+            GREATEST(
+                (SELECT MAX(processed_at) FROM orders
+                 WHERE user_id = $1 AND status = 'PROCESSED' AND accrual > 0 AND processed_at >= $2),
+                (SELECT MAX(created_at) FROM balance_adjustments
+                 WHERE user_id = $1 AND amount > 0 AND created_at >= $2)
+            )
+        FROM (
+            SELECT
+                COUNT(*) AS uploads,
+                COUNT(*) FILTER (WHERE status IN ('PROCESSED', 'INVALID')) AS final,
+                COUNT(*) FILTER (WHERE status = 'INVALID') AS invalid
+            FROM orders
+            WHERE user_id = $1 AND uploaded_at >= $2
+        ) o`, userID, since).
+		Scan(&a.Uploads, &a.FinalOrders, &a.InvalidOrders, &a.Withdrawals, &a.LastAccrualAt)
+	if err != nil {
+		return a, fmt.Errorf("failed to get fraud activity: %w", err)
+	}
+	return a, nil
+}
+
+// FraudEventTime - время события с номером offset от самого старого в окне.
+// Событие order_upload - загрузка заказа, withdrawal - списание
+func (ps *PostgresStorage) FraudEventTime(ctx context.Context, userID int, event string, since time.Time, offset int) (*time.Time, error) {
+	var query string
+	switch event {
+	case models.FraudEventOrderUpload:
+		query = `
+        SELECT uploaded_at FROM orders
+        WHERE user_id = $1 AND uploaded_at >= $2
+        ORDER BY uploaded_at OFFSET $3 LIMIT 1`
+	case models.FraudEventWithdrawal:
+		query = `
+        SELECT processed_at FROM withdrawals
+        WHERE user_id = $1 AND processed_at >= $2
+        ORDER BY processed_at OFFSET $3 LIMIT 1`
+	default:
+		return nil, fmt.Errorf("unknown fraud event %q", event)
+	}
+
+	var at time.Time
+	err := ps.DB.QueryRowContext(ctx, query, userID, since, offset).Scan(&at)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get fraud event time: %w", err)
+	}
+	return &at, nil
+}
+
+// CreateFraudFlags - сохраняет срабатывания правил одной транзакцией,
+// открытый флаг по тому же правилу обновляется вместо создания нового
+func (ps *PostgresStorage) CreateFraudFlags(ctx context.Context, flags []models.FraudFlag) error {
+	tx, err := ps.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, f := range flags {
+		_, err := tx.ExecContext(ctx, `
+            INSERT INTO fraud_flags (user_id, rule, event, action, details)
+            VALUES ($1, $2, $3, $4, $5::jsonb)
+            ON CONFLICT (user_id, rule) WHERE status = 'OPEN'
+            DO UPDATE SET hits = fraud_flags.hits + 1, last_hit_at = NOW(),
+                action = EXCLUDED.action, details = EXCLUDED.details`, f.UserID, f.Rule, f.Event, f.Action, string(f.Details))
+		if err != nil {
+			return fmt.Errorf("failed to create fraud flag: %w", err)
+		}
+	}
+	return tx.Commit()
+}
+
+// FraudFlags - страница флагов антифрода и курсор следующей
+func (ps *PostgresStorage) FraudFlags(filter models.FraudFlagFilter) ([]models.FraudFlag, string, error) {
+	query := `
+        SELECT id, user_id, rule, event, action, details, status, reviewed_by, COALESCE(review_note, ''), reviewed_at, created_at, hits, last_hit_at
+        FROM fraud_flags
+        WHERE TRUE`
+	var args []any
+	if filter.UserID > 0 {
+		args = append(args, filter.UserID)
+		query += ` AND user_id = $1`
+	}
+
+	query, args, err := keysetQuery(query, args, filter.ListParams, "created_at", "id", "status")
+	if err != nil {
+		return nil, "", err
+	}
+
+	rows, err := ps.DB.Query(query, args...)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get fraud flags: %w", err)
+	}
+	defer rows.Close()
+
+	flags := []models.FraudFlag{}
+	for rows.Next() {
+		f, err := scanFraudFlag(rows)
+		if err != nil {
+			return nil, "", err
+		}
+		flags = append(flags, f)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	var nextCursor string
+	if len(flags) > filter.Limit {
+		flags = flags[:filter.Limit]
+		last := flags[len(flags)-1]
+		nextCursor = models.EncodeCursor(last.CreatedAt, int(last.ID))
+	}
+
+	return flags, nextCursor, nil
+}
+
+// ReviewFraudFlag - решение администратора по флагу.
+// Решение принимается один раз, уже закрытый флаг не пересматривается
+func (ps *PostgresStorage) ReviewFraudFlag(flagID int64, adminID int, review models.FraudReview) (*models.FraudFlag, error) {
+	row := ps.DB.QueryRow(`
+        UPDATE fraud_flags
+        SET status = $2, review_note = NULLIF($3, ''), reviewed_by = $4, reviewed_at = NOW()
+        WHERE id = $1 AND status = 'OPEN'
+        RETURNING id, user_id, rule, event, action, details, status, reviewed_by, COALESCE(review_note, ''), reviewed_at, created_at, hits, last_hit_at`,
+		flagID, review.Status, review.Note, adminID)
+
+	f, err := scanFraudFlag(row)
+	if err == sql.ErrNoRows {
+		var exists bool
+		if err := ps.DB.QueryRow(`SELECT EXISTS (SELECT 1 FROM fraud_flags WHERE id = $1)`, flagID).Scan(&exists); err != nil {
+			return nil, fmt.Errorf("failed to get fraud flag: %w", err)
+		}
+		if exists {
+			return nil, handler.ErrFraudFlagAlreadyReviewed
+		}
+		return nil, handler.ErrFraudFlagNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to review fraud flag: %w", err)
+	}
+	return &f, nil
+}
+
+func scanFraudFlag(row interface{ Scan(dest ...any) error }) (models.FraudFlag, error) {
+	var f models.FraudFlag
+	var details []byte
+	err := row.Scan(&f.ID, &f.UserID, &f.Rule, &f.Event, &f.Action, &details, &f.Status,
+		&f.ReviewedBy, &f.ReviewNote, &f.ReviewedAt, &f.CreatedAt, &f.Hits, &f.LastHitAt)
+	f.Details = details
+	return f, err
+}
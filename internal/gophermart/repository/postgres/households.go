@@ -0,0 +1,429 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+)
+
+// queryRower - *sql.DB или *sql.Tx для запросов одной строки
+type queryRower interface {
+	QueryRow(query string, args ...any) *sql.Row
+}
+
+// householdOf - семья пользователя и его роль в ней
+func householdOf(q queryRower, userID int) (int, string, error) {
+	var householdID int
+	var role string
+	err := q.QueryRow(`
+        SELECT household_id, role FROM household_members WHERE user_id = $1`, userID).Scan(&householdID, &role)
+	if err == sql.ErrNoRows {
+		return 0, "", handler.ErrHouseholdNotFound
+	}
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to get household: %w", err)
+	}
+	return householdID, role, nil
+}
+
+// householdBalance - начисления участников, зачисленные в общий кошелёк, за вычетом списаний из него
+func householdBalance(q queryRower, householdID int) (models.Balance, error) {
+	var balance models.Balance
+	err := q.QueryRow(`
+        SELECT
+            COALESCE((
+                SELECT SUM(accrual)
+                FROM orders
+                WHERE household_id = $1 AND status = 'PROCESSED'
+            ), 0)
+            -
+            COALESCE((
+                SELECT SUM(sum)
+                FROM withdrawals
+                WHERE household_id = $1
+            ), 0) AS current,
+            COALESCE((
+                SELECT SUM(sum)
+                FROM withdrawals
+                WHERE household_id = $1
+            ), 0) AS withdrawn`, householdID).Scan(&balance.Current, &balance.Withdrawn)
+	if err != nil {
+		return models.Balance{}, fmt.Errorf("failed to get household balance: %w", err)
+	}
+	return balance, nil
+}
+
+// CreateHousehold - создание семьи, создатель становится владельцем с правом списания
+func (ps *PostgresStorage) CreateHousehold(ownerID int, name string) (*models.Household, error) {
+	tx, err := ps.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	household := &models.Household{Name: name, OwnerID: ownerID}
+	err = tx.QueryRow(`
+        INSERT INTO households (name, owner_id)
+        VALUES ($1, $2)
+        RETURNING id, created_at`, name, ownerID).Scan(&household.ID, &household.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create household: %w", err)
+	}
+
+	// пользователь состоит не более чем в одной семье, конфликт по user_id - уже участник
+	res, err := tx.Exec(`
+        INSERT INTO household_members (household_id, user_id, role, can_withdraw)
+        VALUES ($1, $2, $3, TRUE)
+        ON CONFLICT (user_id) DO NOTHING`, household.ID, ownerID, models.HouseholdRoleOwner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add household owner: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return nil, handler.ErrAlreadyInHousehold
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return household, nil
+}
+
+// Household - семья пользователя с участниками и балансом общего кошелька
+func (ps *PostgresStorage) Household(userID int) (*models.Household, error) {
+	householdID, _, err := householdOf(ps.DB, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	household := &models.Household{ID: householdID}
+	err = ps.DB.QueryRow(`
+        SELECT name, owner_id, created_at FROM households WHERE id = $1`, householdID).
+		Scan(&household.Name, &household.OwnerID, &household.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get household: %w", err)
+	}
+
+	rows, err := ps.DB.Query(`
+        SELECT m.user_id, u.login, m.role, m.can_withdraw, m.withdraw_limit, m.share_accruals, m.joined_at
+        FROM household_members m
+        JOIN users u ON u.id = m.user_id
+        WHERE m.household_id = $1
+        ORDER BY m.joined_at, m.user_id`, householdID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get household members: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var m models.HouseholdMember
+		if err := rows.Scan(&m.UserID, &m.Login, &m.Role, &m.CanWithdraw, &m.WithdrawLimit, &m.ShareAccruals, &m.JoinedAt); err != nil {
+			return nil, err
+		}
+		household.Members = append(household.Members, m)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	balance, err := householdBalance(ps.DB, householdID)
+	if err != nil {
+		return nil, err
+	}
+	household.Balance = &balance
+
+	return household, nil
+}
+
+// HouseholdBalance - баланс общего кошелька семьи пользователя
+func (ps *PostgresStorage) HouseholdBalance(userID int) (models.Balance, error) {
+	householdID, _, err := householdOf(ps.DB, userID)
+	if err != nil {
+		return models.Balance{}, err
+	}
+	return householdBalance(ps.DB, householdID)
+}
+
+// InviteToHousehold - приглашение пользователя по логину, приглашать может только владелец
+func (ps *PostgresStorage) InviteToHousehold(ownerID int, login string) (*models.HouseholdInvite, error) {
+	householdID, role, err := householdOf(ps.DB, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if role != models.HouseholdRoleOwner {
+		return nil, handler.ErrForbidden
+	}
+
+	invite := &models.HouseholdInvite{
+		HouseholdID: householdID,
+		Login:       login,
+		InvitedBy:   ownerID,
+		Status:      models.InvitePending,
+	}
+	var member bool
+	err = ps.DB.QueryRow(`
+        SELECT u.id, h.name, EXISTS (SELECT 1 FROM household_members WHERE user_id = u.id)
+        FROM users u, households h
+        WHERE u.login = $1 AND h.id = $2`, login, householdID).Scan(&invite.UserID, &invite.HouseholdName, &member)
+	if err == sql.ErrNoRows {
+		return nil, handler.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get invited user: %w", err)
+	}
+	if member {
+		return nil, handler.ErrAlreadyInHousehold
+	}
+
+	err = ps.DB.QueryRow(`
+        INSERT INTO household_invites (household_id, user_id, invited_by)
+        VALUES ($1, $2, $3)
+        ON CONFLICT (household_id, user_id) WHERE status = 'PENDING' DO NOTHING
+        RETURNING id, created_at`, householdID, invite.UserID, ownerID).Scan(&invite.ID, &invite.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, handler.ErrInviteExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create household invite: %w", err)
+	}
+
+	return invite, nil
+}
+
+// HouseholdInvites - ожидающие ответа приглашения пользователя
+func (ps *PostgresStorage) HouseholdInvites(userID int) ([]models.HouseholdInvite, error) {
+	rows, err := ps.DB.Query(`
+        SELECT i.id, i.household_id, h.name, i.user_id, u.login, i.invited_by, i.status, i.created_at, i.responded_at
+        FROM household_invites i
+        JOIN households h ON h.id = i.household_id
+        JOIN users u ON u.id = i.user_id
+        WHERE i.user_id = $1 AND i.status = $2
+        ORDER BY i.created_at DESC`, userID, models.InvitePending)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get household invites: %w", err)
+	}
+	defer rows.Close()
+
+	invites := []models.HouseholdInvite{}
+	for rows.Next() {
+		var i models.HouseholdInvite
+		if err := rows.Scan(&i.ID, &i.HouseholdID, &i.HouseholdName, &i.UserID, &i.Login, &i.InvitedBy, &i.Status, &i.CreatedAt, &i.RespondedAt); err != nil {
+			return nil, err
+		}
+		invites = append(invites, i)
+	}
+	return invites, rows.Err()
+}
+
+// RespondHouseholdInvite - принятие или отклонение приглашения адресатом.
+// Принять можно, только если пользователь ещё не состоит в семье
+func (ps *PostgresStorage) RespondHouseholdInvite(userID, inviteID int, accept bool) (*models.HouseholdInvite, error) {
+	tx, err := ps.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var invite models.HouseholdInvite
+	err = tx.QueryRow(`
+        SELECT i.id, i.household_id, h.name, i.user_id, i.invited_by, i.created_at
+        FROM household_invites i
+        JOIN households h ON h.id = i.household_id
+        WHERE i.id = $1 AND i.user_id = $2 AND i.status = $3
+        FOR UPDATE OF i`, inviteID, userID, models.InvitePending).
+		Scan(&invite.ID, &invite.HouseholdID, &invite.HouseholdName, &invite.UserID, &invite.InvitedBy, &invite.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, handler.ErrInviteNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get household invite: %w", err)
+	}
+
+	invite.Status = models.InviteDeclined
+	if accept {
+		invite.Status = models.InviteAccepted
+		res, err := tx.Exec(`
+            INSERT INTO household_members (household_id, user_id, role)
+            VALUES ($1, $2, $3)
+            ON CONFLICT (user_id) DO NOTHING`, invite.HouseholdID, userID, models.HouseholdRoleMember)
+		if err != nil {
+			return nil, fmt.Errorf("failed to add household member: %w", err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			return nil, handler.ErrAlreadyInHousehold
+		}
+	}
+
+	err = tx.QueryRow(`
+        UPDATE household_invites SET status = $1, responded_at = NOW()
+        WHERE id = $2
+        RETURNING responded_at`, invite.Status, inviteID).Scan(&invite.RespondedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to update household invite: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &invite, nil
+}
+
+// UpdateHouseholdMember - права участника на списание из общего кошелька, меняет только владелец
+func (ps *PostgresStorage) UpdateHouseholdMember(ownerID, memberID int, perms models.MemberPermissions) (*models.HouseholdMember, error) {
+	householdID, role, err := householdOf(ps.DB, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	if role != models.HouseholdRoleOwner {
+		return nil, handler.ErrForbidden
+	}
+
+	var m models.HouseholdMember
+	err = ps.DB.QueryRow(`
+        UPDATE household_members m
+        SET can_withdraw = $3, withdraw_limit = $4
+        FROM users u
+        WHERE m.household_id = $1 AND m.user_id = $2 AND u.id = m.user_id
+        RETURNING m.user_id, u.login, m.role, m.can_withdraw, m.withdraw_limit, m.share_accruals, m.joined_at`,
+		householdID, memberID, perms.CanWithdraw, perms.WithdrawLimit).
+		Scan(&m.UserID, &m.Login, &m.Role, &m.CanWithdraw, &m.WithdrawLimit, &m.ShareAccruals, &m.JoinedAt)
+	if err == sql.ErrNoRows {
+		return nil, handler.ErrHouseholdMemberNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to update household member: %w", err)
+	}
+	return &m, nil
+}
+
+// RemoveHouseholdMember - исключение участника владельцем или выход из семьи.
+// Зачисленные в общий кошелёк баллы остаются в нём
+func (ps *PostgresStorage) RemoveHouseholdMember(actorID, memberID int) error {
+	householdID, role, err := householdOf(ps.DB, actorID)
+	if err != nil {
+		return err
+	}
+	if actorID == memberID && role == models.HouseholdRoleOwner {
+		return handler.ErrOwnerCannotLeave
+	}
+	if actorID != memberID && role != models.HouseholdRoleOwner {
+		return handler.ErrForbidden
+	}
+
+	res, err := ps.DB.Exec(`
+        DELETE FROM household_members
+        WHERE household_id = $1 AND user_id = $2 AND role <> $3`, householdID, memberID, models.HouseholdRoleOwner)
+	if err != nil {
+		return fmt.Errorf("failed to remove household member: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return handler.ErrHouseholdMemberNotFound
+	}
+	return nil
+}
+
+// SetAccrualSharing - зачислять ли будущие начисления участника в общий кошелёк
+func (ps *PostgresStorage) SetAccrualSharing(userID int, share bool) error {
+	res, err := ps.DB.Exec(`
+        UPDATE household_members SET share_accruals = $2 WHERE user_id = $1`, userID, share)
+	if err != nil {
+		return fmt.Errorf("failed to update accrual sharing: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return handler.ErrHouseholdNotFound
+	}
+	return nil
+}
+
+// WithdrawFromHousehold - списание из общего кошелька. Владелец списывает без ограничений,
+// участник - при наличии права и в пределах своего лимита. Строка семьи блокируется на время проверки баланса
+func (ps *PostgresStorage) WithdrawFromHousehold(userID int, withdraw models.WithdrawBalance) error {
+	tx, err := ps.DB.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var (
+		householdID int
+		role        string
+		canWithdraw bool
+		limit       *float64
+	)
+	err = tx.QueryRow(`
+        SELECT m.household_id, m.role, m.can_withdraw, m.withdraw_limit
+        FROM household_members m
+        JOIN households h ON h.id = m.household_id
+        WHERE m.user_id = $1
+        FOR UPDATE OF h`, userID).Scan(&householdID, &role, &canWithdraw, &limit)
+	if err == sql.ErrNoRows {
+		return handler.ErrHouseholdNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock household: %w", err)
+	}
+
+	if role != models.HouseholdRoleOwner {
+		if !canWithdraw || (limit != nil && withdraw.Sum > *limit) {
+			return handler.ErrWithdrawNotPermitted
+		}
+	}
+
+	balance, err := householdBalance(tx, householdID)
+	if err != nil {
+		return err
+	}
+	if balance.Current-withdraw.Sum < 0 {
+		return handler.ErrLackOfFunds
+	}
+
+	_, err = tx.Exec(`
+        INSERT INTO withdrawals (user_id, order_number, sum, household_id)
+        VALUES ($1, $2, $3, $4)
+    `, userID, withdraw.Order, withdraw.Sum, householdID)
+	if err != nil {
+		return err
+	}
+
+	if err := enqueueWithdrawalWebhooks(context.Background(), tx, userID, withdraw); err != nil {
+		return err
+	}
+
+	if err := notifyWithdrawal(context.Background(), tx, userID, withdraw); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// HouseholdWithdrawals - списания из общего кошелька семьи пользователя с авторами
+func (ps *PostgresStorage) HouseholdWithdrawals(userID, limit int) ([]models.HouseholdWithdrawal, error) {
+	householdID, _, err := householdOf(ps.DB, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := ps.DB.Query(`
+        SELECT w.uid, w.order_number, w.sum, w.processed_at, w.user_id, u.login
+        FROM withdrawals w
+        JOIN users u ON u.id = w.user_id
+        WHERE w.household_id = $1
+        ORDER BY w.processed_at DESC, w.uid DESC
+        LIMIT $2`, householdID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get household withdrawals: %w", err)
+	}
+	defer rows.Close()
+
+	withdrawals := []models.HouseholdWithdrawal{}
+	for rows.Next() {
+		var w models.HouseholdWithdrawal
+		if err := rows.Scan(&w.UID, &w.Order, &w.Sum, &w.ProcessedAt, &w.UserID, &w.Login); err != nil {
+			return nil, err
+		}
+		withdrawals = append(withdrawals, w)
+	}
+	return withdrawals, rows.Err()
+}
@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+)
+
+// keysetQuery - дописывает к запросу фильтры, условие курсора, сортировку и лимит.
+// Ключ сортировки - (timeCol, idCol), лимит берётся на одну строку больше, чтобы понять, есть ли следующая страница
+func keysetQuery(query string, args []any, params models.ListParams, timeCol, idCol, statusCol string) (string, []any, error) {
+	var sb strings.Builder
+	sb.WriteString(query)
+
+	next := func(v any) string {
+		args = append(args, v)
+		return "$" + strconv.Itoa(len(args))
+	}
+
+	if statusCol != "" && len(params.Statuses) > 0 {
+		placeholders := make([]string, 0, len(params.Statuses))
+		for _, status := range params.Statuses {
+			placeholders = append(placeholders, next(status))
+		}
+		fmt.Fprintf(&sb, " AND %s IN (%s)", statusCol, strings.Join(placeholders, ", "))
+	}
+	if params.From != nil {
+		fmt.Fprintf(&sb, " AND %s >= %s", timeCol, next(*params.From))
+	}
+	if params.To != nil {
+		fmt.Fprintf(&sb, " AND %s < %s", timeCol, next(*params.To))
+	}
+
+	direction, cmp := "DESC", "<"
+	if params.Ascending {
+		direction, cmp = "ASC", ">"
+	}
+
+	if params.Cursor != "" {
+		at, id, err := models.DecodeCursor(params.Cursor)
+		if err != nil {
+			return "", nil, err
+		}
+		fmt.Fprintf(&sb, " AND (%s, %s) %s (%s, %s)", timeCol, idCol, cmp, next(at), next(id))
+	}
+
+	fmt.Fprintf(&sb, " ORDER BY %s %s, %s %s LIMIT %s", timeCol, direction, idCol, direction, next(params.Limit+1))
+	return sb.String(), args, nil
+}
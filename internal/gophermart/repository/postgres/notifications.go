@@ -0,0 +1,120 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+)
+
+// notifyOrderStatus - уведомление владельцу заказа о финальном статусе.
+// Пишется в транзакции смены статуса, как и доставки вебхуков
+func notifyOrderStatus(ctx context.Context, tx execer, orderID int, status string, accrual float64) error {
+	kind := models.NotificationKind(status)
+	if kind == "" {
+		return nil
+	}
+	_, err := tx.ExecContext(ctx, `
+        INSERT INTO notifications (user_id, kind, payload)
+        SELECT user_id, $2, jsonb_build_object('order', number, 'amount', $3::numeric)
+        FROM orders
+        WHERE uid = $1`, orderID, kind, accrual)
+	if err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+	return nil
+}
+
+// notifyWithdrawal - уведомление о проведённом списании
+func notifyWithdrawal(ctx context.Context, tx execer, userID int, withdraw models.WithdrawBalance) error {
+	payload, err := json.Marshal(models.NotificationData{Order: withdraw.Order, Amount: withdraw.Sum})
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO notifications (user_id, kind, payload)
+        VALUES ($1, $2, $3)`, userID, models.NotificationWithdrawalComplete, payload)
+	if err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+	return nil
+}
+
+// notifyPointsExpired - уведомление о сгоревших баллах
+func notifyPointsExpired(ctx context.Context, tx execer, userID int, amount float64) error {
+	payload, err := json.Marshal(models.NotificationData{Amount: amount})
+	if err != nil {
+		return err
+	}
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO notifications (user_id, kind, payload)
+        VALUES ($1, $2, $3)`, userID, models.NotificationPointsExpired, payload)
+	if err != nil {
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+	return nil
+}
+
+// Notifications - последние уведомления пользователя и число непрочитанных
+func (ps *PostgresStorage) Notifications(userID int, unreadOnly bool, limit int) (*models.Notifications, error) {
+	result := &models.Notifications{Notifications: []models.Notification{}}
+	err := ps.DB.QueryRow(`
+        SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`, userID).Scan(&result.Unread)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count notifications: %w", err)
+	}
+
+	rows, err := ps.DB.Query(`
+        SELECT id, kind, payload, read_at, created_at
+        FROM notifications
+        WHERE user_id = $1 AND (NOT $2 OR read_at IS NULL)
+        ORDER BY created_at DESC, id DESC
+        LIMIT $3`, userID, unreadOnly, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get notifications: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var n models.Notification
+		var payload []byte
+		if err := rows.Scan(&n.ID, &n.Kind, &payload, &n.ReadAt, &n.CreatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal(payload, &n.Data); err != nil {
+			return nil, fmt.Errorf("failed to decode notification %d: %w", n.ID, err)
+		}
+		n.Message = models.NotificationMessage(n.Kind, n.Data)
+		result.Notifications = append(result.Notifications, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// MarkNotificationsRead - отмечает уведомления прочитанными, пустой ids - все непрочитанные.
+// Возвращает число непрочитанных после отметки
+func (ps *PostgresStorage) MarkNotificationsRead(userID int, ids []int64) (int, error) {
+	query := `UPDATE notifications SET read_at = NOW() WHERE user_id = $1 AND read_at IS NULL`
+	args := []any{userID}
+	if len(ids) > 0 {
+		// список передаётся одним параметром-массивом, число плейсхолдеров не зависит от запроса
+		query += " AND id = ANY($2)"
+		args = append(args, ids)
+	}
+
+	if _, err := ps.DB.Exec(query, args...); err != nil {
+		return 0, fmt.Errorf("failed to mark notifications read: %w", err)
+	}
+
+	var unread int
+	err := ps.DB.QueryRow(`
+        SELECT COUNT(*) FROM notifications WHERE user_id = $1 AND read_at IS NULL`, userID).Scan(&unread)
+	if err != nil {
+		return 0, fmt.Errorf("failed to count notifications: %w", err)
+	}
+	return unread, nil
+}
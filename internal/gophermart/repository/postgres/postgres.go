@@ -1,15 +1,18 @@
 package postgres
 
 import (
+	"context"
 	"crypto/sha256"
 	"database/sql"
 	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	db "go-musthave-diploma-tpl/internal/gophermart/config/db"
 	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
 	"go-musthave-diploma-tpl/internal/gophermart/models"
 	logger "go-musthave-diploma-tpl/pkg/runtime/logger"
+	"time"
 )
 
 var castomLogger = logger.NewHTTPLogger().Sugar()
@@ -17,6 +20,7 @@ var castomLogger = logger.NewHTTPLogger().Sugar()
 type PostgresStorage struct {
 	DB              *sql.DB
 	errorClassifier *PostgresErrorClassifier
+	referralBonus   models.ReferralBonus
 }
 
 func New() *PostgresStorage {
@@ -138,6 +142,14 @@ func (ps *PostgresStorage) CreateOrder(userID int, orderNumber string) error {
             ON CONFLICT (number) DO NOTHING
             RETURNING uid
         ),
+        job AS (
+            INSERT INTO order_jobs (order_id)
+            SELECT uid FROM inserted
+        ),
+        history AS (
+            INSERT INTO order_status_history (order_id, to_status)
+            SELECT uid, $3 FROM inserted
+        ),
         existing AS (
             SELECT user_id FROM orders WHERE number = $2
         )
@@ -156,7 +168,7 @@ func (ps *PostgresStorage) CreateOrder(userID int, orderNumber string) error {
 
 	switch result {
 	case "inserted":
-		// триггер сам отправит notify
+		// задача в order_jobs создана тем же запросом, триггер отправит notify как подсказку воркерам
 		return nil
 	case "duplicate":
 		return handler.ErrDuplicateOrder
@@ -167,6 +179,159 @@ func (ps *PostgresStorage) CreateOrder(userID int, orderNumber string) error {
 	}
 }
 
+// CreateOrders - пакетная загрузка заказов одним запросом.
+// Возвращает результат по каждому номеру: accepted, duplicate или conflict
+func (ps *PostgresStorage) CreateOrders(userID int, numbers []string) (map[string]string, error) {
+	// номера передаются JSON-массивом: схема проверки может допускать в них любые символы
+	query := `
+        WITH input AS (
+            SELECT DISTINCT number FROM jsonb_array_elements_text($2::jsonb) AS number
+        ),
+        inserted AS (
+            INSERT INTO orders (user_id, number, status)
+            SELECT $1, number, $3 FROM input
+            ON CONFLICT (number) DO NOTHING
+            RETURNING uid, number
+        ),
+        job AS (
+            INSERT INTO order_jobs (order_id)
+            SELECT uid FROM inserted
+        ),
+        history AS (
+            INSERT INTO order_status_history (order_id, to_status)
+            SELECT uid, $3 FROM inserted
+        )
+        SELECT i.number,
+            CASE
+                WHEN ins.uid IS NOT NULL THEN 'accepted'::text
+                WHEN o.user_id = $1 THEN 'duplicate'::text
+                ELSE 'conflict'::text
+            END AS result
+        FROM input i
+        LEFT JOIN inserted ins ON ins.number = i.number
+        LEFT JOIN orders o ON o.number = i.number`
+
+	input, err := json.Marshal(numbers)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := ps.DB.Query(query, userID, string(input), models.OrderStatusNew)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create orders: %w", err)
+	}
+	defer rows.Close()
+
+	results := make(map[string]string, len(numbers))
+	for rows.Next() {
+		var number, result string
+		if err := rows.Scan(&number, &result); err != nil {
+			return nil, err
+		}
+		results[number] = result
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return results, nil
+}
+
+// UpdateOrderStatus - переводит заказ в новый статус.
+// Недопустимые переходы отклоняются с models.ErrInvalidStatusTransition,
+// каждый переход записывается в order_status_history
+func (ps *PostgresStorage) UpdateOrderStatus(ctx context.Context, orderID int, status string, accrual float64) error {
+	tx, err := ps.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var current string
+	err = tx.QueryRowContext(ctx, `SELECT status FROM orders WHERE uid = $1 FOR UPDATE`, orderID).Scan(&current)
+	if err == sql.ErrNoRows {
+		return fmt.Errorf("order not found uid=%d", orderID)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to lock order: %w", err)
+	}
+
+	if err := models.ValidateOrderStatusTransition(current, status); err != nil {
+		return err
+	}
+	if current == status {
+		// статус не изменился - фиксировать нечего
+		return nil
+	}
+
+	// uploaded_at не трогаем - это время загрузки, для финального статуса есть processed_at.
+	// Начисление участника, делящегося баллами с семьёй, зачисляется в общий кошелёк
+	_, err = tx.ExecContext(ctx, `
+        UPDATE orders
+        SET status = $1,
+            accrual = $2,
+            updated_at = NOW(),
+            processed_at = CASE WHEN $1 IN ('PROCESSED', 'INVALID') THEN NOW() ELSE processed_at END,
+            household_id = CASE WHEN $1 = 'PROCESSED' THEN (
+                SELECT household_id FROM household_members
+                WHERE user_id = orders.user_id AND share_accruals
+            ) ELSE household_id END
+        WHERE uid = $3`, status, accrual, orderID)
+	if err != nil {
+		return fmt.Errorf("db update failed: %w", err)
+	}
+
+	_, err = tx.ExecContext(ctx, `
+        INSERT INTO order_status_history (order_id, from_status, to_status, accrual)
+        VALUES ($1, $2, $3, $4)`, orderID, current, status, accrual)
+	if err != nil {
+		return fmt.Errorf("failed to record status history: %w", err)
+	}
+
+	if status == models.OrderStatusProcessed {
+		if err := ps.rewardReferral(ctx, tx, orderID); err != nil {
+			return err
+		}
+	}
+
+	if event := models.OrderWebhookEvent(status); event != "" {
+		if err := enqueueOrderWebhooks(ctx, tx, orderID, event); err != nil {
+			return err
+		}
+	}
+
+	if err := notifyOrderStatus(ctx, tx, orderID, status, accrual); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// UpdateOrderStatusByNumber - смена статуса по номеру заказа.
+// Для финального статуса задача опроса accrual больше не нужна и удаляется
+func (ps *PostgresStorage) UpdateOrderStatusByNumber(ctx context.Context, number, status string, accrual float64) error {
+	var orderID int
+	err := ps.DB.QueryRowContext(ctx, `SELECT uid FROM orders WHERE number = $1`, number).Scan(&orderID)
+	if err == sql.ErrNoRows {
+		return handler.ErrOrderNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get order: %w", err)
+	}
+
+	if err := ps.UpdateOrderStatus(ctx, orderID, status, accrual); err != nil {
+		return err
+	}
+
+	if models.IsFinalOrderStatus(status) {
+		if _, err := ps.DB.ExecContext(ctx, `DELETE FROM order_jobs WHERE order_id = $1`, orderID); err != nil {
+			return fmt.Errorf("failed to complete order job: %w", err)
+		}
+	}
+	return nil
+}
+
 func (ps *PostgresStorage) GetOrders(userID int) ([]models.Order, error) {
 	rows, err := ps.DB.Query(`
         SELECT number, status, accrual, uploaded_at 
@@ -195,6 +360,93 @@ func (ps *PostgresStorage) GetOrders(userID int) ([]models.Order, error) {
 	return orders, nil
 }
 
+// GetOrdersPage - страница заказов пользователя и курсор следующей страницы
+func (ps *PostgresStorage) GetOrdersPage(userID int, params models.ListParams) ([]models.Order, string, error) {
+	query, args, err := keysetQuery(`
+        SELECT uid, number, status, accrual, uploaded_at
+        FROM orders
+        WHERE user_id = $1`, []any{userID}, params, "uploaded_at", "uid", "status")
+	if err != nil {
+		return nil, "", err
+	}
+
+	rows, err := ps.DB.Query(query, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	orders := []models.Order{}
+	for rows.Next() {
+		var order models.Order
+		if err := rows.Scan(&order.UID, &order.Number, &order.Status, &order.Accrual, &order.UploadedAt); err != nil {
+			return nil, "", err
+		}
+		orders = append(orders, order)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	var nextCursor string
+	if len(orders) > params.Limit {
+		orders = orders[:params.Limit]
+		last := orders[len(orders)-1]
+		nextCursor = models.EncodeCursor(last.UploadedAt, last.UID)
+	}
+
+	return orders, nextCursor, nil
+}
+
+// GetOrder - заказ пользователя с историей статусов, чужие заказы не отдаются
+func (ps *PostgresStorage) GetOrder(userID int, number string) (*models.OrderDetails, error) {
+	var details models.OrderDetails
+	err := ps.DB.QueryRow(`
+        SELECT uid, user_id, number, status, accrual, uploaded_at, processed_at
+        FROM orders
+        WHERE user_id = $1 AND number = $2`, userID, number).Scan(
+		&details.UID,
+		&details.UserID,
+		&details.Number,
+		&details.Status,
+		&details.Accrual,
+		&details.UploadedAt,
+		&details.ProcessedAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, handler.ErrOrderNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order: %w", err)
+	}
+
+	rows, err := ps.DB.Query(`
+        SELECT COALESCE(from_status, ''), to_status, accrual, changed_at
+        FROM order_status_history
+        WHERE order_id = $1
+        ORDER BY changed_at ASC, id ASC`, details.UID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get order history: %w", err)
+	}
+	defer rows.Close()
+
+	details.History = []models.OrderStatusChange{}
+	for rows.Next() {
+		var change models.OrderStatusChange
+		if err := rows.Scan(&change.From, &change.Status, &change.Accrual, &change.ChangedAt); err != nil {
+			return nil, err
+		}
+		details.History = append(details.History, change)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return &details, nil
+}
+
 func (ps *PostgresStorage) GetBalance(userID int) (models.Balance, error) {
 	var balance models.Balance
 
@@ -203,18 +455,24 @@ func (ps *PostgresStorage) GetBalance(userID int) (models.Balance, error) {
             COALESCE((
                 SELECT SUM(accrual)
                 FROM orders
-                WHERE user_id = $1 AND status = 'PROCESSED'
+                WHERE user_id = $1 AND status = 'PROCESSED' AND household_id IS NULL
+            ), 0)
+            +
+            COALESCE((
+                SELECT SUM(amount)
+                FROM balance_adjustments
+                WHERE user_id = $1
             ), 0)
             -
             COALESCE((
                 SELECT SUM(sum)
                 FROM withdrawals
-                WHERE user_id = $1
+                WHERE user_id = $1 AND household_id IS NULL
             ), 0) AS current,
             COALESCE((
                 SELECT SUM(sum)
                 FROM withdrawals
-                WHERE user_id = $1
+                WHERE user_id = $1 AND household_id IS NULL
             ), 0) AS withdrawn
     `
 
@@ -236,6 +494,13 @@ func (ps *PostgresStorage) Withdraw(userID int, withdraw models.WithdrawBalance)
 	}
 	defer tx.Rollback()
 
+	// строка пользователя блокируется до конца транзакции, как в CreateAdjustment,
+	// иначе параллельные списания и корректировки проходят проверку баланса одновременно
+	var lockedID int
+	if err := tx.QueryRow(`SELECT id FROM users WHERE id = $1 FOR UPDATE`, userID).Scan(&lockedID); err != nil {
+		return err
+	}
+
 	// считаем баланс как в GetBalance
 	var balance float64
 	err = tx.QueryRow(`
@@ -243,12 +508,17 @@ func (ps *PostgresStorage) Withdraw(userID int, withdraw models.WithdrawBalance)
             COALESCE((
                 SELECT SUM(accrual) 
                 FROM orders 
-                WHERE user_id = $1 AND status = 'PROCESSED'
+                WHERE user_id = $1 AND status = 'PROCESSED' AND household_id IS NULL
             ), 0) 
+            + COALESCE((
+                SELECT SUM(amount)
+                FROM balance_adjustments
+                WHERE user_id = $1
+            ), 0)
             - COALESCE((
                 SELECT SUM(sum) 
                 FROM withdrawals 
-                WHERE user_id = $1
+                WHERE user_id = $1 AND household_id IS NULL
             ), 0) AS balance
     `, userID).Scan(&balance)
 	if err != nil {
@@ -268,6 +538,14 @@ func (ps *PostgresStorage) Withdraw(userID int, withdraw models.WithdrawBalance)
 		return err
 	}
 
+	if err := enqueueWithdrawalWebhooks(context.Background(), tx, userID, withdraw); err != nil {
+		return err
+	}
+
+	if err := notifyWithdrawal(context.Background(), tx, userID, withdraw); err != nil {
+		return err
+	}
+
 	return tx.Commit()
 }
 
@@ -277,7 +555,7 @@ func (ps *PostgresStorage) Withdrawals(userID int) ([]models.WithdrawBalance, er
 									sum,
 									processed_at
 								FROM withdrawals
-								WHERE user_id = $1
+								WHERE user_id = $1 AND household_id IS NULL
 								ORDER BY processed_at DESC
 							`, userID)
 	if err != nil {
@@ -300,3 +578,174 @@ func (ps *PostgresStorage) Withdrawals(userID int) ([]models.WithdrawBalance, er
 
 	return withdrawals, nil
 }
+
+// WithdrawalsPage - страница списаний пользователя и курсор следующей страницы
+func (ps *PostgresStorage) WithdrawalsPage(userID int, params models.ListParams) ([]models.WithdrawBalance, string, error) {
+	query, args, err := keysetQuery(`
+        SELECT uid, order_number, sum, processed_at
+        FROM withdrawals
+        WHERE user_id = $1 AND household_id IS NULL`, []any{userID}, params, "processed_at", "uid", "")
+	if err != nil {
+		return nil, "", err
+	}
+
+	rows, err := ps.DB.Query(query, args...)
+	if err != nil {
+		return nil, "", err
+	}
+	defer rows.Close()
+
+	withdrawals := []models.WithdrawBalance{}
+	for rows.Next() {
+		var w models.WithdrawBalance
+		if err := rows.Scan(&w.UID, &w.Order, &w.Sum, &w.ProcessedAt); err != nil {
+			return nil, "", err
+		}
+		withdrawals = append(withdrawals, w)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, "", err
+	}
+
+	var nextCursor string
+	if len(withdrawals) > params.Limit {
+		withdrawals = withdrawals[:params.Limit]
+		last := withdrawals[len(withdrawals)-1]
+		nextCursor = models.EncodeCursor(last.ProcessedAt, last.UID)
+	}
+
+	return withdrawals, nextCursor, nil
+}
+
+func (ps *PostgresStorage) DeadLetters() ([]models.DeadLetter, error) {
+	rows, err := ps.DB.Query(`
+        SELECT d.order_id, o.number, o.user_id, o.status, d.last_error, d.attempts, d.dead_at
+        FROM order_dead_letters d
+        JOIN orders o ON o.uid = d.order_id
+        ORDER BY d.dead_at DESC`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deadLetters []models.DeadLetter
+	for rows.Next() {
+		var d models.DeadLetter
+		if err := rows.Scan(&d.OrderID, &d.Number, &d.UserID, &d.Status, &d.LastError, &d.Attempts, &d.DeadAt); err != nil {
+			return nil, err
+		}
+		deadLetters = append(deadLetters, d)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return deadLetters, nil
+}
+
+// RequeueDeadLetter - возвращает заказ из DLQ в очередь order_jobs с обнулёнными попытками
+func (ps *PostgresStorage) RequeueDeadLetter(number string) error {
+	n, err := ps.requeueDeadLetters(`AND o.number = $1`, number)
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return handler.ErrDeadLetterNotFound
+	}
+	return nil
+}
+
+// RequeueAllDeadLetters - возвращает в очередь все заказы из DLQ
+func (ps *PostgresStorage) RequeueAllDeadLetters() (int64, error) {
+	return ps.requeueDeadLetters(``)
+}
+
+func (ps *PostgresStorage) requeueDeadLetters(filter string, args ...any) (int64, error) {
+	tx, err := ps.DB.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`
+        WITH moved AS (
+            DELETE FROM order_dead_letters d
+            USING orders o
+            WHERE o.uid = d.order_id `+filter+`
+            RETURNING d.order_id
+        )
+        INSERT INTO order_jobs (order_id)
+        SELECT order_id FROM moved
+        ON CONFLICT (order_id) DO UPDATE
+        SET attempts = 0,
+            run_at = NOW(),
+            locked_until = NULL,
+            locked_by = NULL,
+            last_error = NULL`, args...)
+	if err != nil {
+		return 0, fmt.Errorf("failed to requeue dead letters: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	// будим воркеров
+	if n > 0 {
+		if _, err := tx.Exec(`SELECT pg_notify('new_orders', 'requeue')`); err != nil {
+			return 0, fmt.Errorf("failed to notify workers: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+	return n, nil
+}
+
+func (ps *PostgresStorage) DeadLetterCount() (int64, error) {
+	var n int64
+	if err := ps.DB.QueryRow(`SELECT COUNT(*) FROM order_dead_letters`).Scan(&n); err != nil {
+		return 0, fmt.Errorf("failed to count dead letters: %w", err)
+	}
+	return n, nil
+}
+
+// UserEventsSince - события пользователя после afterID по возрастанию id, не больше limit
+func (ps *PostgresStorage) UserEventsSince(userID int, afterID int64, limit int) ([]models.UserEvent, error) {
+	rows, err := ps.DB.Query(`
+        SELECT id, user_id, type, data, created_at
+        FROM user_events
+        WHERE user_id = $1 AND id > $2
+        ORDER BY id
+        LIMIT $3`, userID, afterID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user events: %w", err)
+	}
+	defer rows.Close()
+
+	var events []models.UserEvent
+	for rows.Next() {
+		var ev models.UserEvent
+		var data []byte
+		if err := rows.Scan(&ev.ID, &ev.UserID, &ev.Type, &data, &ev.CreatedAt); err != nil {
+			return nil, err
+		}
+		ev.Data = data
+		events = append(events, ev)
+	}
+
+	return events, rows.Err()
+}
+
+// PruneUserEvents - удаляет события, созданные раньше before
+func (ps *PostgresStorage) PruneUserEvents(ctx context.Context, before time.Time) (int64, error) {
+	res, err := ps.DB.ExecContext(ctx, `DELETE FROM user_events WHERE created_at < $1`, before)
+	if err != nil {
+		return 0, fmt.Errorf("failed to prune user events: %w", err)
+	}
+	return res.RowsAffected()
+}
@@ -0,0 +1,85 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+)
+
+// Profile - профиль пользователя: заказы по статусам и суммы за всё время,
+// помесячные начисления и списания начиная с since. Месяцы без движений не возвращаются
+func (ps *PostgresStorage) Profile(userID int, since time.Time) (*models.Profile, error) {
+	profile := &models.Profile{ID: userID, Orders: map[string]int{}}
+	err := ps.DB.QueryRow(`
+        SELECT login, created_at,
+            (SELECT COALESCE(SUM(sum), 0) FROM withdrawals WHERE user_id = u.id)
+        FROM users u
+        WHERE id = $1`, userID).Scan(&profile.Login, &profile.RegisteredAt, &profile.Withdrawn)
+	if err == sql.ErrNoRows {
+		return nil, handler.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get user: %w", err)
+	}
+
+	rows, err := ps.DB.Query(`
+        SELECT status, COUNT(*), COALESCE(SUM(accrual) FILTER (WHERE status = 'PROCESSED'), 0)
+        FROM orders
+        WHERE user_id = $1
+        GROUP BY status`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count orders: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var status string
+		var count int
+		var accrued float64
+		if err := rows.Scan(&status, &count, &accrued); err != nil {
+			return nil, err
+		}
+		profile.Orders[status] = count
+		profile.Accrued += accrued
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// каждая таблица агрегируется по своему индексу (user_id, processed_at), затем месяцы сливаются
+	monthly, err := ps.DB.Query(`
+        SELECT to_char(month, 'YYYY-MM'), SUM(orders), SUM(accrued), SUM(withdrawn) FROM (
+            SELECT date_trunc('month', processed_at AT TIME ZONE 'UTC') AS month,
+                COUNT(*) AS orders, SUM(accrual) AS accrued, 0 AS withdrawn
+            FROM orders
+            WHERE user_id = $1 AND status = 'PROCESSED' AND processed_at >= $2
+            GROUP BY 1
+            UNION ALL
+            SELECT date_trunc('month', processed_at AT TIME ZONE 'UTC'), 0, 0, SUM(sum)
+            FROM withdrawals
+            WHERE user_id = $1 AND processed_at >= $2
+            GROUP BY 1
+        ) m
+        GROUP BY month
+        ORDER BY month`, userID, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get monthly accruals: %w", err)
+	}
+	defer monthly.Close()
+
+	for monthly.Next() {
+		var m models.MonthlyAccrual
+		if err := monthly.Scan(&m.Month, &m.Orders, &m.Accrued, &m.Withdrawn); err != nil {
+			return nil, err
+		}
+		profile.Monthly = append(profile.Monthly, m)
+	}
+	if err := monthly.Err(); err != nil {
+		return nil, err
+	}
+
+	return profile, nil
+}
@@ -0,0 +1,150 @@
+package postgres
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+)
+
+// CreatePromoCode - новый промокод, повтор кода - ErrPromoCodeExists
+func (ps *PostgresStorage) CreatePromoCode(adminID int, promo models.PromoCode) (*models.PromoCode, error) {
+	err := ps.DB.QueryRow(`
+        INSERT INTO promo_codes (code, value, max_redemptions, per_user_limit, expires_at, created_by)
+        VALUES ($1, $2, $3, $4, $5, $6)
+        ON CONFLICT (code) DO NOTHING
+        RETURNING id, redeemed, created_at`,
+		promo.Code, promo.Value, promo.MaxRedemptions, promo.PerUserLimit, promo.ExpiresAt, adminID).
+		Scan(&promo.ID, &promo.Redeemed, &promo.CreatedAt)
+	if err == sql.ErrNoRows {
+		return nil, handler.ErrPromoCodeExists
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create promo code: %w", err)
+	}
+	return &promo, nil
+}
+
+// PromoCodes - все промокоды, новые первыми
+func (ps *PostgresStorage) PromoCodes() ([]models.PromoCode, error) {
+	rows, err := ps.DB.Query(`
+        SELECT id, code, value, max_redemptions, per_user_limit, redeemed, expires_at, created_at
+        FROM promo_codes
+        ORDER BY created_at DESC`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get promo codes: %w", err)
+	}
+	defer rows.Close()
+
+	codes := []models.PromoCode{}
+	for rows.Next() {
+		var p models.PromoCode
+		if err := rows.Scan(&p.ID, &p.Code, &p.Value, &p.MaxRedemptions, &p.PerUserLimit, &p.Redeemed, &p.ExpiresAt, &p.CreatedAt); err != nil {
+			return nil, err
+		}
+		codes = append(codes, p)
+	}
+	return codes, rows.Err()
+}
+
+// RedeemPromoCode - погашение промокода и начисление баллов одной транзакцией.
+// Строка промокода блокируется, поэтому лимиты не превышаются при параллельных запросах
+func (ps *PostgresStorage) RedeemPromoCode(userID int, code string) (*models.PromoRedemption, error) {
+	tx, err := ps.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var promo models.PromoCode
+	err = tx.QueryRow(`
+        SELECT id, value, max_redemptions, per_user_limit, redeemed, expires_at
+        FROM promo_codes
+        WHERE code = $1
+        FOR UPDATE`, code).
+		Scan(&promo.ID, &promo.Value, &promo.MaxRedemptions, &promo.PerUserLimit, &promo.Redeemed, &promo.ExpiresAt)
+	if err == sql.ErrNoRows {
+		return nil, handler.ErrPromoCodeNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get promo code: %w", err)
+	}
+
+	if promo.ExpiresAt != nil && !time.Now().Before(*promo.ExpiresAt) {
+		return nil, handler.ErrPromoCodeExpired
+	}
+	if promo.MaxRedemptions > 0 && promo.Redeemed >= promo.MaxRedemptions {
+		return nil, handler.ErrPromoCodeExhausted
+	}
+
+	var used int
+	err = tx.QueryRow(`SELECT COUNT(*) FROM promo_redemptions WHERE promo_code_id = $1 AND user_id = $2`,
+		promo.ID, userID).Scan(&used)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count redemptions: %w", err)
+	}
+	if used >= promo.PerUserLimit {
+		return nil, handler.ErrPromoCodeLimitReached
+	}
+
+	redemption := &models.PromoRedemption{Code: code, Amount: promo.Value}
+	var adjustmentID int64
+	err = tx.QueryRow(`
+        INSERT INTO balance_adjustments (user_id, amount, kind, reference)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id, created_at`, userID, promo.Value, models.AdjustmentPromo, code).
+		Scan(&adjustmentID, &redemption.RedeemedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to credit promo code: %w", err)
+	}
+
+	if _, err := tx.Exec(`INSERT INTO promo_redemptions (promo_code_id, user_id, adjustment_id) VALUES ($1, $2, $3)`,
+		promo.ID, userID, adjustmentID); err != nil {
+		return nil, fmt.Errorf("failed to record redemption: %w", err)
+	}
+	if _, err := tx.Exec(`UPDATE promo_codes SET redeemed = redeemed + 1 WHERE id = $1`, promo.ID); err != nil {
+		return nil, fmt.Errorf("failed to update promo code: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return redemption, nil
+}
+
+// BalanceHistory - последние движения по счёту: начисления за заказы, корректировки и списания
+func (ps *PostgresStorage) BalanceHistory(userID, limit int) ([]models.BalanceEntry, error) {
+	rows, err := ps.DB.Query(`
+        SELECT kind, amount, reference, created_at FROM (
+            SELECT $3::text AS kind, accrual AS amount, number AS reference,
+                COALESCE(processed_at, uploaded_at) AS created_at
+            FROM orders
+            WHERE user_id = $1 AND status = 'PROCESSED' AND accrual > 0 AND household_id IS NULL
+            UNION ALL
+            SELECT kind, amount, COALESCE(reference, reason, ''), created_at
+            FROM balance_adjustments
+            WHERE user_id = $1
+            UNION ALL
+            SELECT $4::text, -sum, order_number, processed_at
+            FROM withdrawals
+            WHERE user_id = $1 AND household_id IS NULL
+        ) history
+        ORDER BY created_at DESC
+        LIMIT $2`, userID, limit, models.BalanceEntryAccrual, models.BalanceEntryWithdrawal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get balance history: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.BalanceEntry{}
+	for rows.Next() {
+		var e models.BalanceEntry
+		if err := rows.Scan(&e.Kind, &e.Amount, &e.Reference, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+	return entries, rows.Err()
+}
@@ -0,0 +1,126 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+)
+
+// SetReferralBonus - суммы бонусов за приглашение
+func (ps *PostgresStorage) SetReferralBonus(bonus models.ReferralBonus) {
+	ps.referralBonus = bonus
+}
+
+// CreateReferredUser - регистрация по реферальному коду одной транзакцией
+func (ps *PostgresStorage) CreateReferredUser(login, password, code string) (*models.User, error) {
+	existingUser, err := ps.GetUserByLogin(login)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check user existence: %w", err)
+	}
+	if existingUser != nil {
+		return nil, errors.New("login already exists")
+	}
+
+	tx, err := ps.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var referrerID int
+	err = tx.QueryRow(`SELECT id FROM users WHERE referral_code = $1`, code).Scan(&referrerID)
+	if err == sql.ErrNoRows {
+		return nil, handler.ErrInvalidReferralCode
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to find referrer: %w", err)
+	}
+
+	var user models.User
+	err = tx.QueryRow(`INSERT INTO users (login, password_hash) 
+              VALUES ($1, $2) 
+              RETURNING id, login, password_hash, created_at`, login, HashPassword(password)).
+		Scan(&user.ID, &user.Login, &user.PasswordHash, &user.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create user: %w", err)
+	}
+
+	if _, err := tx.Exec(`INSERT INTO referrals (referrer_id, referred_id) VALUES ($1, $2)`, referrerID, user.ID); err != nil {
+		return nil, fmt.Errorf("failed to create referral: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// rewardReferral - при первом обработанном заказе приглашённого начисляет бонусы обоим.
+// Вызывается в транзакции смены статуса, rewarded_at гарантирует однократность
+func (ps *PostgresStorage) rewardReferral(ctx context.Context, tx execer, orderID int) error {
+	_, err := tx.ExecContext(ctx, `
+        WITH rewarded AS (
+            UPDATE referrals
+            SET rewarded_at = NOW()
+            WHERE referred_id = (SELECT user_id FROM orders WHERE uid = $1)
+                AND rewarded_at IS NULL
+            RETURNING referrer_id, referred_id
+        )
+        INSERT INTO balance_adjustments (user_id, amount, kind, reference)
+        SELECT referrer_id, $2::numeric, $4, 'referred:' || referred_id FROM rewarded WHERE $2::numeric > 0
+        UNION ALL
+        SELECT referred_id, $3::numeric, $4, 'referrer:' || referrer_id FROM rewarded WHERE $3::numeric > 0`,
+		orderID, ps.referralBonus.Referrer, ps.referralBonus.Referred, models.AdjustmentReferral)
+	if err != nil {
+		return fmt.Errorf("failed to reward referral: %w", err)
+	}
+	return nil
+}
+
+// ReferralStats - код пользователя, приглашённые и заработанные бонусы
+func (ps *PostgresStorage) ReferralStats(userID int) (*models.ReferralStats, error) {
+	stats := &models.ReferralStats{Referrals: []models.Referral{}}
+
+	err := ps.DB.QueryRow(`
+        SELECT u.referral_code,
+            COALESCE((
+                SELECT SUM(amount) FROM balance_adjustments
+                WHERE user_id = u.id AND kind = $2 AND reference LIKE 'referred:%'
+            ), 0)
+        FROM users u
+        WHERE u.id = $1`, userID, models.AdjustmentReferral).Scan(&stats.Code, &stats.Earned)
+	if err == sql.ErrNoRows {
+		return nil, handler.ErrUserNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get referral code: %w", err)
+	}
+
+	rows, err := ps.DB.Query(`
+        SELECT created_at, rewarded_at
+        FROM referrals
+        WHERE referrer_id = $1
+        ORDER BY created_at DESC`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get referrals: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var r models.Referral
+		if err := rows.Scan(&r.JoinedAt, &r.RewardedAt); err != nil {
+			return nil, err
+		}
+		stats.Invited++
+		if r.RewardedAt != nil {
+			stats.Rewarded++
+		}
+		stats.Referrals = append(stats.Referrals, r)
+	}
+
+	return stats, rows.Err()
+}
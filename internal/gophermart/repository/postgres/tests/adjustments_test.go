@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresStorage_CreateAdjustment(t *testing.T) {
+	now := time.Now()
+
+	tests := []struct {
+		name      string
+		amount    float64
+		balance   float64
+		userFound bool
+		wantErr   error
+	}{
+		{name: "Credit", amount: 50, userFound: true},
+		{name: "Debit within balance", amount: -30, balance: 30, userFound: true},
+		{name: "Debit over balance", amount: -30, balance: 29.99, userFound: true, wantErr: handler.ErrLackOfFunds},
+		{name: "Unknown user", amount: 50, wantErr: handler.ErrUserNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			ps := newTestStorage(db)
+
+			mock.ExpectBegin()
+			userRows := sqlmock.NewRows([]string{"id"})
+			if tt.userFound {
+				userRows.AddRow(5)
+			}
+			mock.ExpectQuery(`SELECT id FROM users WHERE id = \$1 FOR UPDATE`).WithArgs(5).WillReturnRows(userRows)
+
+			if tt.userFound && tt.amount < 0 {
+				mock.ExpectQuery(`SELECT(.|\n)*balance`).WithArgs(5).
+					WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(tt.balance))
+			}
+			if tt.wantErr == nil {
+				mock.ExpectQuery(`INSERT INTO balance_adjustments \(user_id, amount, kind, reason, created_by\)`).
+					WithArgs(5, tt.amount, models.AdjustmentManual, "compensation", 1).
+					WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(9, now))
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+
+			adj, err := ps.CreateAdjustment(1, 5, tt.amount, "compensation")
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, int64(9), adj.ID)
+				assert.Equal(t, tt.amount, adj.Amount)
+				assert.Equal(t, 1, *adj.AdminID)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPostgresStorage_Adjustments(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+	now := time.Now()
+
+	mock.ExpectQuery(`FROM balance_adjustments a\s+LEFT JOIN users u ON u.id = a.created_by`).
+		WithArgs(5, 100).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "amount", "kind", "reason", "created_by", "login", "created_at"}).
+			AddRow(2, 5, -10.0, models.AdjustmentManual, "clawback", 1, "support", now).
+			AddRow(1, 5, 100.0, models.AdjustmentPromo, "", nil, "", now.Add(-time.Hour)))
+
+	adjustments, err := ps.Adjustments(5, 100)
+	require.NoError(t, err)
+	require.Len(t, adjustments, 2)
+	assert.Equal(t, "support", adjustments[0].AdminLogin)
+	assert.Nil(t, adjustments[1].AdminID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,72 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresStorage_RecordAudit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+	actor := 3
+
+	mock.ExpectExec(`INSERT INTO audit_events \(actor_id, action, target, ip, user_agent, payload\)`).
+		WithArgs(&actor, models.AuditWithdrawal, "order:2377225624", "203.0.113.5", "curl", `{"sum":10}`).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO audit_events`).
+		WithArgs(nil, models.AuditOrderStatusChanged, "order:1", "", "", nil).
+		WillReturnResult(sqlmock.NewResult(2, 1))
+
+	require.NoError(t, ps.RecordAudit(context.Background(), models.AuditEvent{
+		ActorID:   &actor,
+		Action:    models.AuditWithdrawal,
+		Target:    "order:2377225624",
+		IP:        "203.0.113.5",
+		UserAgent: "curl",
+		Payload:   json.RawMessage(`{"sum":10}`),
+	}))
+	// действие системы без автора и данных
+	require.NoError(t, ps.RecordAudit(context.Background(), models.AuditEvent{
+		Action: models.AuditOrderStatusChanged,
+		Target: "order:1",
+	}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStorage_AuditEvents(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+	now := time.Now()
+	columns := []string{"id", "actor_id", "action", "target", "ip", "user_agent", "payload", "created_at"}
+
+	mock.ExpectQuery(`FROM audit_events\s+WHERE TRUE AND \(actor_id = \$1 OR target = \$2\) AND action = \$3 ORDER BY created_at DESC, id DESC LIMIT \$4`).
+		WithArgs(5, "user:5", models.AuditBalanceAdjusted, 2).
+		WillReturnRows(sqlmock.NewRows(columns).
+			AddRow(9, 1, models.AuditBalanceAdjusted, "user:5", "", "", []byte(`{"amount":-10}`), now).
+			AddRow(8, 1, models.AuditBalanceAdjusted, "user:5", "", "", nil, now.Add(-time.Minute)))
+
+	events, next, err := ps.AuditEvents(models.AuditFilter{
+		UserID:     5,
+		Action:     models.AuditBalanceAdjusted,
+		ListParams: models.ListParams{Limit: 1},
+	})
+	require.NoError(t, err)
+	require.Len(t, events, 1)
+	assert.JSONEq(t, `{"amount":-10}`, string(events[0].Payload))
+	assert.Equal(t, models.EncodeCursor(now, 9), next)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -60,6 +60,14 @@ func TestPostgresStorage_CreateOrder_Success(t *testing.T) {
             ON CONFLICT (number) DO NOTHING
             RETURNING uid
         ),
+        job AS (
+            INSERT INTO order_jobs (order_id)
+            SELECT uid FROM inserted
+        ),
+        history AS (
+            INSERT INTO order_status_history (order_id, to_status)
+            SELECT uid, $3 FROM inserted
+        ),
         existing AS (
             SELECT user_id FROM orders WHERE number = $2
         )
@@ -99,6 +107,14 @@ func TestPostgresStorage_CreateOrder_Duplicate(t *testing.T) {
             ON CONFLICT (number) DO NOTHING
             RETURNING uid
         ),
+        job AS (
+            INSERT INTO order_jobs (order_id)
+            SELECT uid FROM inserted
+        ),
+        history AS (
+            INSERT INTO order_status_history (order_id, to_status)
+            SELECT uid, $3 FROM inserted
+        ),
         existing AS (
             SELECT user_id FROM orders WHERE number = $2
         )
@@ -139,6 +155,14 @@ func TestPostgresStorage_CreateOrder_Conflict(t *testing.T) {
             ON CONFLICT (number) DO NOTHING
             RETURNING uid
         ),
+        job AS (
+            INSERT INTO order_jobs (order_id)
+            SELECT uid FROM inserted
+        ),
+        history AS (
+            INSERT INTO order_status_history (order_id, to_status)
+            SELECT uid, $3 FROM inserted
+        ),
         existing AS (
             SELECT user_id FROM orders WHERE number = $2
         )
@@ -179,6 +203,14 @@ func TestPostgresStorage_CreateOrder_DatabaseError(t *testing.T) {
             ON CONFLICT (number) DO NOTHING
             RETURNING uid
         ),
+        job AS (
+            INSERT INTO order_jobs (order_id)
+            SELECT uid FROM inserted
+        ),
+        history AS (
+            INSERT INTO order_status_history (order_id, to_status)
+            SELECT uid, $3 FROM inserted
+        ),
         existing AS (
             SELECT user_id FROM orders WHERE number = $2
         )
@@ -219,6 +251,14 @@ func TestPostgresStorage_CreateOrder_UnexpectedResult(t *testing.T) {
             ON CONFLICT (number) DO NOTHING
             RETURNING uid
         ),
+        job AS (
+            INSERT INTO order_jobs (order_id)
+            SELECT uid FROM inserted
+        ),
+        history AS (
+            INSERT INTO order_status_history (order_id, to_status)
+            SELECT uid, $3 FROM inserted
+        ),
         existing AS (
             SELECT user_id FROM orders WHERE number = $2
         )
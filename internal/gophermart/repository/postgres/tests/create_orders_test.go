@@ -0,0 +1,34 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresStorage_CreateOrders(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+
+	// весь пакет вставляется одним запросом
+	mock.ExpectQuery(`jsonb_array_elements_text\(\$2::jsonb\)(.|\n)*INSERT INTO orders(.|\n)*INSERT INTO order_jobs(.|\n)*INSERT INTO order_status_history`).
+		WithArgs(1, `["12345678903","2377225624","79927398713"]`, "NEW").
+		WillReturnRows(sqlmock.NewRows([]string{"number", "result"}).
+			AddRow("12345678903", "accepted").
+			AddRow("2377225624", "duplicate").
+			AddRow("79927398713", "conflict"))
+
+	results, err := ps.CreateOrders(1, []string{"12345678903", "2377225624", "79927398713"})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"12345678903": "accepted",
+		"2377225624":  "duplicate",
+		"79927398713": "conflict",
+	}, results)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,107 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresStorage_DeadLetters(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+	deadAt := time.Now()
+
+	mock.ExpectQuery(`SELECT d.order_id, o.number, o.user_id, o.status, d.last_error, d.attempts, d.dead_at`).
+		WillReturnRows(sqlmock.NewRows([]string{"order_id", "number", "user_id", "status", "last_error", "attempts", "dead_at"}).
+			AddRow(42, "12345678903", 1, "NEW", "db update failed", 10, deadAt))
+
+	deadLetters, err := ps.DeadLetters()
+	require.NoError(t, err)
+	assert.Equal(t, []models.DeadLetter{{
+		OrderID:   42,
+		Number:    "12345678903",
+		UserID:    1,
+		Status:    "NEW",
+		LastError: "db update failed",
+		Attempts:  10,
+		DeadAt:    deadAt,
+	}}, deadLetters)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStorage_RequeueDeadLetter(t *testing.T) {
+	tests := []struct {
+		name          string
+		setupMock     func(mock sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name: "Requeued",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`DELETE FROM order_dead_letters d\s+USING orders o\s+WHERE o.uid = d.order_id AND o.number = \$1`).
+					WithArgs("12345678903").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`SELECT pg_notify`).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name: "Not found",
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectExec(`DELETE FROM order_dead_letters`).
+					WithArgs("12345678903").
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectCommit()
+			},
+			expectedError: handler.ErrDeadLetterNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			ps := newTestStorage(db)
+			tt.setupMock(mock)
+
+			err = ps.RequeueDeadLetter("12345678903")
+			assert.ErrorIs(t, err, tt.expectedError)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPostgresStorage_RequeueAllDeadLetters(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM order_dead_letters`).
+		WithArgs().
+		WillReturnResult(sqlmock.NewResult(0, 4))
+	mock.ExpectExec(`SELECT pg_notify`).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectCommit()
+
+	n, err := ps.RequeueAllDeadLetters()
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
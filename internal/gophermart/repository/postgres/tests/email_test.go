@@ -0,0 +1,108 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresStorage_SetUserEmail(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+	expiresAt := time.Now().Add(time.Hour)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE users SET email = \$2, email_verified_at = NULL WHERE id = \$1`).
+		WithArgs(1, "alice@example.com").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM email_verifications WHERE user_id = \$1 AND used_at IS NULL`).
+		WithArgs(1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO email_verifications`).
+		WithArgs(1, "alice@example.com", "hash", expiresAt).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, ps.SetUserEmail(1, "alice@example.com", "hash", expiresAt))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStorage_VerifyEmail(t *testing.T) {
+	t.Run("Verified", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		ps := newTestStorage(db)
+		now := time.Now()
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id, email FROM email_verifications`).
+			WithArgs("hash", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email"}).AddRow(7, "alice@example.com"))
+		mock.ExpectQuery(`SELECT EXISTS`).
+			WithArgs("alice@example.com", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+		mock.ExpectQuery(`UPDATE users SET email_verified_at = NOW\(\)`).
+			WithArgs(1, "alice@example.com").
+			WillReturnRows(sqlmock.NewRows([]string{"login", "email", "email_verified_at"}).
+				AddRow("alice", "alice@example.com", now))
+		mock.ExpectExec(`UPDATE email_verifications SET used_at = NOW\(\) WHERE id = \$1`).
+			WithArgs(7).
+			WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectCommit()
+
+		user, err := ps.VerifyEmail(1, "hash")
+		require.NoError(t, err)
+		assert.Equal(t, "alice", user.Login)
+		assert.True(t, user.EmailVerified())
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Invalid token", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		ps := newTestStorage(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id, email FROM email_verifications`).
+			WithArgs("hash", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email"}))
+		mock.ExpectRollback()
+
+		_, err = ps.VerifyEmail(1, "hash")
+		assert.ErrorIs(t, err, handler.ErrEmailTokenInvalid)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+
+	t.Run("Taken by another user", func(t *testing.T) {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+		defer db.Close()
+
+		ps := newTestStorage(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`SELECT id, email FROM email_verifications`).
+			WithArgs("hash", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "email"}).AddRow(7, "alice@example.com"))
+		mock.ExpectQuery(`SELECT EXISTS`).
+			WithArgs("alice@example.com", 1).
+			WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+		mock.ExpectRollback()
+
+		_, err = ps.VerifyEmail(1, "hash")
+		assert.ErrorIs(t, err, handler.ErrEmailTaken)
+		assert.NoError(t, mock.ExpectationsWereMet())
+	})
+}
@@ -0,0 +1,57 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresStorage_UserEventsSince(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+	createdAt := time.Now()
+
+	mock.ExpectQuery(`FROM user_events\s+WHERE user_id = \$1 AND id > \$2\s+ORDER BY id\s+LIMIT \$3`).
+		WithArgs(1, int64(5), 100).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "user_id", "type", "data", "created_at"}).
+			AddRow(int64(6), 1, "order", []byte(`{"status": "PROCESSED"}`), createdAt))
+
+	evs, err := ps.UserEventsSince(1, 5, 100)
+	require.NoError(t, err)
+	assert.Equal(t, []models.UserEvent{{
+		ID:        6,
+		UserID:    1,
+		Type:      models.UserEventOrder,
+		Data:      json.RawMessage(`{"status": "PROCESSED"}`),
+		CreatedAt: createdAt,
+	}}, evs)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStorage_PruneUserEvents(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+	before := time.Now().Add(-24 * time.Hour)
+
+	mock.ExpectExec(`DELETE FROM user_events WHERE created_at < \$1`).
+		WithArgs(before).
+		WillReturnResult(sqlmock.NewResult(0, 3))
+
+	n, err := ps.PruneUserEvents(context.Background(), before)
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
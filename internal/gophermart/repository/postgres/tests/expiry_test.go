@@ -0,0 +1,86 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresStorage_ExpirePoints(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+	before := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`GROUP BY user_id\s+HAVING SUM\(amount\) > 0`).
+		WithArgs(before).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1).AddRow(2))
+
+	// у первого пользователя сгорает остаток старых начислений
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM users WHERE id = \$1 FOR UPDATE`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(`AS expired`).
+		WithArgs(1, before).
+		WillReturnRows(sqlmock.NewRows([]string{"expired"}).AddRow(150.5))
+	mock.ExpectExec(`INSERT INTO balance_adjustments \(user_id, amount, kind\)`).
+		WithArgs(1, -150.5, models.AdjustmentExpiry).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO notifications`).
+		WithArgs(1, models.NotificationPointsExpired, []byte(`{"amount":150.5}`)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	// второй успел потратить баллы, пока шёл поиск
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM users WHERE id = \$1 FOR UPDATE`).
+		WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(2))
+	mock.ExpectQuery(`AS expired`).
+		WithArgs(2, before).
+		WillReturnRows(sqlmock.NewRows([]string{"expired"}).AddRow(0.0))
+	mock.ExpectRollback()
+
+	n, err := ps.ExpirePoints(context.Background(), before)
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStorage_ExpirePoints_Error(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+	before := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`GROUP BY user_id`).
+		WithArgs(before).
+		WillReturnRows(sqlmock.NewRows([]string{"user_id"}).AddRow(1))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM users WHERE id = \$1 FOR UPDATE`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(`AS expired`).
+		WithArgs(1, before).
+		WillReturnRows(sqlmock.NewRows([]string{"expired"}).AddRow(10.0))
+	mock.ExpectExec(`INSERT INTO balance_adjustments`).
+		WillReturnError(sql.ErrConnDone)
+	mock.ExpectRollback()
+
+	n, err := ps.ExpirePoints(context.Background(), before)
+	assert.ErrorIs(t, err, sql.ErrConnDone)
+	assert.Equal(t, 0, n)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,125 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresStorage_FraudActivity(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+	since := time.Now().Add(-time.Hour)
+	accrualAt := time.Now().Add(-time.Minute)
+
+	mock.ExpectQuery(`FILTER \(WHERE status = 'INVALID'\)`).
+		WithArgs(1, since).
+		WillReturnRows(sqlmock.NewRows([]string{"uploads", "final", "invalid", "withdrawals", "last_accrual"}).
+			AddRow(30, 20, 12, 2, accrualAt))
+
+	a, err := ps.FraudActivity(context.Background(), 1, since)
+	require.NoError(t, err)
+	assert.Equal(t, models.FraudActivity{Uploads: 30, FinalOrders: 20, InvalidOrders: 12, Withdrawals: 2, LastAccrualAt: &accrualAt}, a)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStorage_CreateFraudFlags(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO fraud_flags(.|\n)*ON CONFLICT \(user_id, rule\) WHERE status = 'OPEN'(.|\n)*hits = fraud_flags.hits \+ 1`).
+		WithArgs(1, "uploads_per_hour", models.FraudEventOrderUpload, models.FraudActionDelay, `{"value":101}`).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, ps.CreateFraudFlags(context.Background(), []models.FraudFlag{{
+		UserID:  1,
+		Rule:    "uploads_per_hour",
+		Event:   models.FraudEventOrderUpload,
+		Action:  models.FraudActionDelay,
+		Details: json.RawMessage(`{"value":101}`),
+	}}))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStorage_FraudEventTime(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+	since := time.Now().Add(-time.Hour)
+	at := since.Add(10 * time.Minute)
+
+	mock.ExpectQuery(`SELECT uploaded_at FROM orders(.|\n)*ORDER BY uploaded_at OFFSET \$3 LIMIT 1`).
+		WithArgs(1, since, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"uploaded_at"}).AddRow(at))
+	mock.ExpectQuery(`SELECT processed_at FROM withdrawals`).
+		WithArgs(1, since, 0).
+		WillReturnRows(sqlmock.NewRows([]string{"processed_at"}))
+
+	got, err := ps.FraudEventTime(context.Background(), 1, models.FraudEventOrderUpload, since, 2)
+	require.NoError(t, err)
+	require.NotNil(t, got)
+	assert.True(t, at.Equal(*got))
+
+	// событий в окне меньше - время неизвестно
+	got, err = ps.FraudEventTime(context.Background(), 1, models.FraudEventWithdrawal, since, 0)
+	require.NoError(t, err)
+	assert.Nil(t, got)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStorage_ReviewFraudFlag_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+
+	mock.ExpectQuery(`UPDATE fraud_flags`).
+		WithArgs(int64(9), models.FraudFlagConfirmed, "", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM fraud_flags WHERE id = \$1\)`).
+		WithArgs(int64(9)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	_, err = ps.ReviewFraudFlag(9, 1, models.FraudReview{Status: models.FraudFlagConfirmed})
+	assert.ErrorIs(t, err, handler.ErrFraudFlagNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStorage_ReviewFraudFlag_AlreadyReviewed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+
+	// закрытый флаг не попадает под условие status = 'OPEN'
+	mock.ExpectQuery(`UPDATE fraud_flags\s+SET .*\s+WHERE id = \$1 AND status = 'OPEN'`).
+		WithArgs(int64(3), models.FraudFlagDismissed, "", 1).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM fraud_flags WHERE id = \$1\)`).
+		WithArgs(int64(3)).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	_, err = ps.ReviewFraudFlag(3, 1, models.FraudReview{Status: models.FraudFlagDismissed})
+	assert.ErrorIs(t, err, handler.ErrFraudFlagAlreadyReviewed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
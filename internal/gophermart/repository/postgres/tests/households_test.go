@@ -0,0 +1,185 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresStorage_CreateHousehold(t *testing.T) {
+	now := time.Now()
+
+	for _, alreadyMember := range []bool{false, true} {
+		db, mock, err := sqlmock.New()
+		require.NoError(t, err)
+
+		ps := newTestStorage(db)
+
+		mock.ExpectBegin()
+		mock.ExpectQuery(`INSERT INTO households \(name, owner_id\)`).
+			WithArgs("Smiths", 5).
+			WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(3, now))
+		affected := int64(1)
+		if alreadyMember {
+			affected = 0
+		}
+		mock.ExpectExec(`INSERT INTO household_members(.|\n)*ON CONFLICT \(user_id\) DO NOTHING`).
+			WithArgs(3, 5, models.HouseholdRoleOwner).
+			WillReturnResult(sqlmock.NewResult(0, affected))
+		if alreadyMember {
+			mock.ExpectRollback()
+		} else {
+			mock.ExpectCommit()
+		}
+
+		household, err := ps.CreateHousehold(5, "Smiths")
+		if alreadyMember {
+			assert.ErrorIs(t, err, handler.ErrAlreadyInHousehold)
+		} else {
+			require.NoError(t, err)
+			assert.Equal(t, 3, household.ID)
+			assert.Equal(t, 5, household.OwnerID)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+		db.Close()
+	}
+}
+
+func TestPostgresStorage_WithdrawFromHousehold(t *testing.T) {
+	limit := 100.0
+
+	tests := []struct {
+		name        string
+		member      bool
+		role        string
+		canWithdraw bool
+		limit       *float64
+		sum         float64
+		balance     float64
+		wantErr     error
+	}{
+		{name: "Owner", member: true, role: models.HouseholdRoleOwner, sum: 300, balance: 500},
+		{name: "Member within limit", member: true, role: models.HouseholdRoleMember, canWithdraw: true, limit: &limit, sum: 100, balance: 500},
+		{name: "Member over limit", member: true, role: models.HouseholdRoleMember, canWithdraw: true, limit: &limit, sum: 100.01, wantErr: handler.ErrWithdrawNotPermitted},
+		{name: "Member without permission", member: true, role: models.HouseholdRoleMember, sum: 1, wantErr: handler.ErrWithdrawNotPermitted},
+		{name: "Lack of funds", member: true, role: models.HouseholdRoleOwner, sum: 300, balance: 299, wantErr: handler.ErrLackOfFunds},
+		{name: "Not a member", sum: 1, wantErr: handler.ErrHouseholdNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			ps := newTestStorage(db)
+
+			mock.ExpectBegin()
+			memberRows := sqlmock.NewRows([]string{"household_id", "role", "can_withdraw", "withdraw_limit"})
+			if tt.member {
+				memberRows.AddRow(3, tt.role, tt.canWithdraw, tt.limit)
+			}
+			mock.ExpectQuery(`FROM household_members m(.|\n)*FOR UPDATE OF h`).WithArgs(7).WillReturnRows(memberRows)
+
+			if tt.member && tt.wantErr != handler.ErrWithdrawNotPermitted {
+				mock.ExpectQuery(`WHERE household_id = \$1 AND status = 'PROCESSED'`).WithArgs(3).
+					WillReturnRows(sqlmock.NewRows([]string{"current", "withdrawn"}).AddRow(tt.balance, 0))
+			}
+			if tt.wantErr == nil {
+				mock.ExpectExec(`INSERT INTO withdrawals \(user_id, order_number, sum, household_id\)`).
+					WithArgs(7, "2377225624", tt.sum, 3).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectExec(`INSERT INTO webhook_deliveries`).WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(`INSERT INTO notifications`).WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			} else {
+				mock.ExpectRollback()
+			}
+
+			err = ps.WithdrawFromHousehold(7, models.WithdrawBalance{Order: "2377225624", Sum: tt.sum})
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPostgresStorage_RespondHouseholdInvite(t *testing.T) {
+	now := time.Now()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM household_invites i(.|\n)*FOR UPDATE OF i`).
+		WithArgs(11, 7, models.InvitePending).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "household_id", "name", "user_id", "invited_by", "created_at"}).
+			AddRow(11, 3, "Smiths", 7, 5, now))
+	mock.ExpectExec(`INSERT INTO household_members`).
+		WithArgs(3, 7, models.HouseholdRoleMember).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`UPDATE household_invites SET status = \$1`).
+		WithArgs(models.InviteAccepted, 11).
+		WillReturnRows(sqlmock.NewRows([]string{"responded_at"}).AddRow(now))
+	mock.ExpectCommit()
+
+	invite, err := ps.RespondHouseholdInvite(7, 11, true)
+	require.NoError(t, err)
+	assert.Equal(t, models.InviteAccepted, invite.Status)
+	assert.Equal(t, "Smiths", invite.HouseholdName)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStorage_RemoveHouseholdMember(t *testing.T) {
+	tests := []struct {
+		name     string
+		actorID  int
+		memberID int
+		role     string
+		wantErr  error
+	}{
+		{name: "Owner removes member", actorID: 5, memberID: 7, role: models.HouseholdRoleOwner},
+		{name: "Member leaves", actorID: 7, memberID: 7, role: models.HouseholdRoleMember},
+		{name: "Owner cannot leave", actorID: 5, memberID: 5, role: models.HouseholdRoleOwner, wantErr: handler.ErrOwnerCannotLeave},
+		{name: "Member cannot remove others", actorID: 7, memberID: 8, role: models.HouseholdRoleMember, wantErr: handler.ErrForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			ps := newTestStorage(db)
+
+			mock.ExpectQuery(`SELECT household_id, role FROM household_members WHERE user_id = \$1`).
+				WithArgs(tt.actorID).
+				WillReturnRows(sqlmock.NewRows([]string{"household_id", "role"}).AddRow(3, tt.role))
+			if tt.wantErr == nil {
+				mock.ExpectExec(`DELETE FROM household_members`).
+					WithArgs(3, tt.memberID, models.HouseholdRoleOwner).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			}
+
+			err = ps.RemoveHouseholdMember(tt.actorID, tt.memberID)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
@@ -0,0 +1,117 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresStorage_GetOrdersPage_NextCursor(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+	now := time.Now().UTC()
+
+	// лимит 2, выбирается 3 строки - значит есть следующая страница
+	mock.ExpectQuery(`FROM orders\s+WHERE user_id = \$1 AND status IN \(\$2, \$3\) ORDER BY uploaded_at DESC, uid DESC LIMIT \$4`).
+		WithArgs(1, "NEW", "PROCESSED", 3).
+		WillReturnRows(sqlmock.NewRows([]string{"uid", "number", "status", "accrual", "uploaded_at"}).
+			AddRow(3, "333", "NEW", 0.0, now).
+			AddRow(2, "222", "PROCESSED", 10.0, now.Add(-time.Minute)).
+			AddRow(1, "111", "NEW", 0.0, now.Add(-2*time.Minute)))
+
+	orders, next, err := ps.GetOrdersPage(1, models.ListParams{Limit: 2, Statuses: []string{"NEW", "PROCESSED"}})
+	require.NoError(t, err)
+	require.Len(t, orders, 2)
+	assert.Equal(t, "222", orders[1].Number)
+
+	at, id, err := models.DecodeCursor(next)
+	require.NoError(t, err)
+	assert.Equal(t, 2, id)
+	assert.True(t, at.Equal(now.Add(-time.Minute)))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStorage_GetOrdersPage_CursorAscending(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cursorAt := from.Add(time.Hour)
+
+	mock.ExpectQuery(`WHERE user_id = \$1 AND uploaded_at >= \$2 AND \(uploaded_at, uid\) > \(\$3, \$4\) ORDER BY uploaded_at ASC, uid ASC LIMIT \$5`).
+		WithArgs(1, from, cursorAt, 7, 11).
+		WillReturnRows(sqlmock.NewRows([]string{"uid", "number", "status", "accrual", "uploaded_at"}).
+			AddRow(8, "888", "NEW", 0.0, cursorAt.Add(time.Minute)))
+
+	orders, next, err := ps.GetOrdersPage(1, models.ListParams{
+		Limit:     10,
+		Cursor:    models.EncodeCursor(cursorAt, 7),
+		From:      &from,
+		Ascending: true,
+	})
+	require.NoError(t, err)
+	assert.Len(t, orders, 1)
+	assert.Empty(t, next)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStorage_WithdrawalsPage(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+	to := time.Date(2026, 2, 1, 0, 0, 0, 0, time.UTC)
+	processedAt := to.Add(-time.Hour)
+
+	mock.ExpectQuery(`FROM withdrawals\s+WHERE user_id = \$1 AND household_id IS NULL AND processed_at < \$2 ORDER BY processed_at DESC, uid DESC LIMIT \$3`).
+		WithArgs(1, to, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"uid", "order_number", "sum", "processed_at"}).
+			AddRow(5, "2377225624", 500.0, processedAt))
+
+	withdrawals, next, err := ps.WithdrawalsPage(1, models.ListParams{Limit: 1, To: &to})
+	require.NoError(t, err)
+	assert.Equal(t, []models.WithdrawBalance{{UID: 5, Order: "2377225624", Sum: 500, ProcessedAt: processedAt}}, withdrawals)
+	assert.Empty(t, next)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStorage_WithdrawalsPage_Empty(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+
+	mock.ExpectQuery(`FROM withdrawals`).
+		WithArgs(1, 11).
+		WillReturnRows(sqlmock.NewRows([]string{"uid", "order_number", "sum", "processed_at"}))
+
+	withdrawals, next, err := ps.WithdrawalsPage(1, models.ListParams{Limit: 10})
+	require.NoError(t, err)
+	assert.NotNil(t, withdrawals)
+	assert.Empty(t, withdrawals)
+	assert.Empty(t, next)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStorage_GetOrdersPage_InvalidCursor(t *testing.T) {
+	db, _, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+
+	_, _, err = ps.GetOrdersPage(1, models.ListParams{Limit: 10, Cursor: "%%%"})
+	assert.ErrorIs(t, err, models.ErrInvalidCursor)
+}
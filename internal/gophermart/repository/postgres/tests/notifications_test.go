@@ -0,0 +1,93 @@
+package postgres
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresStorage_Notifications(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+	now := time.Now()
+
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM notifications WHERE user_id = \$1 AND read_at IS NULL`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(1))
+	mock.ExpectQuery(`FROM notifications\s+WHERE user_id = \$1 AND \(NOT \$2 OR read_at IS NULL\)`).
+		WithArgs(1, false, 20).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "kind", "payload", "read_at", "created_at"}).
+			AddRow(2, models.NotificationOrderProcessed, []byte(`{"order":"12345678903","amount":500}`), nil, now).
+			AddRow(1, models.NotificationWithdrawalComplete, []byte(`{"order":"2377225624","amount":751}`), now, now).
+			AddRow(0, models.NotificationPointsExpired, []byte(`{"amount":120}`), now, now))
+
+	result, err := ps.Notifications(1, false, 20)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Unread)
+	require.Len(t, result.Notifications, 3)
+	assert.Equal(t, "120.00 unused points expired", result.Notifications[2].Message)
+	assert.Equal(t, models.NotificationData{Order: "12345678903", Amount: 500}, result.Notifications[0].Data)
+	assert.Equal(t, "Order 12345678903 processed: 500.00 points accrued", result.Notifications[0].Message)
+	assert.Nil(t, result.Notifications[0].ReadAt)
+	assert.NotNil(t, result.Notifications[1].ReadAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStorage_MarkNotificationsRead(t *testing.T) {
+	tests := []struct {
+		name  string
+		ids   []int64
+		query string
+		args  []driver.Value
+	}{
+		{
+			name:  "All unread",
+			query: `UPDATE notifications SET read_at = NOW\(\) WHERE user_id = \$1 AND read_at IS NULL$`,
+			args:  []driver.Value{1},
+		},
+		{
+			name:  "Selected",
+			ids:   []int64{3, 5},
+			query: `UPDATE notifications SET read_at = NOW\(\) WHERE user_id = \$1 AND read_at IS NULL AND id = ANY\(\$2\)`,
+			args:  []driver.Value{1, []int64{3, 5}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New(sqlmock.ValueConverterOption(int64SliceConverter{}))
+			require.NoError(t, err)
+			defer db.Close()
+
+			ps := newTestStorage(db)
+
+			mock.ExpectExec(tt.query).WithArgs(tt.args...).WillReturnResult(sqlmock.NewResult(0, 2))
+			mock.ExpectQuery(`SELECT COUNT\(\*\) FROM notifications`).WithArgs(1).
+				WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(4))
+
+			unread, err := ps.MarkNotificationsRead(1, tt.ids)
+			require.NoError(t, err)
+			assert.Equal(t, 4, unread)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+// int64SliceConverter - массив передаётся драйверу как есть, как это делает pgx
+type int64SliceConverter struct{}
+
+func (int64SliceConverter) ConvertValue(v any) (driver.Value, error) {
+	if ids, ok := v.([]int64); ok {
+		return ids, nil
+	}
+	return driver.DefaultParameterConverter.ConvertValue(v)
+}
@@ -0,0 +1,64 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresStorage_GetOrder(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+	uploadedAt := time.Now().Add(-time.Hour)
+	processedAt := time.Now()
+
+	mock.ExpectQuery(`SELECT uid, user_id, number, status, accrual, uploaded_at, processed_at\s+FROM orders\s+WHERE user_id = \$1 AND number = \$2`).
+		WithArgs(1, "12345678903").
+		WillReturnRows(sqlmock.NewRows([]string{"uid", "user_id", "number", "status", "accrual", "uploaded_at", "processed_at"}).
+			AddRow(42, 1, "12345678903", "PROCESSED", 500.0, uploadedAt, processedAt))
+	mock.ExpectQuery(`FROM order_status_history`).
+		WithArgs(42).
+		WillReturnRows(sqlmock.NewRows([]string{"from_status", "to_status", "accrual", "changed_at"}).
+			AddRow("", "NEW", 0.0, uploadedAt).
+			AddRow("NEW", "PROCESSED", 500.0, processedAt))
+
+	details, err := ps.GetOrder(1, "12345678903")
+	require.NoError(t, err)
+
+	assert.Equal(t, "PROCESSED", details.Status)
+	assert.Equal(t, uploadedAt, details.UploadedAt)
+	require.NotNil(t, details.ProcessedAt)
+	assert.Equal(t, processedAt, *details.ProcessedAt)
+	assert.Equal(t, []models.OrderStatusChange{
+		{Status: "NEW", ChangedAt: uploadedAt},
+		{From: "NEW", Status: "PROCESSED", Accrual: 500, ChangedAt: processedAt},
+	}, details.History)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStorage_GetOrder_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+
+	// заказ другого пользователя не находится по user_id
+	mock.ExpectQuery(`FROM orders\s+WHERE user_id = \$1 AND number = \$2`).
+		WithArgs(2, "12345678903").
+		WillReturnRows(sqlmock.NewRows([]string{"uid", "user_id", "number", "status", "accrual", "uploaded_at", "processed_at"}))
+
+	details, err := ps.GetOrder(2, "12345678903")
+	assert.Nil(t, details)
+	assert.ErrorIs(t, err, handler.ErrOrderNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
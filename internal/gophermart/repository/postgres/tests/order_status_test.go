@@ -0,0 +1,146 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresStorage_UpdateOrderStatus(t *testing.T) {
+	tests := []struct {
+		name          string
+		status        string
+		setupMock     func(mock sqlmock.Sqlmock)
+		expectedError error
+	}{
+		{
+			name:   "Valid transition is recorded",
+			status: models.OrderStatusProcessed,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT status FROM orders WHERE uid = \$1 FOR UPDATE`).
+					WithArgs(42).
+					WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("PROCESSING"))
+				mock.ExpectExec(`UPDATE orders`).
+					WithArgs(models.OrderStatusProcessed, 500.0, 42).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO order_status_history`).
+					WithArgs(42, "PROCESSING", models.OrderStatusProcessed, 500.0).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				// первый обработанный заказ приглашённого начисляет реферальные бонусы
+				mock.ExpectExec(`UPDATE referrals(.|\n)*INSERT INTO balance_adjustments`).
+					WithArgs(42, 0.0, 0.0, models.AdjustmentReferral).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+				mock.ExpectExec(`INSERT INTO webhook_deliveries`).
+					WithArgs(42, models.WebhookEventOrderProcessed).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				// уведомление владельцу заказа
+				mock.ExpectExec(`INSERT INTO notifications`).
+					WithArgs(42, models.NotificationOrderProcessed, 500.0).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:   "Non-final transition does not trigger webhooks",
+			status: models.OrderStatusProcessing,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT status FROM orders`).
+					WithArgs(42).
+					WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("NEW"))
+				mock.ExpectExec(`UPDATE orders`).
+					WithArgs(models.OrderStatusProcessing, 500.0, 42).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+				mock.ExpectExec(`INSERT INTO order_status_history`).
+					WithArgs(42, "NEW", models.OrderStatusProcessing, 500.0).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+				mock.ExpectCommit()
+			},
+		},
+		{
+			name:   "Same status is a no-op",
+			status: models.OrderStatusProcessing,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT status FROM orders`).
+					WithArgs(42).
+					WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("PROCESSING"))
+				mock.ExpectRollback()
+			},
+		},
+		{
+			name:   "Processed order can not move backwards",
+			status: models.OrderStatusProcessing,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+				mock.ExpectQuery(`SELECT status FROM orders`).
+					WithArgs(42).
+					WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("PROCESSED"))
+				mock.ExpectRollback()
+			},
+			expectedError: models.ErrInvalidStatusTransition,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			ps := newTestStorage(db)
+			tt.setupMock(mock)
+
+			err = ps.UpdateOrderStatus(context.Background(), 42, tt.status, 500)
+			if tt.expectedError != nil {
+				assert.ErrorIs(t, err, tt.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPostgresStorage_UpdateOrderStatusByNumber(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+
+	mock.ExpectQuery(`SELECT uid FROM orders WHERE number = \$1`).
+		WithArgs("12345678903").
+		WillReturnRows(sqlmock.NewRows([]string{"uid"}).AddRow(42))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT status FROM orders WHERE uid = \$1 FOR UPDATE`).
+		WithArgs(42).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("NEW"))
+	mock.ExpectExec(`UPDATE orders`).
+		WithArgs(models.OrderStatusInvalid, 0.0, 42).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO order_status_history`).
+		WithArgs(42, "NEW", models.OrderStatusInvalid, 0.0).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO webhook_deliveries`).
+		WithArgs(42, models.WebhookEventOrderInvalid).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO notifications`).
+		WithArgs(42, models.NotificationOrderInvalid, 0.0).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	// финальный статус - задача опроса accrual больше не нужна
+	mock.ExpectExec(`DELETE FROM order_jobs WHERE order_id = \$1`).
+		WithArgs(42).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	err = ps.UpdateOrderStatusByNumber(context.Background(), "12345678903", models.OrderStatusInvalid, 0)
+	require.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
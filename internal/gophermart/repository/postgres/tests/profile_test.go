@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresStorage_Profile(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+	registered := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+	since := time.Date(2026, 8, 1, 0, 0, 0, 0, time.UTC)
+
+	mock.ExpectQuery(`SELECT login, created_at(.|\n)*FROM users u`).WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"login", "created_at", "withdrawn"}).AddRow("alice", registered, 40.5))
+	mock.ExpectQuery(`SELECT status, COUNT\(\*\)(.|\n)*GROUP BY status`).WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"status", "count", "accrued"}).
+			AddRow(models.OrderStatusProcessed, 3, 250.0).
+			AddRow(models.OrderStatusInvalid, 1, 0.0))
+	mock.ExpectQuery(`date_trunc\('month'(.|\n)*FROM orders(.|\n)*UNION ALL(.|\n)*FROM withdrawals`).WithArgs(1, since).
+		WillReturnRows(sqlmock.NewRows([]string{"month", "orders", "accrued", "withdrawn"}).
+			AddRow("2026-08", 2, 200.0, 0.0).
+			AddRow("2026-10", 1, 50.0, 40.5))
+
+	profile, err := ps.Profile(1, since)
+	require.NoError(t, err)
+	assert.Equal(t, "alice", profile.Login)
+	assert.Equal(t, registered, profile.RegisteredAt)
+	assert.Equal(t, map[string]int{models.OrderStatusProcessed: 3, models.OrderStatusInvalid: 1}, profile.Orders)
+	assert.Equal(t, 250.0, profile.Accrued)
+	assert.Equal(t, 40.5, profile.Withdrawn)
+	assert.Equal(t, []models.MonthlyAccrual{
+		{Month: "2026-08", Orders: 2, Accrued: 200},
+		{Month: "2026-10", Orders: 1, Accrued: 50, Withdrawn: 40.5},
+	}, profile.Monthly)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStorage_Profile_UserNotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+	mock.ExpectQuery(`FROM users u`).WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"login", "created_at", "withdrawn"}))
+
+	_, err = ps.Profile(9, time.Now())
+	assert.ErrorIs(t, err, handler.ErrUserNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,131 @@
+package postgres
+
+import (
+	"database/sql/driver"
+	"testing"
+	"time"
+
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var promoColumns = []string{"id", "value", "max_redemptions", "per_user_limit", "redeemed", "expires_at"}
+
+func TestPostgresStorage_RedeemPromoCode(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+	now := time.Now()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`FROM promo_codes\s+WHERE code = \$1\s+FOR UPDATE`).
+		WithArgs("SPRING").
+		WillReturnRows(sqlmock.NewRows(promoColumns).AddRow(3, 100.0, 10, 1, 4, nil))
+	mock.ExpectQuery(`SELECT COUNT\(\*\) FROM promo_redemptions`).
+		WithArgs(3, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(0))
+	mock.ExpectQuery(`INSERT INTO balance_adjustments`).
+		WithArgs(1, 100.0, models.AdjustmentPromo, "SPRING").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(11, now))
+	mock.ExpectExec(`INSERT INTO promo_redemptions`).
+		WithArgs(3, 1, int64(11)).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`UPDATE promo_codes SET redeemed = redeemed \+ 1`).
+		WithArgs(3).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	redemption, err := ps.RedeemPromoCode(1, "SPRING")
+	require.NoError(t, err)
+	assert.Equal(t, 100.0, redemption.Amount)
+	assert.Equal(t, now, redemption.RedeemedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStorage_RedeemPromoCode_Rejected(t *testing.T) {
+	expired := time.Now().Add(-time.Minute)
+
+	tests := []struct {
+		name    string
+		row     []driver.Value
+		used    int
+		wantErr error
+	}{
+		{name: "Unknown code", wantErr: handler.ErrPromoCodeNotFound},
+		{name: "Expired", row: []driver.Value{3, 100.0, 0, 1, 0, expired}, wantErr: handler.ErrPromoCodeExpired},
+		{name: "Exhausted", row: []driver.Value{3, 100.0, 5, 1, 5, nil}, wantErr: handler.ErrPromoCodeExhausted},
+		{name: "Per-user limit", row: []driver.Value{3, 100.0, 0, 2, 7, nil}, used: 2, wantErr: handler.ErrPromoCodeLimitReached},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			ps := newTestStorage(db)
+
+			rows := sqlmock.NewRows(promoColumns)
+			if tt.row != nil {
+				rows.AddRow(tt.row...)
+			}
+
+			mock.ExpectBegin()
+			mock.ExpectQuery(`FROM promo_codes`).WithArgs("CODE").WillReturnRows(rows)
+			if tt.wantErr == handler.ErrPromoCodeLimitReached {
+				mock.ExpectQuery(`SELECT COUNT\(\*\) FROM promo_redemptions`).
+					WillReturnRows(sqlmock.NewRows([]string{"count"}).AddRow(tt.used))
+			}
+			mock.ExpectRollback()
+
+			_, err = ps.RedeemPromoCode(1, "CODE")
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestPostgresStorage_CreatePromoCode_Exists(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+
+	mock.ExpectQuery(`INSERT INTO promo_codes(.|\n)*ON CONFLICT \(code\) DO NOTHING`).
+		WithArgs("SPRING", 100.0, 0, 1, nil, 7).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "redeemed", "created_at"}))
+
+	_, err = ps.CreatePromoCode(7, models.PromoCode{Code: "SPRING", Value: 100, PerUserLimit: 1})
+	assert.ErrorIs(t, err, handler.ErrPromoCodeExists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStorage_BalanceHistory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+	now := time.Now()
+
+	mock.ExpectQuery(`FROM orders(.|\n)*UNION ALL(.|\n)*FROM balance_adjustments(.|\n)*UNION ALL(.|\n)*FROM withdrawals`).
+		WithArgs(1, 50, models.BalanceEntryAccrual, models.BalanceEntryWithdrawal).
+		WillReturnRows(sqlmock.NewRows([]string{"kind", "amount", "reference", "created_at"}).
+			AddRow(models.AdjustmentPromo, 100.0, "SPRING", now).
+			AddRow(models.BalanceEntryWithdrawal, -30.0, "2377225624", now.Add(-time.Hour)).
+			AddRow(models.BalanceEntryAccrual, 500.0, "12345678903", now.Add(-2*time.Hour)))
+
+	entries, err := ps.BalanceHistory(1, 50)
+	require.NoError(t, err)
+	require.Len(t, entries, 3)
+	assert.Equal(t, models.AdjustmentPromo, entries[0].Kind)
+	assert.Equal(t, -30.0, entries[1].Amount)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
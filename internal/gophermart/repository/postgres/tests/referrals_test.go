@@ -0,0 +1,129 @@
+package postgres
+
+import (
+	"context"
+	"regexp"
+	"testing"
+	"time"
+
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+	"go-musthave-diploma-tpl/internal/gophermart/repository/postgres"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresStorage_CreateReferredUser(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+	createdAt := time.Now()
+
+	mock.ExpectQuery(regexp.QuoteMeta(`SELECT id, login, password_hash, created_at FROM users WHERE login = $1`)).
+		WithArgs("friend").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "login", "password_hash", "created_at"}))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM users WHERE referral_code = \$1`).
+		WithArgs("abc123").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(`INSERT INTO users`).
+		WithArgs("friend", postgres.HashPassword("secret")).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "login", "password_hash", "created_at"}).
+			AddRow(2, "friend", postgres.HashPassword("secret"), createdAt))
+	mock.ExpectExec(`INSERT INTO referrals \(referrer_id, referred_id\)`).
+		WithArgs(1, 2).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	user, err := ps.CreateReferredUser("friend", "secret", "abc123")
+	require.NoError(t, err)
+	assert.Equal(t, 2, user.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStorage_CreateReferredUser_InvalidCode(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+
+	mock.ExpectQuery(`FROM users WHERE login = \$1`).
+		WithArgs("friend").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "login", "password_hash", "created_at"}))
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT id FROM users WHERE referral_code = \$1`).
+		WithArgs("nope").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}))
+	mock.ExpectRollback()
+
+	// пользователь не создаётся, если код неверный
+	_, err = ps.CreateReferredUser("friend", "secret", "nope")
+	assert.ErrorIs(t, err, handler.ErrInvalidReferralCode)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStorage_UpdateOrderStatus_RewardsReferral(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+	ps.SetReferralBonus(models.ReferralBonus{Referrer: 100, Referred: 50})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`SELECT status FROM orders WHERE uid = \$1 FOR UPDATE`).
+		WithArgs(42).
+		WillReturnRows(sqlmock.NewRows([]string{"status"}).AddRow("PROCESSING"))
+	mock.ExpectExec(`UPDATE orders`).
+		WithArgs(models.OrderStatusProcessed, 10.0, 42).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO order_status_history`).
+		WithArgs(42, "PROCESSING", models.OrderStatusProcessed, 10.0).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`UPDATE referrals(.|\n)*rewarded_at IS NULL(.|\n)*INSERT INTO balance_adjustments`).
+		WithArgs(42, 100.0, 50.0, models.AdjustmentReferral).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`INSERT INTO webhook_deliveries`).
+		WithArgs(42, models.WebhookEventOrderProcessed).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO notifications`).
+		WithArgs(42, models.NotificationOrderProcessed, 10.0).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	require.NoError(t, ps.UpdateOrderStatus(context.Background(), 42, models.OrderStatusProcessed, 10))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStorage_ReferralStats(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+	joined := time.Now().Add(-48 * time.Hour)
+	rewarded := time.Now()
+
+	mock.ExpectQuery(`SELECT u.referral_code`).
+		WithArgs(1, models.AdjustmentReferral).
+		WillReturnRows(sqlmock.NewRows([]string{"referral_code", "earned"}).AddRow("abc123", 100.0))
+	mock.ExpectQuery(`FROM referrals\s+WHERE referrer_id = \$1`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"created_at", "rewarded_at"}).
+			AddRow(joined, rewarded).
+			AddRow(joined, nil))
+
+	stats, err := ps.ReferralStats(1)
+	require.NoError(t, err)
+	assert.Equal(t, "abc123", stats.Code)
+	assert.Equal(t, 2, stats.Invited)
+	assert.Equal(t, 1, stats.Rewarded)
+	assert.Equal(t, 100.0, stats.Earned)
+	assert.Len(t, stats.Referrals, 2)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
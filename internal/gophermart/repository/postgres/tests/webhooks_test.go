@@ -0,0 +1,109 @@
+package postgres
+
+import (
+	"testing"
+	"time"
+
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPostgresStorage_CreateWebhook(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+	createdAt := time.Now()
+
+	mock.ExpectQuery(`INSERT INTO webhooks \(user_id, url, secret, events\)`).
+		WithArgs(1, "https://partner.example/hook", sqlmock.AnyArg(), "order.processed,order.invalid").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "created_at"}).AddRow(5, createdAt))
+
+	webhook, err := ps.CreateWebhook(1, "https://partner.example/hook", []string{"order.processed", "order.invalid"})
+	require.NoError(t, err)
+	assert.Equal(t, 5, webhook.ID)
+	assert.Len(t, webhook.Secret, 64)
+	assert.True(t, webhook.Active)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStorage_Webhooks(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+	createdAt := time.Now()
+
+	mock.ExpectQuery(`SELECT id, url, events, active, created_at\s+FROM webhooks\s+WHERE user_id = \$1`).
+		WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "url", "events", "active", "created_at"}).
+			AddRow(5, "https://partner.example/hook", "order.processed,withdrawal.created", true, createdAt))
+
+	webhooks, err := ps.Webhooks(1)
+	require.NoError(t, err)
+	assert.Equal(t, []models.Webhook{{
+		ID:        5,
+		UserID:    1,
+		URL:       "https://partner.example/hook",
+		Events:    []string{"order.processed", "withdrawal.created"},
+		Active:    true,
+		CreatedAt: createdAt,
+	}}, webhooks)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStorage_DeleteWebhook_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+
+	// чужой вебхук не удаляется
+	mock.ExpectExec(`DELETE FROM webhooks WHERE id = \$1 AND user_id = \$2`).
+		WithArgs(5, 2).
+		WillReturnResult(sqlmock.NewResult(0, 0))
+
+	err = ps.DeleteWebhook(2, 5)
+	assert.ErrorIs(t, err, handler.ErrWebhookNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPostgresStorage_WebhookDeliveries(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	ps := newTestStorage(db)
+	createdAt := time.Now()
+
+	mock.ExpectQuery(`SELECT EXISTS`).
+		WithArgs(5, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`FROM webhook_deliveries\s+WHERE webhook_id = \$1\s+ORDER BY id DESC\s+LIMIT \$2`).
+		WithArgs(5, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "webhook_id", "event", "payload", "status", "attempts", "last_status_code", "last_error", "created_at", "delivered_at"}).
+			AddRow(int64(9), 5, "order.invalid", []byte(`{}`), "PENDING", 2, 502, "unexpected status 502", createdAt, nil))
+
+	deliveries, err := ps.WebhookDeliveries(1, 5, 10)
+	require.NoError(t, err)
+	require.Len(t, deliveries, 1)
+	assert.Equal(t, 2, deliveries[0].Attempts)
+	require.NotNil(t, deliveries[0].LastStatusCode)
+	assert.Equal(t, 502, *deliveries[0].LastStatusCode)
+	assert.Nil(t, deliveries[0].DeliveredAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+
+	mock.ExpectQuery(`SELECT EXISTS`).
+		WithArgs(6, 1).
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	_, err = ps.WebhookDeliveries(1, 6, 10)
+	assert.ErrorIs(t, err, handler.ErrWebhookNotFound)
+}
@@ -30,6 +30,11 @@ func TestPostgresStorage_Withdraw(t *testing.T) {
 			setupMock: func(mock sqlmock.Sqlmock) {
 				mock.ExpectBegin()
 
+				// блокировка пользователя на время проверки баланса
+				mock.ExpectQuery(`SELECT id FROM users WHERE id = \$1 FOR UPDATE`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
 				// расчёт баланса
 				mock.ExpectQuery(`SELECT.*balance`).
 					WithArgs(1).
@@ -40,6 +45,16 @@ func TestPostgresStorage_Withdraw(t *testing.T) {
 					WithArgs(1, "2377225624", 751.0).
 					WillReturnResult(sqlmock.NewResult(1, 1))
 
+				// доставка вебхуков в той же транзакции
+				mock.ExpectExec(`INSERT INTO webhook_deliveries`).
+					WithArgs(1, models.WebhookEventWithdrawalCreated, "2377225624", 751.0).
+					WillReturnResult(sqlmock.NewResult(0, 0))
+
+				// уведомление о списании
+				mock.ExpectExec(`INSERT INTO notifications`).
+					WithArgs(1, models.NotificationWithdrawalComplete, []byte(`{"order":"2377225624","amount":751}`)).
+					WillReturnResult(sqlmock.NewResult(1, 1))
+
 				mock.ExpectCommit()
 			},
 			expectedError: nil,
@@ -54,6 +69,11 @@ func TestPostgresStorage_Withdraw(t *testing.T) {
 			setupMock: func(mock sqlmock.Sqlmock) {
 				mock.ExpectBegin()
 
+				// блокировка пользователя на время проверки баланса
+				mock.ExpectQuery(`SELECT id FROM users WHERE id = \$1 FOR UPDATE`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
 				// баланс меньше суммы списания
 				mock.ExpectQuery(`SELECT.*balance`).
 					WithArgs(1).
@@ -73,6 +93,11 @@ func TestPostgresStorage_Withdraw(t *testing.T) {
 			setupMock: func(mock sqlmock.Sqlmock) {
 				mock.ExpectBegin()
 
+				// блокировка пользователя на время проверки баланса
+				mock.ExpectQuery(`SELECT id FROM users WHERE id = \$1 FOR UPDATE`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
 				mock.ExpectQuery(`SELECT.*balance`).
 					WithArgs(1).
 					WillReturnError(sql.ErrConnDone)
@@ -91,6 +116,11 @@ func TestPostgresStorage_Withdraw(t *testing.T) {
 			setupMock: func(mock sqlmock.Sqlmock) {
 				mock.ExpectBegin()
 
+				// блокировка пользователя на время проверки баланса
+				mock.ExpectQuery(`SELECT id FROM users WHERE id = \$1 FOR UPDATE`).
+					WithArgs(1).
+					WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
 				mock.ExpectQuery(`SELECT.*balance`).
 					WithArgs(1).
 					WillReturnRows(sqlmock.NewRows([]string{"balance"}).AddRow(1000.0))
@@ -103,6 +133,24 @@ func TestPostgresStorage_Withdraw(t *testing.T) {
 			},
 			expectedError: sql.ErrConnDone,
 		},
+		{
+			name:   "Database error when locking user",
+			userID: 1,
+			withdraw: models.WithdrawBalance{
+				Order: "2377225624",
+				Sum:   500,
+			},
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectBegin()
+
+				mock.ExpectQuery(`SELECT id FROM users WHERE id = \$1 FOR UPDATE`).
+					WithArgs(1).
+					WillReturnError(sql.ErrConnDone)
+
+				mock.ExpectRollback()
+			},
+			expectedError: sql.ErrConnDone,
+		},
 		{
 			name:   "Transaction begin error",
 			userID: 1,
@@ -47,7 +47,7 @@ func TestPostgresStorage_Withdrawals(t *testing.T) {
 					sum,
 					processed_at
 				FROM withdrawals
-				WHERE user_id = \$1
+				WHERE user_id = \$1 AND household_id IS NULL
 				ORDER BY processed_at DESC`).
 					WithArgs(1).
 					WillReturnRows(rows)
@@ -77,7 +77,7 @@ func TestPostgresStorage_Withdrawals(t *testing.T) {
 					sum,
 					processed_at
 				FROM withdrawals
-				WHERE user_id = \$1
+				WHERE user_id = \$1 AND household_id IS NULL
 				ORDER BY processed_at DESC`).
 					WithArgs(2).
 					WillReturnRows(rows)
@@ -94,7 +94,7 @@ func TestPostgresStorage_Withdrawals(t *testing.T) {
 					sum,
 					processed_at
 				FROM withdrawals
-				WHERE user_id = \$1
+				WHERE user_id = \$1 AND household_id IS NULL
 				ORDER BY processed_at DESC`).
 					WithArgs(3).
 					WillReturnError(sql.ErrConnDone)
@@ -115,7 +115,7 @@ func TestPostgresStorage_Withdrawals(t *testing.T) {
 					sum,
 					processed_at
 				FROM withdrawals
-				WHERE user_id = \$1
+				WHERE user_id = \$1 AND household_id IS NULL
 				ORDER BY processed_at DESC`).
 					WithArgs(4).
 					WillReturnRows(rows)
@@ -136,7 +136,7 @@ func TestPostgresStorage_Withdrawals(t *testing.T) {
 					sum,
 					processed_at
 				FROM withdrawals
-				WHERE user_id = \$1
+				WHERE user_id = \$1 AND household_id IS NULL
 				ORDER BY processed_at DESC`).
 					WithArgs(5).
 					WillReturnRows(rows)
@@ -156,7 +156,7 @@ func TestPostgresStorage_Withdrawals(t *testing.T) {
 					sum,
 					processed_at
 				FROM withdrawals
-				WHERE user_id = \$1
+				WHERE user_id = \$1 AND household_id IS NULL
 				ORDER BY processed_at DESC`).
 					WithArgs(6).
 					WillReturnRows(rows)
@@ -244,7 +244,7 @@ func TestPostgresStorage_Withdrawals_RowsClose(t *testing.T) {
 			sum,
 			processed_at
 		FROM withdrawals
-		WHERE user_id = \$1
+		WHERE user_id = \$1 AND household_id IS NULL
 		ORDER BY processed_at DESC`).
 			WithArgs(1).
 			WillReturnRows(rows)
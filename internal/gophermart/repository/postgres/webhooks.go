@@ -0,0 +1,164 @@
+package postgres
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+	"strings"
+
+	handler "go-musthave-diploma-tpl/internal/gophermart/handler"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+)
+
+// execer - общий интерфейс *sql.DB и *sql.Tx
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// enqueueOrderWebhooks - кладёт в outbox доставки события заказа подписанным вебхукам владельца.
+// Вызывается в транзакции смены статуса, поэтому событие не теряется и не появляется без коммита
+func enqueueOrderWebhooks(ctx context.Context, tx execer, orderID int, event string) error {
+	_, err := tx.ExecContext(ctx, `
+        INSERT INTO webhook_deliveries (webhook_id, event, payload)
+        SELECT w.id, $2, jsonb_build_object(
+            'event', $2::text,
+            'number', o.number,
+            'status', o.status,
+            'accrual', o.accrual,
+            'processed_at', o.processed_at
+        )
+        FROM orders o
+        JOIN webhooks w ON w.user_id = o.user_id
+        WHERE o.uid = $1 AND w.active AND $2 = ANY(string_to_array(w.events, ','))`, orderID, event)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue webhooks: %w", err)
+	}
+	return nil
+}
+
+// enqueueWithdrawalWebhooks - outbox доставки события списания, в транзакции списания
+func enqueueWithdrawalWebhooks(ctx context.Context, tx execer, userID int, withdraw models.WithdrawBalance) error {
+	_, err := tx.ExecContext(ctx, `
+        INSERT INTO webhook_deliveries (webhook_id, event, payload)
+        SELECT id, $2, jsonb_build_object(
+            'event', $2::text,
+            'order', $3::text,
+            'sum', $4::numeric,
+            'processed_at', NOW()
+        )
+        FROM webhooks
+        WHERE user_id = $1 AND active AND $2 = ANY(string_to_array(events, ','))`,
+		userID, models.WebhookEventWithdrawalCreated, withdraw.Order, withdraw.Sum)
+	if err != nil {
+		return fmt.Errorf("failed to enqueue webhooks: %w", err)
+	}
+	return nil
+}
+
+// CreateWebhook - регистрирует вебхук и генерирует секрет подписи
+func (ps *PostgresStorage) CreateWebhook(userID int, url string, events []string) (*models.Webhook, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return nil, fmt.Errorf("failed to generate webhook secret: %w", err)
+	}
+
+	webhook := &models.Webhook{
+		UserID: userID,
+		URL:    url,
+		Secret: hex.EncodeToString(secret),
+		Events: events,
+		Active: true,
+	}
+	err := ps.DB.QueryRow(`
+        INSERT INTO webhooks (user_id, url, secret, events)
+        VALUES ($1, $2, $3, $4)
+        RETURNING id, created_at`, userID, url, webhook.Secret, strings.Join(events, ",")).
+		Scan(&webhook.ID, &webhook.CreatedAt)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create webhook: %w", err)
+	}
+	return webhook, nil
+}
+
+// Webhooks - вебхуки пользователя без секретов
+func (ps *PostgresStorage) Webhooks(userID int) ([]models.Webhook, error) {
+	rows, err := ps.DB.Query(`
+        SELECT id, url, events, active, created_at
+        FROM webhooks
+        WHERE user_id = $1
+        ORDER BY id`, userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhooks: %w", err)
+	}
+	defer rows.Close()
+
+	webhooks := []models.Webhook{}
+	for rows.Next() {
+		webhook := models.Webhook{UserID: userID}
+		var events string
+		if err := rows.Scan(&webhook.ID, &webhook.URL, &events, &webhook.Active, &webhook.CreatedAt); err != nil {
+			return nil, err
+		}
+		webhook.Events = strings.Split(events, ",")
+		webhooks = append(webhooks, webhook)
+	}
+
+	return webhooks, rows.Err()
+}
+
+// DeleteWebhook - удаляет вебхук пользователя вместе с журналом доставок
+func (ps *PostgresStorage) DeleteWebhook(userID, webhookID int) error {
+	res, err := ps.DB.Exec(`DELETE FROM webhooks WHERE id = $1 AND user_id = $2`, webhookID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete webhook: %w", err)
+	}
+
+	n, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return handler.ErrWebhookNotFound
+	}
+	return nil
+}
+
+// WebhookDeliveries - последние доставки вебхука пользователя, сначала новые
+func (ps *PostgresStorage) WebhookDeliveries(userID, webhookID, limit int) ([]models.WebhookDelivery, error) {
+	var exists bool
+	err := ps.DB.QueryRow(`SELECT EXISTS (SELECT 1 FROM webhooks WHERE id = $1 AND user_id = $2)`, webhookID, userID).
+		Scan(&exists)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook: %w", err)
+	}
+	if !exists {
+		return nil, handler.ErrWebhookNotFound
+	}
+
+	rows, err := ps.DB.Query(`
+        SELECT id, webhook_id, event, payload, status, attempts, last_status_code, last_error, created_at, delivered_at
+        FROM webhook_deliveries
+        WHERE webhook_id = $1
+        ORDER BY id DESC
+        LIMIT $2`, webhookID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	deliveries := []models.WebhookDelivery{}
+	for rows.Next() {
+		var d models.WebhookDelivery
+		var payload []byte
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.Event, &payload, &d.Status, &d.Attempts,
+			&d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.DeliveredAt); err != nil {
+			return nil, err
+		}
+		d.Payload = payload
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
@@ -2,9 +2,26 @@
 package service
 
 import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
 	"errors"
 	"fmt"
+	"go-musthave-diploma-tpl/internal/gophermart/accrualclient"
+	"go-musthave-diploma-tpl/internal/gophermart/breaker"
+	"go-musthave-diploma-tpl/internal/gophermart/events"
+	"go-musthave-diploma-tpl/internal/gophermart/fraud"
+	"go-musthave-diploma-tpl/internal/gophermart/metrics"
 	"go-musthave-diploma-tpl/internal/gophermart/models"
+	"go-musthave-diploma-tpl/internal/gophermart/notify"
+	"go-musthave-diploma-tpl/internal/gophermart/ratelimit"
+	pgk "go-musthave-diploma-tpl/pkg"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
 )
 
 // GofemartRepo - интерфейс репозитория
@@ -13,33 +30,192 @@ type GofemartRepo interface {
 	GetUserByLoginAndPassword(login, password string) (*models.User, error)
 	// создание пользователя
 	CreateUser(login, password string) (*models.User, error)
+	// создание пользователя по реферальному коду
+	CreateReferredUser(login, password, code string) (*models.User, error)
+	// реферальная статистика пользователя
+	ReferralStats(userID int) (*models.ReferralStats, error)
 	// получаем пользователя по ID
 	GetUserByID(id int) (*models.User, error)
+	// логин и почта пользователя для исходящих сообщений
+	GetUserContact(userID int) (*models.User, error)
+	// новая почта и код её подтверждения
+	SetUserEmail(userID int, email, tokenHash string, expiresAt time.Time) error
+	// подтверждение почты кодом
+	VerifyEmail(userID int, tokenHash string) (*models.User, error)
+	// удаление почты
+	RemoveUserEmail(userID int) error
+	// профиль пользователя со статистикой, помесячно начиная с since
+	Profile(userID int, since time.Time) (*models.Profile, error)
 	// создание и проверка заказа
 	CreateOrder(userID int, orderNumber string) error
+	// пакетная загрузка заказов
+	CreateOrders(userID int, numbers []string) (map[string]string, error)
+	// смена статуса заказа по номеру
+	UpdateOrderStatusByNumber(ctx context.Context, number, status string, accrual float64) error
 	// получение заказов по пользвователю
 	GetOrders(userID int) ([]models.Order, error)
+	// постраничное получение заказов с фильтрами
+	GetOrdersPage(userID int, params models.ListParams) ([]models.Order, string, error)
+	// получение заказа пользователя с историей статусов
+	GetOrder(userID int, number string) (*models.OrderDetails, error)
 	// получение баланса
 	GetBalance(userID int) (models.Balance, error)
 	// запрос на списание средств
 	Withdraw(userID int, withdraw models.WithdrawBalance) error
+	// история движений по счёту
+	BalanceHistory(userID, limit int) ([]models.BalanceEntry, error)
 	// получение списка информации о выводе средств
 	Withdrawals(userID int) ([]models.WithdrawBalance, error)
+	// постраничное получение списаний с фильтрами
+	WithdrawalsPage(userID int, params models.ListParams) ([]models.WithdrawBalance, string, error)
+	// создание семьи с общим кошельком
+	CreateHousehold(ownerID int, name string) (*models.Household, error)
+	// семья пользователя с участниками и балансом
+	Household(userID int) (*models.Household, error)
+	// баланс общего кошелька семьи пользователя
+	HouseholdBalance(userID int) (models.Balance, error)
+	// приглашение пользователя в семью
+	InviteToHousehold(ownerID int, login string) (*models.HouseholdInvite, error)
+	// приглашения пользователя, ожидающие ответа
+	HouseholdInvites(userID int) ([]models.HouseholdInvite, error)
+	// принятие или отклонение приглашения
+	RespondHouseholdInvite(userID, inviteID int, accept bool) (*models.HouseholdInvite, error)
+	// права участника на общий кошелёк
+	UpdateHouseholdMember(ownerID, memberID int, perms models.MemberPermissions) (*models.HouseholdMember, error)
+	// исключение участника или выход из семьи
+	RemoveHouseholdMember(actorID, memberID int) error
+	// зачисление начислений участника в общий кошелёк
+	SetAccrualSharing(userID int, share bool) error
+	// списание из общего кошелька
+	WithdrawFromHousehold(userID int, withdraw models.WithdrawBalance) error
+	// списания из общего кошелька
+	HouseholdWithdrawals(userID, limit int) ([]models.HouseholdWithdrawal, error)
+	// ручная корректировка баланса администратором
+	CreateAdjustment(adminID, userID int, amount float64, reason string) (*models.BalanceAdjustment, error)
+	// корректировки баланса пользователя
+	Adjustments(userID, limit int) ([]models.BalanceAdjustment, error)
+	// флаги антифрода
+	FraudFlags(filter models.FraudFlagFilter) ([]models.FraudFlag, string, error)
+	// решение администратора по флагу антифрода
+	ReviewFraudFlag(flagID int64, adminID int, review models.FraudReview) (*models.FraudFlag, error)
+	// создание промокода администратором
+	CreatePromoCode(adminID int, promo models.PromoCode) (*models.PromoCode, error)
+	// все промокоды
+	PromoCodes() ([]models.PromoCode, error)
+	// погашение промокода с начислением баллов
+	RedeemPromoCode(userID int, code string) (*models.PromoRedemption, error)
+	// заказы в очереди недоставленных
+	DeadLetters() ([]models.DeadLetter, error)
+	// возврат заказа из очереди недоставленных в обработку
+	RequeueDeadLetter(number string) error
+	// возврат всех недоставленных заказов в обработку
+	RequeueAllDeadLetters() (int64, error)
+	// размер очереди недоставленных
+	DeadLetterCount() (int64, error)
+	// входящие уведомления и число непрочитанных
+	Notifications(userID int, unreadOnly bool, limit int) (*models.Notifications, error)
+	// отметка уведомлений прочитанными, возвращает число оставшихся непрочитанных
+	MarkNotificationsRead(userID int, ids []int64) (int, error)
+	// события пользователя после указанного id
+	UserEventsSince(userID int, afterID int64, limit int) ([]models.UserEvent, error)
+	// регистрация вебхука
+	CreateWebhook(userID int, url string, events []string) (*models.Webhook, error)
+	// вебхуки пользователя
+	Webhooks(userID int) ([]models.Webhook, error)
+	// удаление вебхука
+	DeleteWebhook(userID, webhookID int) error
+	// журнал доставок вебхука
+	WebhookDeliveries(userID, webhookID, limit int) ([]models.WebhookDelivery, error)
+}
+
+// AuditLog - журнал аудита, хранится отдельно от основного репозитория
+type AuditLog interface {
+	RecordAudit(ctx context.Context, event models.AuditEvent) error
+	AuditEvents(filter models.AuditFilter) ([]models.AuditEvent, string, error)
+}
+
+// LeaderReporter - какой инстанс сейчас обрабатывает заказы
+type LeaderReporter interface {
+	InstanceID() string
+	LeaderID() string
 }
 
 // GofemartService - сервис с бизнес-логикой
 type GofemartService struct {
 	repo             GofemartRepo
 	accrualSystemURL string
+	accrualBreaker   *breaker.Breaker
+	leader           LeaderReporter
+	adminLogins      map[string]struct{}
+	events           *events.Broker
+	// синхронный запрос в accrual при загрузке заказа, выключен при nil
+	syncAccrual        accrualclient.Client
+	syncAccrualTimeout time.Duration
+	// проверка номеров заказов, по умолчанию Луна
+	orderValidator pgk.Validator
+	// попытки погасить неизвестный промокод по пользователю, считаются в памяти инстанса
+	promoLimiter *ratelimit.Limiter
+	audit        AuditLog
+	// правила антифрода при загрузке заказов и списании, выключены при nil
+	fraud *fraud.Engine
+	// вебхуки на loopback и в частные сети, только для локальной разработки
+	webhookAllowPrivate bool
+	logger              *zap.SugaredLogger
+	// исходящие сообщения на почту, выключены при nil
+	notifier notify.Notifier
+	// списания от этой суммы сопровождаются письмом, 0 - без писем
+	largeWithdrawal float64
+	emailTokenTTL   time.Duration
 }
 
+// syncAccrualWriteTimeout - сохранение результата, полученного при загрузке заказа
+const syncAccrualWriteTimeout = 5 * time.Second
+
 func NewGofemartService(repo GofemartRepo, accrualURL string) *GofemartService {
 	return &GofemartService{
 		repo:             repo,
+		logger:           zap.NewNop().Sugar(),
 		accrualSystemURL: accrualURL,
+		orderValidator:   pgk.LuhnValidator,
+		promoLimiter:     ratelimit.New(ratelimit.Config{}),
+		emailTokenTTL:    24 * time.Hour,
 	}
 }
 
+// SetLogger - журнал фоновых операций сервиса
+func (s *GofemartService) SetLogger(logger *zap.SugaredLogger) {
+	s.logger = logger
+}
+
+// SetAccrualBreaker - circuit breaker системы начислений, состояние которого отдаётся в Health
+func (s *GofemartService) SetAccrualBreaker(b *breaker.Breaker) {
+	s.accrualBreaker = b
+}
+
+// SetLeaderReporter - источник информации о лидере обработки заказов
+func (s *GofemartService) SetLeaderReporter(r LeaderReporter) {
+	s.leader = r
+}
+
+// Health - состояние сервиса и его зависимостей
+func (s *GofemartService) Health() models.Health {
+	health := models.Health{Status: models.HealthStatusOK}
+
+	if s.leader != nil {
+		health.Instance = s.leader.InstanceID()
+		health.Leader = s.leader.LeaderID()
+	}
+
+	if s.accrualBreaker != nil {
+		state := s.accrualBreaker.State()
+		health.AccrualBreaker = state.String()
+		if state != breaker.StateClosed {
+			health.Status = models.HealthStatusDegraded
+		}
+	}
+	return health
+}
+
 func (s *GofemartService) RegisterUser(login, password string) (*models.User, error) {
 	if login == "" || password == "" {
 		return nil, errors.New("login and password are required")
@@ -53,6 +229,26 @@ func (s *GofemartService) RegisterUser(login, password string) (*models.User, er
 	return user, nil
 }
 
+// RegisterReferredUser - регистрация с кодом пригласившего, пустой код - обычная регистрация
+func (s *GofemartService) RegisterReferredUser(login, password, code string) (*models.User, error) {
+	code = strings.TrimSpace(code)
+	if code == "" {
+		return s.RegisterUser(login, password)
+	}
+	if login == "" || password == "" {
+		return nil, errors.New("login and password are required")
+	}
+	return s.repo.CreateReferredUser(login, password, code)
+}
+
+// ReferralStats - реферальный код и приглашённые пользователи
+func (s *GofemartService) ReferralStats(userID int) (*models.ReferralStats, error) {
+	if userID <= 0 {
+		return nil, fmt.Errorf("invalid user ID")
+	}
+	return s.repo.ReferralStats(userID)
+}
+
 func (s *GofemartService) LoginUser(login, password string) (*models.User, error) {
 	if login == "" || password == "" {
 		return nil, fmt.Errorf("login and password are required")
@@ -64,7 +260,7 @@ func (s *GofemartService) LoginUser(login, password string) (*models.User, error
 	}
 
 	if user == nil {
-		return nil, fmt.Errorf("invalid login or password")
+		return nil, models.ErrInvalidLoginOrPassword
 	}
 
 	return user, nil
@@ -87,7 +283,141 @@ func (s *GofemartService) CreateOrder(userID int, orderNumber string) error {
 		return fmt.Errorf("order number is required")
 	}
 
-	return s.repo.CreateOrder(userID, orderNumber)
+	if err := s.checkFraud(models.FraudEventOrderUpload, userID, 1, 0); err != nil {
+		return err
+	}
+
+	if err := s.repo.CreateOrder(userID, orderNumber); err != nil {
+		return err
+	}
+
+	s.lookupAccrual(orderNumber)
+	return nil
+}
+
+// SetOrderValidator - схема проверки номеров заказов для загрузки и списания
+func (s *GofemartService) SetOrderValidator(v pgk.Validator) {
+	s.orderValidator = v
+}
+
+// ValidateOrderNumber - номер заказа проходит проверку выбранной схемой
+func (s *GofemartService) ValidateOrderNumber(number string) bool {
+	return number != "" && s.orderValidator.Validate(number)
+}
+
+// SetSyncAccrual - включает короткий запрос в accrual сразу после загрузки заказа.
+// client nil - клиент к accrualSystemURL; timeout <= 0 выключает запрос
+func (s *GofemartService) SetSyncAccrual(client accrualclient.Client, timeout time.Duration) {
+	if timeout <= 0 {
+		s.syncAccrual = nil
+		return
+	}
+	if client == nil {
+		client = accrualclient.New(s.accrualSystemURL)
+	}
+	s.syncAccrual = client
+	s.syncAccrualTimeout = timeout
+}
+
+// lookupAccrual - если accrual уже знает финальный результат, заказ сразу сохраняется в нём.
+// Любая ошибка или таймаут оставляют заказ в NEW, его обработает listener
+func (s *GofemartService) lookupAccrual(number string) {
+	if s.syncAccrual == nil {
+		return
+	}
+	if s.accrualBreaker != nil && s.accrualBreaker.Allow() != nil {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.syncAccrualTimeout)
+	info, err := s.syncAccrual.GetOrder(ctx, number)
+	timedOut := ctx.Err() != nil
+	cancel()
+
+	// о каждом исходе сообщаем автомату, иначе пробный запрос в half-open
+	// не освободится и listener больше не получит доступа к accrual
+	if s.accrualBreaker != nil {
+		switch {
+		case timedOut:
+			// таймаут на нашей стороне - не признак недоступности accrual
+			s.accrualBreaker.Release()
+		case accrualclient.IsUnavailable(err):
+			s.accrualBreaker.Failure()
+		default:
+			// accrual ответил, в том числе 204 и 429
+			s.accrualBreaker.Success()
+		}
+	}
+	if err != nil {
+		return
+	}
+
+	status, err := models.OrderStatusFromAccrual(info.Status)
+	if err != nil || !models.IsFinalOrderStatus(status) {
+		return
+	}
+
+	// контекст запроса к accrual уже мог истечь
+	dbCtx, dbCancel := context.WithTimeout(context.Background(), syncAccrualWriteTimeout)
+	defer dbCancel()
+	if err := s.repo.UpdateOrderStatusByNumber(dbCtx, number, status, info.Accrual); err != nil {
+		s.logger.Warnf("failed to save accrual result for order %s: %v", number, err)
+	}
+}
+
+// CreateOrders - пакетная загрузка заказов.
+// Результаты возвращаются в порядке номеров; невалидные номера и повторы внутри пакета в базу не попадают
+func (s *GofemartService) CreateOrders(userID int, numbers []string) ([]models.BatchOrderResult, error) {
+	if userID <= 0 {
+		return nil, fmt.Errorf("invalid user ID")
+	}
+	if len(numbers) == 0 {
+		return nil, models.ErrEmptyBatch
+	}
+	if len(numbers) > models.MaxBatchSize {
+		return nil, fmt.Errorf("%w: at most %d numbers", models.ErrBatchTooLarge, models.MaxBatchSize)
+	}
+
+	results := make([]models.BatchOrderResult, len(numbers))
+	seen := make(map[string]bool, len(numbers))
+	var valid []string
+	for i, number := range numbers {
+		results[i].Number = number
+		switch {
+		case !s.ValidateOrderNumber(number):
+			results[i].Result = models.BatchResultInvalid
+		case seen[number]:
+			results[i].Result = models.BatchResultDuplicate
+		default:
+			seen[number] = true
+			valid = append(valid, number)
+		}
+	}
+
+	if len(valid) == 0 {
+		return results, nil
+	}
+
+	if err := s.checkFraud(models.FraudEventOrderUpload, userID, len(valid), 0); err != nil {
+		return nil, err
+	}
+
+	created, err := s.repo.CreateOrders(userID, valid)
+	if err != nil {
+		return nil, err
+	}
+
+	for i := range results {
+		if results[i].Result != "" {
+			continue
+		}
+		result, ok := created[results[i].Number]
+		if !ok {
+			return nil, fmt.Errorf("no result for order %s", results[i].Number)
+		}
+		results[i].Result = result
+	}
+	return results, nil
 }
 
 func (s *GofemartService) GetOrders(userID int) ([]models.Order, error) {
@@ -97,6 +427,32 @@ func (s *GofemartService) GetOrders(userID int) ([]models.Order, error) {
 	return s.repo.GetOrders(userID)
 }
 
+// GetOrdersPage - страница заказов и курсор следующей, пустой если страница последняя
+func (s *GofemartService) GetOrdersPage(userID int, params models.ListParams) ([]models.Order, string, error) {
+	if userID <= 0 {
+		return nil, "", fmt.Errorf("invalid user ID")
+	}
+	for _, status := range params.Statuses {
+		if !models.IsKnownOrderStatus(status) {
+			return nil, "", fmt.Errorf("%w: unknown status %q", models.ErrInvalidListParams, status)
+		}
+	}
+	if err := normalizeListParams(&params); err != nil {
+		return nil, "", err
+	}
+	return s.repo.GetOrdersPage(userID, params)
+}
+
+func (s *GofemartService) GetOrder(userID int, number string) (*models.OrderDetails, error) {
+	if userID <= 0 {
+		return nil, fmt.Errorf("invalid user ID")
+	}
+	if number == "" {
+		return nil, fmt.Errorf("order number is required")
+	}
+	return s.repo.GetOrder(userID, number)
+}
+
 func (s *GofemartService) GetBalance(userID int) (models.Balance, error) {
 	if userID <= 0 {
 		return models.Balance{}, fmt.Errorf("invalid user ID")
@@ -105,9 +461,572 @@ func (s *GofemartService) GetBalance(userID int) (models.Balance, error) {
 }
 
 func (s *GofemartService) Withdraw(userID int, withdraw models.WithdrawBalance) error {
+	if err := s.checkFraud(models.FraudEventWithdrawal, userID, 1, withdraw.Sum); err != nil {
+		return err
+	}
 	return s.repo.Withdraw(userID, withdraw)
 }
 
+// BalanceHistory - последние движения по счёту, limit ограничен MaxPageLimit
+func (s *GofemartService) BalanceHistory(userID, limit int) ([]models.BalanceEntry, error) {
+	if userID <= 0 {
+		return nil, fmt.Errorf("invalid user ID")
+	}
+	if limit <= 0 {
+		limit = models.DefaultPageLimit
+	}
+	if limit > models.MaxPageLimit {
+		limit = models.MaxPageLimit
+	}
+	return s.repo.BalanceHistory(userID, limit)
+}
+
 func (s *GofemartService) Withdrawals(userID int) ([]models.WithdrawBalance, error) {
 	return s.repo.Withdrawals(userID)
 }
+
+// WithdrawalsPage - страница списаний и курсор следующей, пустой если страница последняя
+func (s *GofemartService) WithdrawalsPage(userID int, params models.ListParams) ([]models.WithdrawBalance, string, error) {
+	if userID <= 0 {
+		return nil, "", fmt.Errorf("invalid user ID")
+	}
+	if len(params.Statuses) > 0 {
+		return nil, "", fmt.Errorf("%w: withdrawals have no status", models.ErrInvalidListParams)
+	}
+	if err := normalizeListParams(&params); err != nil {
+		return nil, "", err
+	}
+	return s.repo.WithdrawalsPage(userID, params)
+}
+
+func normalizeListParams(params *models.ListParams) error {
+	if params.Limit == 0 {
+		params.Limit = models.DefaultPageLimit
+	}
+	if params.Limit < 0 || params.Limit > models.MaxPageLimit {
+		return fmt.Errorf("%w: limit must be between 1 and %d", models.ErrInvalidListParams, models.MaxPageLimit)
+	}
+	if params.From != nil && params.To != nil && !params.From.Before(*params.To) {
+		return fmt.Errorf("%w: from must be before to", models.ErrInvalidListParams)
+	}
+	if params.Cursor != "" {
+		if _, _, err := models.DecodeCursor(params.Cursor); err != nil {
+			return fmt.Errorf("%w: %v", models.ErrInvalidListParams, err)
+		}
+	}
+	return nil
+}
+
+// SetAdminLogins - логины пользователей с доступом к /api/admin
+func (s *GofemartService) SetAdminLogins(logins []string) {
+	s.adminLogins = make(map[string]struct{}, len(logins))
+	for _, login := range logins {
+		s.adminLogins[login] = struct{}{}
+	}
+}
+
+// IsAdmin - является ли пользователь администратором
+func (s *GofemartService) IsAdmin(userID int) bool {
+	if len(s.adminLogins) == 0 {
+		return false
+	}
+
+	user, err := s.GetUserByID(userID)
+	if err != nil || user == nil {
+		return false
+	}
+
+	_, ok := s.adminLogins[user.Login]
+	return ok
+}
+
+func (s *GofemartService) DeadLetters() ([]models.DeadLetter, error) {
+	return s.repo.DeadLetters()
+}
+
+func (s *GofemartService) RequeueDeadLetter(number string) error {
+	if number == "" {
+		return fmt.Errorf("order number is required")
+	}
+
+	if err := s.repo.RequeueDeadLetter(number); err != nil {
+		return err
+	}
+	s.refreshDeadLetterMetrics()
+	return nil
+}
+
+func (s *GofemartService) RequeueAllDeadLetters() (int64, error) {
+	n, err := s.repo.RequeueAllDeadLetters()
+	if err != nil {
+		return 0, err
+	}
+	s.refreshDeadLetterMetrics()
+	return n, nil
+}
+
+func (s *GofemartService) refreshDeadLetterMetrics() {
+	if n, err := s.repo.DeadLetterCount(); err == nil {
+		metrics.DeadLetterQueueSize.Set(n)
+	}
+}
+
+// SetEventBroker - источник событий для SSE
+func (s *GofemartService) SetEventBroker(b *events.Broker) {
+	s.events = b
+}
+
+// SubscribeEvents - подписка на события пользователя
+func (s *GofemartService) SubscribeEvents(userID int) (*events.Subscription, error) {
+	if userID <= 0 {
+		return nil, fmt.Errorf("invalid user ID")
+	}
+	if s.events == nil {
+		return nil, models.ErrEventsUnavailable
+	}
+	return s.events.Subscribe(userID), nil
+}
+
+// UserEventsSince - пропущенные события для возобновления по Last-Event-ID
+func (s *GofemartService) UserEventsSince(userID int, afterID int64, limit int) ([]models.UserEvent, error) {
+	if userID <= 0 {
+		return nil, fmt.Errorf("invalid user ID")
+	}
+	return s.repo.UserEventsSince(userID, afterID, limit)
+}
+
+// CreateWebhook - регистрация вебхука, секрет подписи возвращается только здесь
+func (s *GofemartService) CreateWebhook(userID int, url string, events []string) (*models.Webhook, error) {
+	if userID <= 0 {
+		return nil, fmt.Errorf("invalid user ID")
+	}
+	if err := models.ValidateWebhook(url, events); err != nil {
+		return nil, err
+	}
+	if !s.webhookAllowPrivate {
+		if err := models.ValidateWebhookTarget(url); err != nil {
+			return nil, err
+		}
+	}
+	return s.repo.CreateWebhook(userID, url, events)
+}
+
+func (s *GofemartService) Webhooks(userID int) ([]models.Webhook, error) {
+	if userID <= 0 {
+		return nil, fmt.Errorf("invalid user ID")
+	}
+	return s.repo.Webhooks(userID)
+}
+
+func (s *GofemartService) DeleteWebhook(userID, webhookID int) error {
+	if userID <= 0 {
+		return fmt.Errorf("invalid user ID")
+	}
+	return s.repo.DeleteWebhook(userID, webhookID)
+}
+
+// WebhookDeliveries - последние доставки вебхука, limit ограничен MaxPageLimit
+func (s *GofemartService) WebhookDeliveries(userID, webhookID, limit int) ([]models.WebhookDelivery, error) {
+	if userID <= 0 {
+		return nil, fmt.Errorf("invalid user ID")
+	}
+	if limit <= 0 {
+		limit = models.DefaultPageLimit
+	}
+	if limit > models.MaxPageLimit {
+		limit = models.MaxPageLimit
+	}
+	return s.repo.WebhookDeliveries(userID, webhookID, limit)
+}
+
+// SetPromoLimiter - ограничение перебора промокодов
+func (s *GofemartService) SetPromoLimiter(l *ratelimit.Limiter) {
+	s.promoLimiter = l
+}
+
+// CreatePromoCode - новый промокод кампании
+func (s *GofemartService) CreatePromoCode(adminID int, promo models.PromoCode) (*models.PromoCode, error) {
+	promo.Code = models.NormalizePromoCode(promo.Code)
+	if err := models.ValidatePromoCode(promo); err != nil {
+		return nil, err
+	}
+	return s.repo.CreatePromoCode(adminID, promo)
+}
+
+func (s *GofemartService) PromoCodes() ([]models.PromoCode, error) {
+	return s.repo.PromoCodes()
+}
+
+// RedeemPromoCode - погашение промокода. Неудачные попытки считаются,
+// после исчерпания лимита запросы отклоняются до конца окна
+func (s *GofemartService) RedeemPromoCode(userID int, code string) (*models.PromoRedemption, error) {
+	if userID <= 0 {
+		return nil, fmt.Errorf("invalid user ID")
+	}
+
+	code = models.NormalizePromoCode(code)
+	if code == "" || len(code) > models.MaxPromoCodeLength {
+		return nil, models.ErrInvalidPromoCode
+	}
+
+	// попытка учитывается до запроса в базу, иначе параллельные подборы обходят лимит.
+	// В лимите остаются только неизвестные коды: успех, истёкший код или сбой базы не считаются
+	release, ok := s.promoLimiter.Reserve(strconv.Itoa(userID))
+	if !ok {
+		return nil, models.ErrTooManyRedeemAttempts
+	}
+
+	redemption, err := s.repo.RedeemPromoCode(userID, code)
+	if !errors.Is(err, models.ErrPromoCodeNotFound) {
+		release()
+	}
+	if err != nil {
+		return nil, err
+	}
+	return redemption, nil
+}
+
+// AdjustBalance - ручное начисление или списание баллов с обязательной причиной
+func (s *GofemartService) AdjustBalance(adminID, userID int, req models.AdjustmentRequest) (*models.BalanceAdjustment, error) {
+	if adminID <= 0 || userID <= 0 {
+		return nil, fmt.Errorf("invalid user ID")
+	}
+	amount, err := models.ValidateAdjustment(req)
+	if err != nil {
+		return nil, err
+	}
+	return s.repo.CreateAdjustment(adminID, userID, amount, strings.TrimSpace(req.Reason))
+}
+
+// Adjustments - корректировки баланса пользователя, limit ограничен MaxPageLimit
+func (s *GofemartService) Adjustments(userID, limit int) ([]models.BalanceAdjustment, error) {
+	if userID <= 0 {
+		return nil, fmt.Errorf("invalid user ID")
+	}
+	if limit <= 0 {
+		limit = models.DefaultPageLimit
+	}
+	if limit > models.MaxPageLimit {
+		limit = models.MaxPageLimit
+	}
+	return s.repo.Adjustments(userID, limit)
+}
+
+// SetAuditLog - журнал аудита, без него записи не сохраняются
+func (s *GofemartService) SetAuditLog(a AuditLog) {
+	s.audit = a
+}
+
+// RecordAudit - запись действия в журнал аудита
+func (s *GofemartService) RecordAudit(ctx context.Context, event models.AuditEvent) error {
+	if s.audit == nil {
+		return nil
+	}
+	return s.audit.RecordAudit(ctx, event)
+}
+
+// AuditEvents - страница журнала аудита и курсор следующей
+func (s *GofemartService) AuditEvents(filter models.AuditFilter) ([]models.AuditEvent, string, error) {
+	if s.audit == nil {
+		return nil, "", models.ErrAuditUnavailable
+	}
+	if len(filter.Statuses) > 0 {
+		return nil, "", fmt.Errorf("%w: audit events have no status", models.ErrInvalidListParams)
+	}
+	if err := normalizeListParams(&filter.ListParams); err != nil {
+		return nil, "", err
+	}
+	return s.audit.AuditEvents(filter)
+}
+
+// SetWebhookAllowPrivate - разрешить регистрацию вебхуков на адреса вне интернета
+func (s *GofemartService) SetWebhookAllowPrivate(allow bool) {
+	s.webhookAllowPrivate = allow
+}
+
+// SetFraudEngine - правила антифрода
+func (s *GofemartService) SetFraudEngine(e *fraud.Engine) {
+	s.fraud = e
+}
+
+// checkFraud - ErrActionBlocked или FraudDelayError, если действие не пропускают правила
+func (s *GofemartService) checkFraud(event string, userID, count int, amount float64) error {
+	if s.fraud == nil {
+		return nil
+	}
+	decision := s.fraud.Evaluate(context.Background(), fraud.Input{
+		Event:  event,
+		UserID: userID,
+		Count:  count,
+		Amount: amount,
+	})
+	return decision.Err()
+}
+
+// FraudFlags - страница флагов антифрода и курсор следующей
+func (s *GofemartService) FraudFlags(filter models.FraudFlagFilter) ([]models.FraudFlag, string, error) {
+	for _, status := range filter.Statuses {
+		if status != models.FraudFlagOpen && status != models.FraudFlagDismissed && status != models.FraudFlagConfirmed {
+			return nil, "", fmt.Errorf("%w: unknown status %s", models.ErrInvalidListParams, status)
+		}
+	}
+	if err := normalizeListParams(&filter.ListParams); err != nil {
+		return nil, "", err
+	}
+	return s.repo.FraudFlags(filter)
+}
+
+// ReviewFraudFlag - закрытие флага администратором
+func (s *GofemartService) ReviewFraudFlag(flagID int64, adminID int, review models.FraudReview) (*models.FraudFlag, error) {
+	if err := models.ValidateFraudReview(review); err != nil {
+		return nil, err
+	}
+	review.Note = strings.TrimSpace(review.Note)
+	return s.repo.ReviewFraudFlag(flagID, adminID, review)
+}
+
+// CreateHousehold - создание семьи, создатель становится владельцем
+func (s *GofemartService) CreateHousehold(ownerID int, req models.HouseholdRequest) (*models.Household, error) {
+	if ownerID <= 0 {
+		return nil, fmt.Errorf("invalid user ID")
+	}
+	if err := models.ValidateHouseholdName(req.Name); err != nil {
+		return nil, err
+	}
+	return s.repo.CreateHousehold(ownerID, strings.TrimSpace(req.Name))
+}
+
+// Household - семья пользователя с участниками и балансом общего кошелька
+func (s *GofemartService) Household(userID int) (*models.Household, error) {
+	return s.repo.Household(userID)
+}
+
+// WalletBalance - баланс личного или общего кошелька
+func (s *GofemartService) WalletBalance(userID int, wallet string) (models.Balance, error) {
+	if wallet == models.WalletHousehold {
+		return s.repo.HouseholdBalance(userID)
+	}
+	return s.GetBalance(userID)
+}
+
+// InviteToHousehold - приглашение пользователя по логину
+func (s *GofemartService) InviteToHousehold(ownerID int, req models.InviteRequest) (*models.HouseholdInvite, error) {
+	login := strings.TrimSpace(req.Login)
+	if login == "" {
+		return nil, fmt.Errorf("%w: login is required", models.ErrInvalidHousehold)
+	}
+	return s.repo.InviteToHousehold(ownerID, login)
+}
+
+// HouseholdInvites - приглашения пользователя, ожидающие ответа
+func (s *GofemartService) HouseholdInvites(userID int) ([]models.HouseholdInvite, error) {
+	return s.repo.HouseholdInvites(userID)
+}
+
+// RespondHouseholdInvite - принятие или отклонение приглашения
+func (s *GofemartService) RespondHouseholdInvite(userID, inviteID int, accept bool) (*models.HouseholdInvite, error) {
+	return s.repo.RespondHouseholdInvite(userID, inviteID, accept)
+}
+
+// UpdateHouseholdMember - права участника на списание из общего кошелька
+func (s *GofemartService) UpdateHouseholdMember(ownerID, memberID int, perms models.MemberPermissions) (*models.HouseholdMember, error) {
+	if err := models.ValidateMemberPermissions(perms); err != nil {
+		return nil, err
+	}
+	return s.repo.UpdateHouseholdMember(ownerID, memberID, perms)
+}
+
+// RemoveHouseholdMember - исключение участника владельцем или выход из семьи
+func (s *GofemartService) RemoveHouseholdMember(actorID, memberID int) error {
+	return s.repo.RemoveHouseholdMember(actorID, memberID)
+}
+
+// SetAccrualSharing - зачислять ли будущие начисления в общий кошелёк
+func (s *GofemartService) SetAccrualSharing(userID int, share bool) error {
+	return s.repo.SetAccrualSharing(userID, share)
+}
+
+// WithdrawFrom - списание из личного или общего кошелька
+func (s *GofemartService) WithdrawFrom(userID int, wallet string, withdraw models.WithdrawBalance) error {
+	if wallet != models.WalletHousehold {
+		return s.Withdraw(userID, withdraw)
+	}
+	if err := s.checkFraud(models.FraudEventWithdrawal, userID, 1, withdraw.Sum); err != nil {
+		return err
+	}
+	return s.repo.WithdrawFromHousehold(userID, withdraw)
+}
+
+// HouseholdWithdrawals - списания из общего кошелька, limit ограничен MaxPageLimit
+func (s *GofemartService) HouseholdWithdrawals(userID, limit int) ([]models.HouseholdWithdrawal, error) {
+	if limit <= 0 {
+		limit = models.DefaultPageLimit
+	}
+	if limit > models.MaxPageLimit {
+		limit = models.MaxPageLimit
+	}
+	return s.repo.HouseholdWithdrawals(userID, limit)
+}
+
+// Profile - профиль пользователя и статистика за последние months месяцев, включая текущий.
+// Месяцы без движений и статусы без заказов заполняются нулями, чтобы клиенту не приходилось их достраивать
+func (s *GofemartService) Profile(userID, months int) (*models.Profile, error) {
+	if userID <= 0 {
+		return nil, fmt.Errorf("invalid user ID")
+	}
+	if months == 0 {
+		months = models.DefaultProfileMonths
+	}
+	if months < 0 || months > models.MaxProfileMonths {
+		return nil, fmt.Errorf("%w: must be between 1 and %d", models.ErrInvalidProfileMonths, models.MaxProfileMonths)
+	}
+
+	now := time.Now().UTC()
+	since := time.Date(now.Year(), now.Month()-time.Month(months-1), 1, 0, 0, 0, 0, time.UTC)
+	profile, err := s.repo.Profile(userID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, status := range []string{models.OrderStatusNew, models.OrderStatusProcessing, models.OrderStatusInvalid, models.OrderStatusProcessed} {
+		if _, ok := profile.Orders[status]; !ok {
+			profile.Orders[status] = 0
+		}
+	}
+
+	byMonth := make(map[string]models.MonthlyAccrual, len(profile.Monthly))
+	for _, m := range profile.Monthly {
+		byMonth[m.Month] = m
+	}
+	monthly := make([]models.MonthlyAccrual, 0, months)
+	for i := 0; i < months; i++ {
+		month := since.AddDate(0, i, 0).Format(models.ProfileMonthFormat)
+		m, ok := byMonth[month]
+		if !ok {
+			m = models.MonthlyAccrual{Month: month}
+		}
+		monthly = append(monthly, m)
+	}
+	profile.Monthly = monthly
+
+	return profile, nil
+}
+
+// Notifications - последние уведомления пользователя, limit ограничен MaxPageLimit
+func (s *GofemartService) Notifications(userID int, unreadOnly bool, limit int) (*models.Notifications, error) {
+	if userID <= 0 {
+		return nil, fmt.Errorf("invalid user ID")
+	}
+	if limit <= 0 {
+		limit = models.DefaultPageLimit
+	}
+	if limit > models.MaxPageLimit {
+		limit = models.MaxPageLimit
+	}
+	return s.repo.Notifications(userID, unreadOnly, limit)
+}
+
+// MarkNotificationsRead - отметка уведомлений прочитанными, без ids - всех
+func (s *GofemartService) MarkNotificationsRead(userID int, ids []int64) (int, error) {
+	if userID <= 0 {
+		return 0, fmt.Errorf("invalid user ID")
+	}
+	return s.repo.MarkNotificationsRead(userID, ids)
+}
+
+// SetNotifier - канал исходящих сообщений и порог списания, о котором пользователь получает письмо
+func (s *GofemartService) SetNotifier(n notify.Notifier, largeWithdrawal float64) {
+	s.notifier = n
+	s.largeWithdrawal = largeWithdrawal
+}
+
+// SetEmailTokenTTL - срок действия кода подтверждения почты
+func (s *GofemartService) SetEmailTokenTTL(ttl time.Duration) {
+	if ttl > 0 {
+		s.emailTokenTTL = ttl
+	}
+}
+
+// NotifyUser - сообщение вида kind на подтверждённую почту пользователя.
+// Без почты или без канала доставки ничего не отправляется
+func (s *GofemartService) NotifyUser(ctx context.Context, userID int, kind string, data notify.Data) error {
+	if s.notifier == nil {
+		return nil
+	}
+	user, err := s.repo.GetUserContact(userID)
+	if err != nil {
+		return err
+	}
+	if !user.EmailVerified() {
+		return nil
+	}
+
+	data.Login = user.Login
+	msg, err := notify.Render(kind, user.Email, data)
+	if err != nil {
+		return err
+	}
+	return s.notifier.Send(ctx, msg)
+}
+
+// AlertLargeWithdrawal - письмо о списании, если сумма не меньше порога
+func (s *GofemartService) AlertLargeWithdrawal(ctx context.Context, userID int, withdraw models.WithdrawBalance) error {
+	if s.largeWithdrawal <= 0 || withdraw.Sum < s.largeWithdrawal {
+		return nil
+	}
+	return s.NotifyUser(ctx, userID, models.NotificationLargeWithdrawal, notify.Data{
+		Order:  withdraw.Order,
+		Amount: withdraw.Sum,
+	})
+}
+
+// RequestEmailVerification - сохраняет новую почту и отправляет на неё код подтверждения
+func (s *GofemartService) RequestEmailVerification(ctx context.Context, userID int, req models.EmailRequest) error {
+	email := models.NormalizeEmail(req.Email)
+	if err := models.ValidateEmail(email); err != nil {
+		return err
+	}
+	if s.notifier == nil {
+		return models.ErrNotifierUnavailable
+	}
+
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return fmt.Errorf("failed to generate verification code: %w", err)
+	}
+	token := hex.EncodeToString(raw)
+	expiresAt := time.Now().Add(s.emailTokenTTL)
+
+	if err := s.repo.SetUserEmail(userID, email, hashEmailToken(token), expiresAt); err != nil {
+		return err
+	}
+
+	msg, err := notify.Render(models.NotificationEmailVerification, email, notify.Data{
+		Token:     token,
+		ExpiresAt: expiresAt,
+	})
+	if err != nil {
+		return err
+	}
+	return s.notifier.Send(ctx, msg)
+}
+
+// VerifyEmail - подтверждение почты кодом из письма
+func (s *GofemartService) VerifyEmail(userID int, req models.VerifyEmailRequest) (*models.User, error) {
+	token := strings.TrimSpace(req.Token)
+	if token == "" {
+		return nil, fmt.Errorf("%w: verification code is required", models.ErrInvalidEmail)
+	}
+	return s.repo.VerifyEmail(userID, hashEmailToken(token))
+}
+
+// RemoveEmail - удаление почты пользователя
+func (s *GofemartService) RemoveEmail(userID int) error {
+	return s.repo.RemoveUserEmail(userID)
+}
+
+// hashEmailToken - в базе хранится только sha256 кода подтверждения
+func hashEmailToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -5,8 +5,10 @@
 package mocks
 
 import (
+	context "context"
 	models "go-musthave-diploma-tpl/internal/gophermart/models"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 )
@@ -34,6 +36,66 @@ func (m *MockGofemartRepo) EXPECT() *MockGofemartRepoMockRecorder {
 	return m.recorder
 }
 
+// Adjustments mocks base method.
+func (m *MockGofemartRepo) Adjustments(userID, limit int) ([]models.BalanceAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Adjustments", userID, limit)
+	ret0, _ := ret[0].([]models.BalanceAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Adjustments indicates an expected call of Adjustments.
+func (mr *MockGofemartRepoMockRecorder) Adjustments(userID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Adjustments", reflect.TypeOf((*MockGofemartRepo)(nil).Adjustments), userID, limit)
+}
+
+// BalanceHistory mocks base method.
+func (m *MockGofemartRepo) BalanceHistory(userID, limit int) ([]models.BalanceEntry, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "BalanceHistory", userID, limit)
+	ret0, _ := ret[0].([]models.BalanceEntry)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// BalanceHistory indicates an expected call of BalanceHistory.
+func (mr *MockGofemartRepoMockRecorder) BalanceHistory(userID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "BalanceHistory", reflect.TypeOf((*MockGofemartRepo)(nil).BalanceHistory), userID, limit)
+}
+
+// CreateAdjustment mocks base method.
+func (m *MockGofemartRepo) CreateAdjustment(adminID, userID int, amount float64, reason string) (*models.BalanceAdjustment, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateAdjustment", adminID, userID, amount, reason)
+	ret0, _ := ret[0].(*models.BalanceAdjustment)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateAdjustment indicates an expected call of CreateAdjustment.
+func (mr *MockGofemartRepoMockRecorder) CreateAdjustment(adminID, userID, amount, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateAdjustment", reflect.TypeOf((*MockGofemartRepo)(nil).CreateAdjustment), adminID, userID, amount, reason)
+}
+
+// CreateHousehold mocks base method.
+func (m *MockGofemartRepo) CreateHousehold(ownerID int, name string) (*models.Household, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateHousehold", ownerID, name)
+	ret0, _ := ret[0].(*models.Household)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateHousehold indicates an expected call of CreateHousehold.
+func (mr *MockGofemartRepoMockRecorder) CreateHousehold(ownerID, name interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHousehold", reflect.TypeOf((*MockGofemartRepo)(nil).CreateHousehold), ownerID, name)
+}
+
 // CreateOrder mocks base method.
 func (m *MockGofemartRepo) CreateOrder(userID int, orderNumber string) error {
 	m.ctrl.T.Helper()
@@ -48,6 +110,51 @@ func (mr *MockGofemartRepoMockRecorder) CreateOrder(userID, orderNumber interfac
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrder", reflect.TypeOf((*MockGofemartRepo)(nil).CreateOrder), userID, orderNumber)
 }
 
+// CreateOrders mocks base method.
+func (m *MockGofemartRepo) CreateOrders(userID int, numbers []string) (map[string]string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateOrders", userID, numbers)
+	ret0, _ := ret[0].(map[string]string)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateOrders indicates an expected call of CreateOrders.
+func (mr *MockGofemartRepoMockRecorder) CreateOrders(userID, numbers interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateOrders", reflect.TypeOf((*MockGofemartRepo)(nil).CreateOrders), userID, numbers)
+}
+
+// CreatePromoCode mocks base method.
+func (m *MockGofemartRepo) CreatePromoCode(adminID int, promo models.PromoCode) (*models.PromoCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreatePromoCode", adminID, promo)
+	ret0, _ := ret[0].(*models.PromoCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreatePromoCode indicates an expected call of CreatePromoCode.
+func (mr *MockGofemartRepoMockRecorder) CreatePromoCode(adminID, promo interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreatePromoCode", reflect.TypeOf((*MockGofemartRepo)(nil).CreatePromoCode), adminID, promo)
+}
+
+// CreateReferredUser mocks base method.
+func (m *MockGofemartRepo) CreateReferredUser(login, password, code string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateReferredUser", login, password, code)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateReferredUser indicates an expected call of CreateReferredUser.
+func (mr *MockGofemartRepoMockRecorder) CreateReferredUser(login, password, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateReferredUser", reflect.TypeOf((*MockGofemartRepo)(nil).CreateReferredUser), login, password, code)
+}
+
 // CreateUser mocks base method.
 func (m *MockGofemartRepo) CreateUser(login, password string) (*models.User, error) {
 	m.ctrl.T.Helper()
@@ -63,6 +170,81 @@ func (mr *MockGofemartRepoMockRecorder) CreateUser(login, password interface{})
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateUser", reflect.TypeOf((*MockGofemartRepo)(nil).CreateUser), login, password)
 }
 
+// CreateWebhook mocks base method.
+func (m *MockGofemartRepo) CreateWebhook(userID int, url string, events []string) (*models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", userID, url, events)
+	ret0, _ := ret[0].(*models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockGofemartRepoMockRecorder) CreateWebhook(userID, url, events interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockGofemartRepo)(nil).CreateWebhook), userID, url, events)
+}
+
+// DeadLetterCount mocks base method.
+func (m *MockGofemartRepo) DeadLetterCount() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeadLetterCount")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeadLetterCount indicates an expected call of DeadLetterCount.
+func (mr *MockGofemartRepoMockRecorder) DeadLetterCount() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeadLetterCount", reflect.TypeOf((*MockGofemartRepo)(nil).DeadLetterCount))
+}
+
+// DeadLetters mocks base method.
+func (m *MockGofemartRepo) DeadLetters() ([]models.DeadLetter, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeadLetters")
+	ret0, _ := ret[0].([]models.DeadLetter)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeadLetters indicates an expected call of DeadLetters.
+func (mr *MockGofemartRepoMockRecorder) DeadLetters() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeadLetters", reflect.TypeOf((*MockGofemartRepo)(nil).DeadLetters))
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockGofemartRepo) DeleteWebhook(userID, webhookID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", userID, webhookID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockGofemartRepoMockRecorder) DeleteWebhook(userID, webhookID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockGofemartRepo)(nil).DeleteWebhook), userID, webhookID)
+}
+
+// FraudFlags mocks base method.
+func (m *MockGofemartRepo) FraudFlags(filter models.FraudFlagFilter) ([]models.FraudFlag, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "FraudFlags", filter)
+	ret0, _ := ret[0].([]models.FraudFlag)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// FraudFlags indicates an expected call of FraudFlags.
+func (mr *MockGofemartRepoMockRecorder) FraudFlags(filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "FraudFlags", reflect.TypeOf((*MockGofemartRepo)(nil).FraudFlags), filter)
+}
+
 // GetBalance mocks base method.
 func (m *MockGofemartRepo) GetBalance(userID int) (models.Balance, error) {
 	m.ctrl.T.Helper()
@@ -78,6 +260,21 @@ func (mr *MockGofemartRepoMockRecorder) GetBalance(userID interface{}) *gomock.C
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockGofemartRepo)(nil).GetBalance), userID)
 }
 
+// GetOrder mocks base method.
+func (m *MockGofemartRepo) GetOrder(userID int, number string) (*models.OrderDetails, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrder", userID, number)
+	ret0, _ := ret[0].(*models.OrderDetails)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOrder indicates an expected call of GetOrder.
+func (mr *MockGofemartRepoMockRecorder) GetOrder(userID, number interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrder", reflect.TypeOf((*MockGofemartRepo)(nil).GetOrder), userID, number)
+}
+
 // GetOrders mocks base method.
 func (m *MockGofemartRepo) GetOrders(userID int) ([]models.Order, error) {
 	m.ctrl.T.Helper()
@@ -93,6 +290,22 @@ func (mr *MockGofemartRepoMockRecorder) GetOrders(userID interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrders", reflect.TypeOf((*MockGofemartRepo)(nil).GetOrders), userID)
 }
 
+// GetOrdersPage mocks base method.
+func (m *MockGofemartRepo) GetOrdersPage(userID int, params models.ListParams) ([]models.Order, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOrdersPage", userID, params)
+	ret0, _ := ret[0].([]models.Order)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// GetOrdersPage indicates an expected call of GetOrdersPage.
+func (mr *MockGofemartRepoMockRecorder) GetOrdersPage(userID, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOrdersPage", reflect.TypeOf((*MockGofemartRepo)(nil).GetOrdersPage), userID, params)
+}
+
 // GetUserByID mocks base method.
 func (m *MockGofemartRepo) GetUserByID(id int) (*models.User, error) {
 	m.ctrl.T.Helper()
@@ -123,6 +336,390 @@ func (mr *MockGofemartRepoMockRecorder) GetUserByLoginAndPassword(login, passwor
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserByLoginAndPassword", reflect.TypeOf((*MockGofemartRepo)(nil).GetUserByLoginAndPassword), login, password)
 }
 
+// GetUserContact mocks base method.
+func (m *MockGofemartRepo) GetUserContact(userID int) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetUserContact", userID)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetUserContact indicates an expected call of GetUserContact.
+func (mr *MockGofemartRepoMockRecorder) GetUserContact(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetUserContact", reflect.TypeOf((*MockGofemartRepo)(nil).GetUserContact), userID)
+}
+
+// Household mocks base method.
+func (m *MockGofemartRepo) Household(userID int) (*models.Household, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Household", userID)
+	ret0, _ := ret[0].(*models.Household)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Household indicates an expected call of Household.
+func (mr *MockGofemartRepoMockRecorder) Household(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Household", reflect.TypeOf((*MockGofemartRepo)(nil).Household), userID)
+}
+
+// HouseholdBalance mocks base method.
+func (m *MockGofemartRepo) HouseholdBalance(userID int) (models.Balance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HouseholdBalance", userID)
+	ret0, _ := ret[0].(models.Balance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HouseholdBalance indicates an expected call of HouseholdBalance.
+func (mr *MockGofemartRepoMockRecorder) HouseholdBalance(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HouseholdBalance", reflect.TypeOf((*MockGofemartRepo)(nil).HouseholdBalance), userID)
+}
+
+// HouseholdInvites mocks base method.
+func (m *MockGofemartRepo) HouseholdInvites(userID int) ([]models.HouseholdInvite, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HouseholdInvites", userID)
+	ret0, _ := ret[0].([]models.HouseholdInvite)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HouseholdInvites indicates an expected call of HouseholdInvites.
+func (mr *MockGofemartRepoMockRecorder) HouseholdInvites(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HouseholdInvites", reflect.TypeOf((*MockGofemartRepo)(nil).HouseholdInvites), userID)
+}
+
+// HouseholdWithdrawals mocks base method.
+func (m *MockGofemartRepo) HouseholdWithdrawals(userID, limit int) ([]models.HouseholdWithdrawal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "HouseholdWithdrawals", userID, limit)
+	ret0, _ := ret[0].([]models.HouseholdWithdrawal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// HouseholdWithdrawals indicates an expected call of HouseholdWithdrawals.
+func (mr *MockGofemartRepoMockRecorder) HouseholdWithdrawals(userID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "HouseholdWithdrawals", reflect.TypeOf((*MockGofemartRepo)(nil).HouseholdWithdrawals), userID, limit)
+}
+
+// InviteToHousehold mocks base method.
+func (m *MockGofemartRepo) InviteToHousehold(ownerID int, login string) (*models.HouseholdInvite, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InviteToHousehold", ownerID, login)
+	ret0, _ := ret[0].(*models.HouseholdInvite)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// InviteToHousehold indicates an expected call of InviteToHousehold.
+func (mr *MockGofemartRepoMockRecorder) InviteToHousehold(ownerID, login interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InviteToHousehold", reflect.TypeOf((*MockGofemartRepo)(nil).InviteToHousehold), ownerID, login)
+}
+
+// MarkNotificationsRead mocks base method.
+func (m *MockGofemartRepo) MarkNotificationsRead(userID int, ids []int64) (int, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkNotificationsRead", userID, ids)
+	ret0, _ := ret[0].(int)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MarkNotificationsRead indicates an expected call of MarkNotificationsRead.
+func (mr *MockGofemartRepoMockRecorder) MarkNotificationsRead(userID, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkNotificationsRead", reflect.TypeOf((*MockGofemartRepo)(nil).MarkNotificationsRead), userID, ids)
+}
+
+// Notifications mocks base method.
+func (m *MockGofemartRepo) Notifications(userID int, unreadOnly bool, limit int) (*models.Notifications, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Notifications", userID, unreadOnly, limit)
+	ret0, _ := ret[0].(*models.Notifications)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Notifications indicates an expected call of Notifications.
+func (mr *MockGofemartRepoMockRecorder) Notifications(userID, unreadOnly, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Notifications", reflect.TypeOf((*MockGofemartRepo)(nil).Notifications), userID, unreadOnly, limit)
+}
+
+// Profile mocks base method.
+func (m *MockGofemartRepo) Profile(userID int, since time.Time) (*models.Profile, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Profile", userID, since)
+	ret0, _ := ret[0].(*models.Profile)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Profile indicates an expected call of Profile.
+func (mr *MockGofemartRepoMockRecorder) Profile(userID, since interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Profile", reflect.TypeOf((*MockGofemartRepo)(nil).Profile), userID, since)
+}
+
+// PromoCodes mocks base method.
+func (m *MockGofemartRepo) PromoCodes() ([]models.PromoCode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PromoCodes")
+	ret0, _ := ret[0].([]models.PromoCode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PromoCodes indicates an expected call of PromoCodes.
+func (mr *MockGofemartRepoMockRecorder) PromoCodes() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PromoCodes", reflect.TypeOf((*MockGofemartRepo)(nil).PromoCodes))
+}
+
+// RedeemPromoCode mocks base method.
+func (m *MockGofemartRepo) RedeemPromoCode(userID int, code string) (*models.PromoRedemption, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RedeemPromoCode", userID, code)
+	ret0, _ := ret[0].(*models.PromoRedemption)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RedeemPromoCode indicates an expected call of RedeemPromoCode.
+func (mr *MockGofemartRepoMockRecorder) RedeemPromoCode(userID, code interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RedeemPromoCode", reflect.TypeOf((*MockGofemartRepo)(nil).RedeemPromoCode), userID, code)
+}
+
+// ReferralStats mocks base method.
+func (m *MockGofemartRepo) ReferralStats(userID int) (*models.ReferralStats, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReferralStats", userID)
+	ret0, _ := ret[0].(*models.ReferralStats)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReferralStats indicates an expected call of ReferralStats.
+func (mr *MockGofemartRepoMockRecorder) ReferralStats(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReferralStats", reflect.TypeOf((*MockGofemartRepo)(nil).ReferralStats), userID)
+}
+
+// RemoveHouseholdMember mocks base method.
+func (m *MockGofemartRepo) RemoveHouseholdMember(actorID, memberID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveHouseholdMember", actorID, memberID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveHouseholdMember indicates an expected call of RemoveHouseholdMember.
+func (mr *MockGofemartRepoMockRecorder) RemoveHouseholdMember(actorID, memberID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveHouseholdMember", reflect.TypeOf((*MockGofemartRepo)(nil).RemoveHouseholdMember), actorID, memberID)
+}
+
+// RemoveUserEmail mocks base method.
+func (m *MockGofemartRepo) RemoveUserEmail(userID int) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RemoveUserEmail", userID)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RemoveUserEmail indicates an expected call of RemoveUserEmail.
+func (mr *MockGofemartRepoMockRecorder) RemoveUserEmail(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RemoveUserEmail", reflect.TypeOf((*MockGofemartRepo)(nil).RemoveUserEmail), userID)
+}
+
+// RequeueAllDeadLetters mocks base method.
+func (m *MockGofemartRepo) RequeueAllDeadLetters() (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequeueAllDeadLetters")
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RequeueAllDeadLetters indicates an expected call of RequeueAllDeadLetters.
+func (mr *MockGofemartRepoMockRecorder) RequeueAllDeadLetters() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequeueAllDeadLetters", reflect.TypeOf((*MockGofemartRepo)(nil).RequeueAllDeadLetters))
+}
+
+// RequeueDeadLetter mocks base method.
+func (m *MockGofemartRepo) RequeueDeadLetter(number string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RequeueDeadLetter", number)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RequeueDeadLetter indicates an expected call of RequeueDeadLetter.
+func (mr *MockGofemartRepoMockRecorder) RequeueDeadLetter(number interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RequeueDeadLetter", reflect.TypeOf((*MockGofemartRepo)(nil).RequeueDeadLetter), number)
+}
+
+// RespondHouseholdInvite mocks base method.
+func (m *MockGofemartRepo) RespondHouseholdInvite(userID, inviteID int, accept bool) (*models.HouseholdInvite, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RespondHouseholdInvite", userID, inviteID, accept)
+	ret0, _ := ret[0].(*models.HouseholdInvite)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RespondHouseholdInvite indicates an expected call of RespondHouseholdInvite.
+func (mr *MockGofemartRepoMockRecorder) RespondHouseholdInvite(userID, inviteID, accept interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RespondHouseholdInvite", reflect.TypeOf((*MockGofemartRepo)(nil).RespondHouseholdInvite), userID, inviteID, accept)
+}
+
+// ReviewFraudFlag mocks base method.
+func (m *MockGofemartRepo) ReviewFraudFlag(flagID int64, adminID int, review models.FraudReview) (*models.FraudFlag, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReviewFraudFlag", flagID, adminID, review)
+	ret0, _ := ret[0].(*models.FraudFlag)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReviewFraudFlag indicates an expected call of ReviewFraudFlag.
+func (mr *MockGofemartRepoMockRecorder) ReviewFraudFlag(flagID, adminID, review interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReviewFraudFlag", reflect.TypeOf((*MockGofemartRepo)(nil).ReviewFraudFlag), flagID, adminID, review)
+}
+
+// SetAccrualSharing mocks base method.
+func (m *MockGofemartRepo) SetAccrualSharing(userID int, share bool) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetAccrualSharing", userID, share)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetAccrualSharing indicates an expected call of SetAccrualSharing.
+func (mr *MockGofemartRepoMockRecorder) SetAccrualSharing(userID, share interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetAccrualSharing", reflect.TypeOf((*MockGofemartRepo)(nil).SetAccrualSharing), userID, share)
+}
+
+// SetUserEmail mocks base method.
+func (m *MockGofemartRepo) SetUserEmail(userID int, email, tokenHash string, expiresAt time.Time) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetUserEmail", userID, email, tokenHash, expiresAt)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetUserEmail indicates an expected call of SetUserEmail.
+func (mr *MockGofemartRepoMockRecorder) SetUserEmail(userID, email, tokenHash, expiresAt interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetUserEmail", reflect.TypeOf((*MockGofemartRepo)(nil).SetUserEmail), userID, email, tokenHash, expiresAt)
+}
+
+// UpdateHouseholdMember mocks base method.
+func (m *MockGofemartRepo) UpdateHouseholdMember(ownerID, memberID int, perms models.MemberPermissions) (*models.HouseholdMember, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateHouseholdMember", ownerID, memberID, perms)
+	ret0, _ := ret[0].(*models.HouseholdMember)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateHouseholdMember indicates an expected call of UpdateHouseholdMember.
+func (mr *MockGofemartRepoMockRecorder) UpdateHouseholdMember(ownerID, memberID, perms interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateHouseholdMember", reflect.TypeOf((*MockGofemartRepo)(nil).UpdateHouseholdMember), ownerID, memberID, perms)
+}
+
+// UpdateOrderStatusByNumber mocks base method.
+func (m *MockGofemartRepo) UpdateOrderStatusByNumber(ctx context.Context, number, status string, accrual float64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateOrderStatusByNumber", ctx, number, status, accrual)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// UpdateOrderStatusByNumber indicates an expected call of UpdateOrderStatusByNumber.
+func (mr *MockGofemartRepoMockRecorder) UpdateOrderStatusByNumber(ctx, number, status, accrual interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateOrderStatusByNumber", reflect.TypeOf((*MockGofemartRepo)(nil).UpdateOrderStatusByNumber), ctx, number, status, accrual)
+}
+
+// UserEventsSince mocks base method.
+func (m *MockGofemartRepo) UserEventsSince(userID int, afterID int64, limit int) ([]models.UserEvent, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UserEventsSince", userID, afterID, limit)
+	ret0, _ := ret[0].([]models.UserEvent)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UserEventsSince indicates an expected call of UserEventsSince.
+func (mr *MockGofemartRepoMockRecorder) UserEventsSince(userID, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UserEventsSince", reflect.TypeOf((*MockGofemartRepo)(nil).UserEventsSince), userID, afterID, limit)
+}
+
+// VerifyEmail mocks base method.
+func (m *MockGofemartRepo) VerifyEmail(userID int, tokenHash string) (*models.User, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyEmail", userID, tokenHash)
+	ret0, _ := ret[0].(*models.User)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyEmail indicates an expected call of VerifyEmail.
+func (mr *MockGofemartRepoMockRecorder) VerifyEmail(userID, tokenHash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyEmail", reflect.TypeOf((*MockGofemartRepo)(nil).VerifyEmail), userID, tokenHash)
+}
+
+// WebhookDeliveries mocks base method.
+func (m *MockGofemartRepo) WebhookDeliveries(userID, webhookID, limit int) ([]models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WebhookDeliveries", userID, webhookID, limit)
+	ret0, _ := ret[0].([]models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WebhookDeliveries indicates an expected call of WebhookDeliveries.
+func (mr *MockGofemartRepoMockRecorder) WebhookDeliveries(userID, webhookID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WebhookDeliveries", reflect.TypeOf((*MockGofemartRepo)(nil).WebhookDeliveries), userID, webhookID, limit)
+}
+
+// Webhooks mocks base method.
+func (m *MockGofemartRepo) Webhooks(userID int) ([]models.Webhook, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Webhooks", userID)
+	ret0, _ := ret[0].([]models.Webhook)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Webhooks indicates an expected call of Webhooks.
+func (mr *MockGofemartRepoMockRecorder) Webhooks(userID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Webhooks", reflect.TypeOf((*MockGofemartRepo)(nil).Webhooks), userID)
+}
+
 // Withdraw mocks base method.
 func (m *MockGofemartRepo) Withdraw(userID int, withdraw models.WithdrawBalance) error {
 	m.ctrl.T.Helper()
@@ -137,6 +734,20 @@ func (mr *MockGofemartRepoMockRecorder) Withdraw(userID, withdraw interface{}) *
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Withdraw", reflect.TypeOf((*MockGofemartRepo)(nil).Withdraw), userID, withdraw)
 }
 
+// WithdrawFromHousehold mocks base method.
+func (m *MockGofemartRepo) WithdrawFromHousehold(userID int, withdraw models.WithdrawBalance) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithdrawFromHousehold", userID, withdraw)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// WithdrawFromHousehold indicates an expected call of WithdrawFromHousehold.
+func (mr *MockGofemartRepoMockRecorder) WithdrawFromHousehold(userID, withdraw interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithdrawFromHousehold", reflect.TypeOf((*MockGofemartRepo)(nil).WithdrawFromHousehold), userID, withdraw)
+}
+
 // Withdrawals mocks base method.
 func (m *MockGofemartRepo) Withdrawals(userID int) ([]models.WithdrawBalance, error) {
 	m.ctrl.T.Helper()
@@ -151,3 +762,123 @@ func (mr *MockGofemartRepoMockRecorder) Withdrawals(userID interface{}) *gomock.
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Withdrawals", reflect.TypeOf((*MockGofemartRepo)(nil).Withdrawals), userID)
 }
+
+// WithdrawalsPage mocks base method.
+func (m *MockGofemartRepo) WithdrawalsPage(userID int, params models.ListParams) ([]models.WithdrawBalance, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WithdrawalsPage", userID, params)
+	ret0, _ := ret[0].([]models.WithdrawBalance)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// WithdrawalsPage indicates an expected call of WithdrawalsPage.
+func (mr *MockGofemartRepoMockRecorder) WithdrawalsPage(userID, params interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WithdrawalsPage", reflect.TypeOf((*MockGofemartRepo)(nil).WithdrawalsPage), userID, params)
+}
+
+// MockAuditLog is a mock of AuditLog interface.
+type MockAuditLog struct {
+	ctrl     *gomock.Controller
+	recorder *MockAuditLogMockRecorder
+}
+
+// MockAuditLogMockRecorder is the mock recorder for MockAuditLog.
+type MockAuditLogMockRecorder struct {
+	mock *MockAuditLog
+}
+
+// NewMockAuditLog creates a new mock instance.
+func NewMockAuditLog(ctrl *gomock.Controller) *MockAuditLog {
+	mock := &MockAuditLog{ctrl: ctrl}
+	mock.recorder = &MockAuditLogMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAuditLog) EXPECT() *MockAuditLogMockRecorder {
+	return m.recorder
+}
+
+// AuditEvents mocks base method.
+func (m *MockAuditLog) AuditEvents(filter models.AuditFilter) ([]models.AuditEvent, string, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AuditEvents", filter)
+	ret0, _ := ret[0].([]models.AuditEvent)
+	ret1, _ := ret[1].(string)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// AuditEvents indicates an expected call of AuditEvents.
+func (mr *MockAuditLogMockRecorder) AuditEvents(filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AuditEvents", reflect.TypeOf((*MockAuditLog)(nil).AuditEvents), filter)
+}
+
+// RecordAudit mocks base method.
+func (m *MockAuditLog) RecordAudit(ctx context.Context, event models.AuditEvent) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordAudit", ctx, event)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordAudit indicates an expected call of RecordAudit.
+func (mr *MockAuditLogMockRecorder) RecordAudit(ctx, event interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordAudit", reflect.TypeOf((*MockAuditLog)(nil).RecordAudit), ctx, event)
+}
+
+// MockLeaderReporter is a mock of LeaderReporter interface.
+type MockLeaderReporter struct {
+	ctrl     *gomock.Controller
+	recorder *MockLeaderReporterMockRecorder
+}
+
+// MockLeaderReporterMockRecorder is the mock recorder for MockLeaderReporter.
+type MockLeaderReporterMockRecorder struct {
+	mock *MockLeaderReporter
+}
+
+// NewMockLeaderReporter creates a new mock instance.
+func NewMockLeaderReporter(ctrl *gomock.Controller) *MockLeaderReporter {
+	mock := &MockLeaderReporter{ctrl: ctrl}
+	mock.recorder = &MockLeaderReporterMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockLeaderReporter) EXPECT() *MockLeaderReporterMockRecorder {
+	return m.recorder
+}
+
+// InstanceID mocks base method.
+func (m *MockLeaderReporter) InstanceID() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "InstanceID")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// InstanceID indicates an expected call of InstanceID.
+func (mr *MockLeaderReporterMockRecorder) InstanceID() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "InstanceID", reflect.TypeOf((*MockLeaderReporter)(nil).InstanceID))
+}
+
+// LeaderID mocks base method.
+func (m *MockLeaderReporter) LeaderID() string {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LeaderID")
+	ret0, _ := ret[0].(string)
+	return ret0
+}
+
+// LeaderID indicates an expected call of LeaderID.
+func (mr *MockLeaderReporterMockRecorder) LeaderID() *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LeaderID", reflect.TypeOf((*MockLeaderReporter)(nil).LeaderID))
+}
@@ -132,7 +132,7 @@ func TestGofemartService_LoginUser_InvalidCredentials(t *testing.T) {
 
 	assert.Error(t, err)
 	assert.Nil(t, user)
-	assert.Equal(t, handler.ErrInvalidLoginOrPassword.Error(), err.Error())
+	assert.ErrorIs(t, err, handler.ErrInvalidLoginOrPassword)
 }
 
 func TestGofemartService_LoginUser_EmptyCredentials(t *testing.T) {
@@ -0,0 +1,105 @@
+package tests
+
+import (
+	"errors"
+	"testing"
+
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+	"go-musthave-diploma-tpl/internal/gophermart/service"
+	mocks "go-musthave-diploma-tpl/internal/gophermart/service/mocks"
+	pgk "go-musthave-diploma-tpl/pkg"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGofemartService_CreateOrders(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	svc := service.NewGofemartService(mockRepo, "http://localhost:8081")
+
+	// невалидные номера и повтор внутри пакета в репозиторий не уходят
+	mockRepo.EXPECT().
+		CreateOrders(1, []string{"12345678903", "2377225624", "79927398713"}).
+		Return(map[string]string{
+			"12345678903": models.BatchResultAccepted,
+			"2377225624":  models.BatchResultDuplicate,
+			"79927398713": models.BatchResultConflict,
+		}, nil)
+
+	results, err := svc.CreateOrders(1, []string{"12345678903", "12345678900", "2377225624", "12345678903", "abc", "79927398713"})
+	require.NoError(t, err)
+	assert.Equal(t, []models.BatchOrderResult{
+		{Number: "12345678903", Result: models.BatchResultAccepted},
+		{Number: "12345678900", Result: models.BatchResultInvalid},
+		{Number: "2377225624", Result: models.BatchResultDuplicate},
+		{Number: "12345678903", Result: models.BatchResultDuplicate},
+		{Number: "abc", Result: models.BatchResultInvalid},
+		{Number: "79927398713", Result: models.BatchResultConflict},
+	}, results)
+}
+
+func TestGofemartService_CreateOrders_AllInvalid(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	svc := service.NewGofemartService(mockRepo, "http://localhost:8081")
+
+	results, err := svc.CreateOrders(1, []string{"1"})
+	require.NoError(t, err)
+	assert.Equal(t, []models.BatchOrderResult{{Number: "1", Result: models.BatchResultInvalid}}, results)
+}
+
+func TestGofemartService_CreateOrders_Limits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	svc := service.NewGofemartService(mockRepo, "http://localhost:8081")
+
+	_, err := svc.CreateOrders(1, nil)
+	assert.ErrorIs(t, err, models.ErrEmptyBatch)
+
+	_, err = svc.CreateOrders(1, make([]string, models.MaxBatchSize+1))
+	assert.ErrorIs(t, err, models.ErrBatchTooLarge)
+}
+
+func TestGofemartService_CreateOrders_RepoError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	svc := service.NewGofemartService(mockRepo, "http://localhost:8081")
+
+	mockRepo.EXPECT().CreateOrders(1, []string{"12345678903"}).Return(nil, errors.New("database error"))
+
+	_, err := svc.CreateOrders(1, []string{"12345678903"})
+	assert.Error(t, err)
+}
+
+func TestGofemartService_CreateOrders_CustomValidator(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	svc := service.NewGofemartService(mockRepo, "http://localhost:8081")
+
+	validator, err := pgk.NewValidator(pgk.SchemeDamm, `SHOP-(\d+)`)
+	require.NoError(t, err)
+	svc.SetOrderValidator(validator)
+
+	mockRepo.EXPECT().CreateOrders(1, []string{"SHOP-5724"}).
+		Return(map[string]string{"SHOP-5724": models.BatchResultAccepted}, nil)
+
+	results, err := svc.CreateOrders(1, []string{"SHOP-5724", "5724", "SHOP-12345678903"})
+	require.NoError(t, err)
+	assert.Equal(t, []models.BatchOrderResult{
+		{Number: "SHOP-5724", Result: models.BatchResultAccepted},
+		{Number: "5724", Result: models.BatchResultInvalid},
+		{Number: "SHOP-12345678903", Result: models.BatchResultInvalid},
+	}, results)
+}
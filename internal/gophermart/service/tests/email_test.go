@@ -0,0 +1,125 @@
+package tests
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+	"go-musthave-diploma-tpl/internal/gophermart/notify"
+	"go-musthave-diploma-tpl/internal/gophermart/service"
+	mocks "go-musthave-diploma-tpl/internal/gophermart/service/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// captureNotifier - запоминает отправленные сообщения
+type captureNotifier struct {
+	sent []notify.Message
+}
+
+func (n *captureNotifier) Send(_ context.Context, msg notify.Message) error {
+	n.sent = append(n.sent, msg)
+	return nil
+}
+
+func TestGofemartService_NotifyUser(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	svc := service.NewGofemartService(mockRepo, "http://localhost:8081")
+	n := &captureNotifier{}
+	svc.SetNotifier(n, 1000)
+
+	verified := time.Now()
+	mockRepo.EXPECT().GetUserContact(1).
+		Return(&models.User{ID: 1, Login: "alice", Email: "alice@example.com", EmailVerifiedAt: &verified}, nil)
+	mockRepo.EXPECT().GetUserContact(2).
+		Return(&models.User{ID: 2, Login: "bob", Email: "bob@example.com"}, nil)
+
+	data := notify.Data{Order: "12345678903", Amount: 100}
+	require.NoError(t, svc.NotifyUser(context.Background(), 1, models.NotificationOrderProcessed, data))
+	// неподтверждённая почта не получает писем
+	require.NoError(t, svc.NotifyUser(context.Background(), 2, models.NotificationOrderProcessed, data))
+
+	require.Len(t, n.sent, 1)
+	assert.Equal(t, "alice@example.com", n.sent[0].To)
+	assert.Contains(t, n.sent[0].Body, "alice")
+}
+
+func TestGofemartService_AlertLargeWithdrawal(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	svc := service.NewGofemartService(mockRepo, "http://localhost:8081")
+	n := &captureNotifier{}
+	svc.SetNotifier(n, 1000)
+
+	verified := time.Now()
+	mockRepo.EXPECT().GetUserContact(1).
+		Return(&models.User{ID: 1, Login: "alice", Email: "alice@example.com", EmailVerifiedAt: &verified}, nil)
+
+	// ниже порога письмо не отправляется и пользователь не запрашивается
+	require.NoError(t, svc.AlertLargeWithdrawal(context.Background(), 1, models.WithdrawBalance{Order: "2377225624", Sum: 999}))
+	require.NoError(t, svc.AlertLargeWithdrawal(context.Background(), 1, models.WithdrawBalance{Order: "2377225624", Sum: 1000}))
+
+	require.Len(t, n.sent, 1)
+	assert.Contains(t, n.sent[0].Subject, "1000.00")
+}
+
+func TestGofemartService_RequestEmailVerification(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	svc := service.NewGofemartService(mockRepo, "http://localhost:8081")
+
+	// без канала доставки код подтверждения не отправить
+	err := svc.RequestEmailVerification(context.Background(), 1, models.EmailRequest{Email: "alice@example.com"})
+	assert.ErrorIs(t, err, models.ErrNotifierUnavailable)
+
+	n := &captureNotifier{}
+	svc.SetNotifier(n, 0)
+
+	err = svc.RequestEmailVerification(context.Background(), 1, models.EmailRequest{Email: "Alice <alice@example.com>"})
+	assert.ErrorIs(t, err, models.ErrInvalidEmail)
+
+	var storedHash string
+	mockRepo.EXPECT().SetUserEmail(1, "alice@example.com", gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ int, _, hash string, expiresAt time.Time) error {
+			storedHash = hash
+			assert.True(t, expiresAt.After(time.Now()))
+			return nil
+		})
+
+	require.NoError(t, svc.RequestEmailVerification(context.Background(), 1, models.EmailRequest{Email: " alice@EXAMPLE.com "}))
+	require.Len(t, n.sent, 1)
+	assert.Equal(t, "alice@example.com", n.sent[0].To)
+
+	// в базе хранится хеш кода из письма, а не сам код
+	var token string
+	for _, line := range strings.Split(n.sent[0].Body, "\n") {
+		if i := strings.LastIndex(line, ": "); i >= 0 && strings.Contains(line, "confirm") {
+			token = line[i+2:]
+		}
+	}
+	require.NotEmpty(t, token)
+	sum := sha256.Sum256([]byte(token))
+	assert.Equal(t, hex.EncodeToString(sum[:]), storedHash)
+
+	mockRepo.EXPECT().VerifyEmail(1, storedHash).
+		Return(&models.User{ID: 1, Email: "alice@example.com"}, nil)
+	user, err := svc.VerifyEmail(1, models.VerifyEmailRequest{Token: " " + token + " "})
+	require.NoError(t, err)
+	assert.Equal(t, "alice@example.com", user.Email)
+
+	_, err = svc.VerifyEmail(1, models.VerifyEmailRequest{})
+	assert.ErrorIs(t, err, models.ErrInvalidEmail)
+}
@@ -0,0 +1,63 @@
+package tests
+
+import (
+	"testing"
+	"time"
+
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+	"go-musthave-diploma-tpl/internal/gophermart/service"
+	mocks "go-musthave-diploma-tpl/internal/gophermart/service/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGofemartService_Profile(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	svc := service.NewGofemartService(mockRepo, "http://localhost:8081")
+
+	now := time.Now().UTC()
+	current := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	since := current.AddDate(0, -2, 0)
+	month := current.Format(models.ProfileMonthFormat)
+
+	mockRepo.EXPECT().Profile(1, since).Return(&models.Profile{
+		ID:      1,
+		Login:   "alice",
+		Orders:  map[string]int{models.OrderStatusProcessed: 2},
+		Accrued: 150,
+		Monthly: []models.MonthlyAccrual{{Month: month, Orders: 2, Accrued: 150}},
+	}, nil)
+
+	profile, err := svc.Profile(1, 3)
+	require.NoError(t, err)
+
+	// статусы без заказов и месяцы без движений заполнены нулями
+	assert.Equal(t, map[string]int{
+		models.OrderStatusNew:        0,
+		models.OrderStatusProcessing: 0,
+		models.OrderStatusInvalid:    0,
+		models.OrderStatusProcessed:  2,
+	}, profile.Orders)
+	assert.Equal(t, []models.MonthlyAccrual{
+		{Month: since.Format(models.ProfileMonthFormat)},
+		{Month: current.AddDate(0, -1, 0).Format(models.ProfileMonthFormat)},
+		{Month: month, Orders: 2, Accrued: 150},
+	}, profile.Monthly)
+}
+
+func TestGofemartService_Profile_InvalidMonths(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	svc := service.NewGofemartService(mocks.NewMockGofemartRepo(ctrl), "http://localhost:8081")
+
+	for _, months := range []int{-1, models.MaxProfileMonths + 1} {
+		_, err := svc.Profile(1, months)
+		assert.ErrorIs(t, err, models.ErrInvalidProfileMonths)
+	}
+}
@@ -0,0 +1,153 @@
+package tests
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+	"go-musthave-diploma-tpl/internal/gophermart/ratelimit"
+	"go-musthave-diploma-tpl/internal/gophermart/service"
+	mocks "go-musthave-diploma-tpl/internal/gophermart/service/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGofemartService_RedeemPromoCode_RateLimited(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	svc := service.NewGofemartService(mockRepo, "http://localhost:8081")
+	svc.SetPromoLimiter(ratelimit.New(ratelimit.Config{Limit: 2, Window: time.Hour}))
+
+	mockRepo.EXPECT().RedeemPromoCode(1, "GUESS").Return(nil, models.ErrPromoCodeNotFound).Times(2)
+
+	for i := 0; i < 2; i++ {
+		_, err := svc.RedeemPromoCode(1, " guess ")
+		assert.ErrorIs(t, err, models.ErrPromoCodeNotFound)
+	}
+
+	// третья попытка не доходит до репозитория
+	_, err := svc.RedeemPromoCode(1, "guess")
+	assert.ErrorIs(t, err, models.ErrTooManyRedeemAttempts)
+
+	// лимит считается по пользователю
+	mockRepo.EXPECT().RedeemPromoCode(2, "SPRING").
+		Return(&models.PromoRedemption{Code: "SPRING", Amount: 100}, nil)
+	redemption, err := svc.RedeemPromoCode(2, "spring")
+	require.NoError(t, err)
+	assert.Equal(t, 100.0, redemption.Amount)
+}
+
+func TestGofemartService_RedeemPromoCode_SuccessNotCounted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	svc := service.NewGofemartService(mockRepo, "http://localhost:8081")
+	svc.SetPromoLimiter(ratelimit.New(ratelimit.Config{Limit: 1, Window: time.Hour}))
+
+	mockRepo.EXPECT().RedeemPromoCode(1, gomock.Any()).
+		Return(&models.PromoRedemption{Amount: 10}, nil).Times(3)
+
+	for _, code := range []string{"A", "B", "C"} {
+		_, err := svc.RedeemPromoCode(1, code)
+		require.NoError(t, err)
+	}
+
+	_, err := svc.RedeemPromoCode(1, "  ")
+	assert.ErrorIs(t, err, models.ErrInvalidPromoCode)
+}
+
+func TestGofemartService_RedeemPromoCode_DatabaseErrorNotCounted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	svc := service.NewGofemartService(mockRepo, "http://localhost:8081")
+	svc.SetPromoLimiter(ratelimit.New(ratelimit.Config{Limit: 1, Window: time.Hour}))
+
+	dbErr := errors.New("database error")
+	mockRepo.EXPECT().RedeemPromoCode(1, "SPRING").Return(nil, dbErr).Times(3)
+
+	// сбой базы не блокирует пользователя
+	for i := 0; i < 3; i++ {
+		_, err := svc.RedeemPromoCode(1, "spring")
+		assert.ErrorIs(t, err, dbErr)
+	}
+}
+
+func TestGofemartService_RedeemPromoCode_ParallelGuesses(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	svc := service.NewGofemartService(mockRepo, "http://localhost:8081")
+	svc.SetPromoLimiter(ratelimit.New(ratelimit.Config{Limit: 3, Window: time.Hour}))
+
+	// параллельные попытки учитываются до ответа базы, до неё доходят только 3
+	mockRepo.EXPECT().RedeemPromoCode(1, gomock.Any()).
+		DoAndReturn(func(int, string) (*models.PromoRedemption, error) {
+			time.Sleep(10 * time.Millisecond)
+			return nil, models.ErrPromoCodeNotFound
+		}).Times(3)
+
+	var wg sync.WaitGroup
+	var limited atomic.Int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			if _, err := svc.RedeemPromoCode(1, fmt.Sprintf("GUESS%d", i)); errors.Is(err, models.ErrTooManyRedeemAttempts) {
+				limited.Add(1)
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.Equal(t, int32(7), limited.Load())
+}
+
+func TestGofemartService_CreatePromoCode(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	svc := service.NewGofemartService(mockRepo, "http://localhost:8081")
+
+	past := time.Now().Add(-time.Hour)
+	tests := []struct {
+		name    string
+		promo   models.PromoCode
+		wantErr bool
+	}{
+		{name: "Zero value", promo: models.PromoCode{Code: "X", PerUserLimit: 1}, wantErr: true},
+		{name: "Space in code", promo: models.PromoCode{Code: "A B", Value: 10, PerUserLimit: 1}, wantErr: true},
+		{name: "No per-user limit", promo: models.PromoCode{Code: "X", Value: 10}, wantErr: true},
+		{name: "Already expired", promo: models.PromoCode{Code: "X", Value: 10, PerUserLimit: 1, ExpiresAt: &past}, wantErr: true},
+		{name: "Valid", promo: models.PromoCode{Code: "spring", Value: 10, PerUserLimit: 1, MaxRedemptions: 100}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if !tt.wantErr {
+				expected := tt.promo
+				expected.Code = "SPRING"
+				mockRepo.EXPECT().CreatePromoCode(7, expected).Return(&expected, nil)
+			}
+
+			_, err := svc.CreatePromoCode(7, tt.promo)
+			if tt.wantErr {
+				assert.ErrorIs(t, err, models.ErrInvalidPromoCode)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package tests
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go-musthave-diploma-tpl/internal/gophermart/accrualclient"
+	"go-musthave-diploma-tpl/internal/gophermart/breaker"
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+	"go-musthave-diploma-tpl/internal/gophermart/service"
+	mocks "go-musthave-diploma-tpl/internal/gophermart/service/mocks"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeAccrual - accrual с заданным ответом, delay имитирует медленный сервис
+type fakeAccrual struct {
+	info  *accrualclient.OrderInfo
+	err   error
+	delay time.Duration
+}
+
+func (f *fakeAccrual) GetOrder(ctx context.Context, number string) (*accrualclient.OrderInfo, error) {
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-time.After(f.delay):
+	}
+	return f.info, f.err
+}
+
+func (f *fakeAccrual) RegisterOrder(context.Context, accrualclient.Order) error   { return nil }
+func (f *fakeAccrual) RegisterReward(context.Context, accrualclient.Reward) error { return nil }
+
+func TestGofemartService_CreateOrder_SyncAccrual(t *testing.T) {
+	tests := []struct {
+		name          string
+		accrual       *fakeAccrual
+		expectUpdate  bool
+		updatedStatus string
+	}{
+		{
+			name:          "Processed result is saved immediately",
+			accrual:       &fakeAccrual{info: &accrualclient.OrderInfo{Order: "12345678903", Status: accrualclient.StatusProcessed, Accrual: 500}},
+			expectUpdate:  true,
+			updatedStatus: models.OrderStatusProcessed,
+		},
+		{
+			name:          "Invalid result is saved immediately",
+			accrual:       &fakeAccrual{info: &accrualclient.OrderInfo{Order: "12345678903", Status: accrualclient.StatusInvalid}},
+			expectUpdate:  true,
+			updatedStatus: models.OrderStatusInvalid,
+		},
+		{
+			name:    "Non-final result is left to the listener",
+			accrual: &fakeAccrual{info: &accrualclient.OrderInfo{Order: "12345678903", Status: accrualclient.StatusRegistered}},
+		},
+		{
+			name:    "Not registered",
+			accrual: &fakeAccrual{err: accrualclient.ErrNotRegistered},
+		},
+		{
+			name:    "Accrual error",
+			accrual: &fakeAccrual{err: errors.New("connection refused")},
+		},
+		{
+			name:    "Timeout",
+			accrual: &fakeAccrual{info: &accrualclient.OrderInfo{Status: accrualclient.StatusProcessed}, delay: time.Second},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockGofemartRepo(ctrl)
+			svc := service.NewGofemartService(mockRepo, "http://localhost:8081")
+			svc.SetSyncAccrual(tt.accrual, 50*time.Millisecond)
+
+			mockRepo.EXPECT().CreateOrder(1, "12345678903").Return(nil)
+			if tt.expectUpdate {
+				mockRepo.EXPECT().
+					UpdateOrderStatusByNumber(gomock.Any(), "12345678903", tt.updatedStatus, tt.accrual.info.Accrual).
+					Return(nil)
+			}
+
+			// при любом исходе запроса в accrual заказ принят
+			assert.NoError(t, svc.CreateOrder(1, "12345678903"))
+		})
+	}
+}
+
+func TestGofemartService_CreateOrder_SyncAccrualUpdateError(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := mocks.NewMockGofemartRepo(ctrl)
+	svc := service.NewGofemartService(mockRepo, "http://localhost:8081")
+	svc.SetSyncAccrual(&fakeAccrual{info: &accrualclient.OrderInfo{Status: accrualclient.StatusProcessed, Accrual: 10}}, time.Second)
+
+	mockRepo.EXPECT().CreateOrder(1, "12345678903").Return(nil)
+	mockRepo.EXPECT().UpdateOrderStatusByNumber(gomock.Any(), "12345678903", models.OrderStatusProcessed, 10.0).
+		Return(errors.New("database error"))
+
+	// ошибка сохранения результата не ломает загрузку, заказ обработает listener
+	assert.NoError(t, svc.CreateOrder(1, "12345678903"))
+}
+
+func TestGofemartService_CreateOrder_SyncAccrualReleasesProbe(t *testing.T) {
+	tests := []struct {
+		name    string
+		accrual *fakeAccrual
+		state   breaker.State
+	}{
+		{
+			name:    "Order not registered closes the breaker",
+			accrual: &fakeAccrual{err: accrualclient.ErrNotRegistered},
+			state:   breaker.StateClosed,
+		},
+		{
+			name:    "Rate limit closes the breaker",
+			accrual: &fakeAccrual{err: &accrualclient.RateLimitError{RetryAfter: time.Second}},
+			state:   breaker.StateClosed,
+		},
+		{
+			name:    "Local timeout frees the probe",
+			accrual: &fakeAccrual{delay: time.Second},
+			state:   breaker.StateHalfOpen,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctrl := gomock.NewController(t)
+			defer ctrl.Finish()
+
+			mockRepo := mocks.NewMockGofemartRepo(ctrl)
+			svc := service.NewGofemartService(mockRepo, "http://localhost:8081")
+			svc.SetSyncAccrual(tt.accrual, 10*time.Millisecond)
+
+			cb := breaker.New("accrual", breaker.Config{FailureThreshold: 1, CoolDown: time.Millisecond}, nil)
+			cb.Failure()
+			time.Sleep(5 * time.Millisecond)
+			svc.SetAccrualBreaker(cb)
+
+			mockRepo.EXPECT().CreateOrder(1, "12345678903").Return(nil)
+			assert.NoError(t, svc.CreateOrder(1, "12345678903"))
+
+			// пробный запрос завершён, listener снова может обратиться к accrual
+			assert.Equal(t, tt.state, cb.State())
+			assert.NoError(t, cb.Allow())
+		})
+	}
+}
@@ -0,0 +1,214 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strconv"
+	"sync"
+	"syscall"
+	"time"
+
+	"go-musthave-diploma-tpl/internal/gophermart/models"
+
+	"go.uber.org/zap"
+)
+
+// заголовки исходящего запроса вебхука
+const (
+	HeaderEvent     = "X-Gophermart-Event"
+	HeaderDelivery  = "X-Gophermart-Delivery"
+	HeaderTimestamp = "X-Gophermart-Timestamp"
+	HeaderSignature = "X-Gophermart-Signature"
+)
+
+// Sign - HMAC-SHA256 от "timestamp.body" в hex.
+// Получатель пересчитывает подпись своим секретом и отбрасывает запросы со старым timestamp
+func Sign(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// Config - настройки доставки вебхуков
+type Config struct {
+	// сколько доставок забирать за один проход
+	BatchSize int
+	// пауза, когда доставок нет
+	PollInterval time.Duration
+	// таймаут запроса к получателю
+	Timeout time.Duration
+	// после стольких неудачных попыток доставка помечается FAILED
+	MaxAttempts int
+	// разрешить доставку на loopback и в частные сети, только для локальной разработки и тестов
+	AllowPrivateTargets bool
+}
+
+// ErrForbiddenTarget - адрес получателя вне интернета
+var ErrForbiddenTarget = errors.New("webhook target is not a public address")
+
+// Dispatcher - отправляет события из outbox webhook_deliveries с повторами и backoff
+type Dispatcher struct {
+	queue  *Queue
+	client *http.Client
+	cfg    Config
+	logger *zap.SugaredLogger
+}
+
+func NewDispatcher(db *sql.DB, cfg Config, logger *zap.SugaredLogger) *Dispatcher {
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 20
+	}
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+	if cfg.MaxAttempts <= 0 {
+		cfg.MaxAttempts = 8
+	}
+
+	return &Dispatcher{
+		// блокировка доставки переживает запрос с запасом
+		queue:  NewQueue(db, 2*cfg.Timeout+10*time.Second),
+		client: newClient(cfg),
+		cfg:    cfg,
+		logger: logger,
+	}
+}
+
+// newClient - HTTP-клиент без прокси и редиректов. Адрес проверяется при соединении,
+// уже после DNS, поэтому смена записи после регистрации вебхука не откроет доступ во внутреннюю сеть
+func newClient(cfg Config) *http.Client {
+	dialer := &net.Dialer{Timeout: cfg.Timeout}
+	if !cfg.AllowPrivateTargets {
+		dialer.Control = denyPrivate
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   cfg.Timeout,
+		Transport: transport,
+		// редирект считается ответом получателя, а не поводом идти по новому адресу
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// denyPrivate - запрет соединений с адресами вне интернета
+func denyPrivate(_, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	addr, err := netip.ParseAddr(host)
+	if err != nil || !models.IsPublicAddr(addr) {
+		return ErrForbiddenTarget
+	}
+	return nil
+}
+
+// Run - рассылает доставки до отмены ctx
+func (d *Dispatcher) Run(ctx context.Context) {
+	for ctx.Err() == nil {
+		n, err := d.DispatchOnce(ctx)
+		if err != nil {
+			d.logger.Errorf("Webhook dispatch failed: %v", err)
+		}
+		if n > 0 && err == nil {
+			// очередь могла не опустеть - следующий проход сразу
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(d.cfg.PollInterval):
+		}
+	}
+}
+
+// DispatchOnce - один проход: забрать пачку доставок и отправить их параллельно
+func (d *Dispatcher) DispatchOnce(ctx context.Context) (int, error) {
+	deliveries, err := d.queue.Claim(ctx, d.cfg.BatchSize)
+	if err != nil {
+		return 0, err
+	}
+
+	var wg sync.WaitGroup
+	for _, delivery := range deliveries {
+		wg.Add(1)
+		go func(delivery Delivery) {
+			defer wg.Done()
+			d.deliver(ctx, delivery)
+		}(delivery)
+	}
+	wg.Wait()
+
+	return len(deliveries), nil
+}
+
+func (d *Dispatcher) deliver(ctx context.Context, delivery Delivery) {
+	statusCode, err := d.send(ctx, delivery)
+	if err == nil {
+		if err := d.queue.Delivered(ctx, delivery.ID, statusCode); err != nil {
+			d.logger.Errorf("%v", err)
+		}
+		return
+	}
+
+	attempt := delivery.Attempt + 1
+	if attempt >= d.cfg.MaxAttempts {
+		d.logger.Warnf("Webhook delivery %d to %s failed after %d attempts: %v", delivery.ID, delivery.URL, attempt, err)
+		if err := d.queue.Failed(ctx, delivery.ID, statusCode, err); err != nil {
+			d.logger.Errorf("%v", err)
+		}
+		return
+	}
+
+	if err := d.queue.Retry(ctx, delivery.ID, statusCode, err, retryDelay(attempt)); err != nil {
+		d.logger.Errorf("%v", err)
+	}
+}
+
+// send - POST подписанного payload, ошибка для любого ответа кроме 2xx
+func (d *Dispatcher) send(ctx context.Context, delivery Delivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+
+	timestamp := time.Now().Unix()
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderEvent, delivery.Event)
+	req.Header.Set(HeaderDelivery, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(HeaderTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderSignature, "sha256="+Sign(delivery.Secret, timestamp, delivery.Payload))
+
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,134 @@
+package webhook
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+// Delivery - доставка, забранная из outbox вместе с адресом и секретом вебхука
+type Delivery struct {
+	ID        int64
+	WebhookID int
+	URL       string
+	Secret    string
+	Event     string
+	Payload   []byte
+	Attempt   int
+}
+
+// Queue - outbox доставок в таблице webhook_deliveries.
+// Доставки забираются с FOR UPDATE SKIP LOCKED, поэтому диспетчер может работать на всех инстансах
+type Queue struct {
+	db         *sql.DB
+	visibility time.Duration
+}
+
+func NewQueue(db *sql.DB, visibility time.Duration) *Queue {
+	if visibility <= 0 {
+		visibility = time.Minute
+	}
+	return &Queue{db: db, visibility: visibility}
+}
+
+// Claim - забирает до limit доставок, время повтора которых подошло
+func (q *Queue) Claim(ctx context.Context, limit int) ([]Delivery, error) {
+	rows, err := q.db.QueryContext(ctx, `
+        WITH claimed AS (
+            UPDATE webhook_deliveries
+            SET locked_until = NOW() + make_interval(secs => $1)
+            WHERE id IN (
+                SELECT id FROM webhook_deliveries
+                WHERE status = 'PENDING'
+                    AND next_attempt_at <= NOW()
+                    AND (locked_until IS NULL OR locked_until < NOW())
+                ORDER BY next_attempt_at
+                LIMIT $2
+                FOR UPDATE SKIP LOCKED
+            )
+            RETURNING id, webhook_id, event, payload, attempts
+        )
+        SELECT c.id, c.webhook_id, w.url, w.secret, c.event, c.payload, c.attempts
+        FROM claimed c
+        JOIN webhooks w ON w.id = c.webhook_id`,
+		q.visibility.Seconds(), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to claim webhook deliveries: %w", err)
+	}
+	defer rows.Close()
+
+	var deliveries []Delivery
+	for rows.Next() {
+		var d Delivery
+		if err := rows.Scan(&d.ID, &d.WebhookID, &d.URL, &d.Secret, &d.Event, &d.Payload, &d.Attempt); err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+// Delivered - получатель ответил 2xx
+func (q *Queue) Delivered(ctx context.Context, id int64, statusCode int) error {
+	_, err := q.db.ExecContext(ctx, `
+        UPDATE webhook_deliveries
+        SET status = 'DELIVERED',
+            attempts = attempts + 1,
+            last_status_code = $2,
+            last_error = NULL,
+            locked_until = NULL,
+            delivered_at = NOW()
+        WHERE id = $1`, id, statusCode)
+	if err != nil {
+		return fmt.Errorf("failed to mark webhook delivery %d delivered: %w", id, err)
+	}
+	return nil
+}
+
+// Retry - попытка не удалась, повтор через delay. statusCode 0 - ответа не было
+func (q *Queue) Retry(ctx context.Context, id int64, statusCode int, cause error, delay time.Duration) error {
+	_, err := q.db.ExecContext(ctx, `
+        UPDATE webhook_deliveries
+        SET attempts = attempts + 1,
+            last_status_code = NULLIF($2, 0),
+            last_error = $3,
+            next_attempt_at = NOW() + make_interval(secs => $4),
+            locked_until = NULL
+        WHERE id = $1`, id, statusCode, cause.Error(), delay.Seconds())
+	if err != nil {
+		return fmt.Errorf("failed to retry webhook delivery %d: %w", id, err)
+	}
+	return nil
+}
+
+// Failed - попытки исчерпаны, доставка больше не повторяется
+func (q *Queue) Failed(ctx context.Context, id int64, statusCode int, cause error) error {
+	_, err := q.db.ExecContext(ctx, `
+        UPDATE webhook_deliveries
+        SET status = 'FAILED',
+            attempts = attempts + 1,
+            last_status_code = NULLIF($2, 0),
+            last_error = $3,
+            locked_until = NULL
+        WHERE id = $1`, id, statusCode, cause.Error())
+	if err != nil {
+		return fmt.Errorf("failed to fail webhook delivery %d: %w", id, err)
+	}
+	return nil
+}
+
+// retryDelay - экспоненциальная пауза между попытками доставки
+func retryDelay(attempt int) time.Duration {
+	const maxDelay = time.Hour
+
+	delay := 10 * time.Second
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	if delay > maxDelay {
+		delay = maxDelay
+	}
+	return delay
+}
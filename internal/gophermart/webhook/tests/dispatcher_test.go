@@ -0,0 +1,166 @@
+package tests
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"go-musthave-diploma-tpl/internal/gophermart/webhook"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+const claimQuery = `UPDATE webhook_deliveries(.|\n)*FOR UPDATE SKIP LOCKED(.|\n)*JOIN webhooks w`
+
+func claimRows(url string, attempts int) *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "webhook_id", "url", "secret", "event", "payload", "attempts"}).
+		AddRow(int64(7), 3, url, "s3cr3t", "order.processed", []byte(`{"number":"12345678903"}`), attempts)
+}
+
+func TestSign(t *testing.T) {
+	// подпись детерминирована и зависит от timestamp
+	a := webhook.Sign("secret", 1700000000, []byte(`{}`))
+	assert.Equal(t, a, webhook.Sign("secret", 1700000000, []byte(`{}`)))
+	assert.NotEqual(t, a, webhook.Sign("secret", 1700000001, []byte(`{}`)))
+	assert.NotEqual(t, a, webhook.Sign("other", 1700000000, []byte(`{}`)))
+	assert.Len(t, a, 64)
+}
+
+func TestDispatcher_Delivered(t *testing.T) {
+	var received *http.Request
+	var body []byte
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+		body, _ = io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer server.Close()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(claimQuery).WillReturnRows(claimRows(server.URL, 0))
+	mock.ExpectExec(`SET status = 'DELIVERED'`).
+		WithArgs(int64(7), http.StatusNoContent).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	d := webhook.NewDispatcher(db, webhook.Config{AllowPrivateTargets: true}, zap.NewNop().Sugar())
+	n, err := d.DispatchOnce(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, 1, n)
+
+	require.NotNil(t, received)
+	assert.Equal(t, `{"number":"12345678903"}`, string(body))
+	assert.Equal(t, "order.processed", received.Header.Get(webhook.HeaderEvent))
+	assert.Equal(t, "7", received.Header.Get(webhook.HeaderDelivery))
+
+	timestamp, err := strconv.ParseInt(received.Header.Get(webhook.HeaderTimestamp), 10, 64)
+	require.NoError(t, err)
+	assert.Equal(t, "sha256="+webhook.Sign("s3cr3t", timestamp, body), received.Header.Get(webhook.HeaderSignature))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDispatcher_RetryAndFail(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name      string
+		attempts  int
+		setupMock func(mock sqlmock.Sqlmock)
+	}{
+		{
+			name:     "Retry with backoff",
+			attempts: 0,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`next_attempt_at = NOW\(\) \+ make_interval`).
+					WithArgs(int64(7), http.StatusBadGateway, "unexpected status 502", (10 * time.Second).Seconds()).
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+		{
+			name:     "Attempts exhausted",
+			attempts: 2,
+			setupMock: func(mock sqlmock.Sqlmock) {
+				mock.ExpectExec(`SET status = 'FAILED'`).
+					WithArgs(int64(7), http.StatusBadGateway, "unexpected status 502").
+					WillReturnResult(sqlmock.NewResult(0, 1))
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, mock, err := sqlmock.New()
+			require.NoError(t, err)
+			defer db.Close()
+
+			mock.ExpectQuery(claimQuery).WillReturnRows(claimRows(server.URL, tt.attempts))
+			tt.setupMock(mock)
+
+			d := webhook.NewDispatcher(db, webhook.Config{MaxAttempts: 3, AllowPrivateTargets: true}, zap.NewNop().Sugar())
+			_, err = d.DispatchOnce(context.Background())
+			require.NoError(t, err)
+			assert.NoError(t, mock.ExpectationsWereMet())
+		})
+	}
+}
+
+func TestDispatcher_ForbiddenTarget(t *testing.T) {
+	var called bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+	defer server.Close()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	// loopback запрещён при соединении, даже если адрес прошёл проверку при регистрации
+	mock.ExpectQuery(claimQuery).WillReturnRows(claimRows(server.URL, 0))
+	mock.ExpectExec(`next_attempt_at = NOW\(\) \+ make_interval`).
+		WithArgs(int64(7), 0, sqlmock.AnyArg(), sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	d := webhook.NewDispatcher(db, webhook.Config{}, zap.NewNop().Sugar())
+	_, err = d.DispatchOnce(context.Background())
+	require.NoError(t, err)
+	assert.False(t, called)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDispatcher_RedirectNotFollowed(t *testing.T) {
+	var redirected bool
+	target := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		redirected = true
+	}))
+	defer target.Close()
+	server := httptest.NewServer(http.RedirectHandler(target.URL, http.StatusTemporaryRedirect))
+	defer server.Close()
+
+	db, mock, err := sqlmock.New()
+	require.NoError(t, err)
+	defer db.Close()
+
+	mock.ExpectQuery(claimQuery).WillReturnRows(claimRows(server.URL, 0))
+	mock.ExpectExec(`next_attempt_at = NOW\(\) \+ make_interval`).
+		WithArgs(int64(7), http.StatusTemporaryRedirect, "unexpected status 307", sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	d := webhook.NewDispatcher(db, webhook.Config{AllowPrivateTargets: true}, zap.NewNop().Sugar())
+	_, err = d.DispatchOnce(context.Background())
+	require.NoError(t, err)
+	assert.False(t, redirected)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,39 @@
+package luhn
+
+// dammTable - вполне антисимметричная квазигруппа порядка 10
+var dammTable = [10][10]int{
+	{0, 3, 1, 7, 5, 9, 8, 6, 4, 2},
+	{7, 0, 9, 2, 1, 5, 4, 8, 6, 3},
+	{4, 2, 0, 6, 8, 7, 1, 3, 5, 9},
+	{1, 7, 5, 0, 9, 8, 3, 4, 2, 6},
+	{6, 1, 2, 3, 0, 4, 5, 9, 7, 8},
+	{3, 6, 7, 4, 2, 0, 9, 5, 8, 1},
+	{5, 8, 6, 9, 7, 2, 0, 1, 3, 4},
+	{8, 9, 4, 5, 3, 6, 2, 0, 1, 7},
+	{9, 4, 3, 8, 6, 1, 7, 2, 0, 5},
+	{2, 5, 8, 1, 4, 3, 6, 7, 9, 0},
+}
+
+// ValidateDamm - номер из цифр с верной контрольной цифрой по Дамму
+func ValidateDamm(number string) bool {
+	if number == "" || !ContainsOnlyDigits(number) {
+		return false
+	}
+	return dammInterim(number) == 0
+}
+
+// DammCheckDigit - контрольная цифра, которую нужно дописать к payload
+func DammCheckDigit(payload string) (byte, error) {
+	if payload == "" || !ContainsOnlyDigits(payload) {
+		return 0, ErrInvalidPayload
+	}
+	return byte('0' + dammInterim(payload)), nil
+}
+
+func dammInterim(number string) int {
+	interim := 0
+	for i := 0; i < len(number); i++ {
+		interim = dammTable[interim][number[i]-'0']
+	}
+	return interim
+}
@@ -1,8 +1,30 @@
 package luhn
 
+import "errors"
+
+// ErrInvalidPayload - payload для расчёта контрольной цифры пуст или содержит не только цифры
+var ErrInvalidPayload = errors.New("payload must be a non-empty string of digits")
+
+// ValidateLuhn - номер из цифр с верной контрольной цифрой по Луну
 func ValidateLuhn(number string) bool {
+	if number == "" || !ContainsOnlyDigits(number) {
+		return false
+	}
+	return luhnSum(number, false)%10 == 0
+}
+
+// LuhnCheckDigit - контрольная цифра, которую нужно дописать к payload
+func LuhnCheckDigit(payload string) (byte, error) {
+	if payload == "" || !ContainsOnlyDigits(payload) {
+		return 0, ErrInvalidPayload
+	}
+	return byte('0' + (10-luhnSum(payload, true)%10)%10), nil
+}
+
+// luhnSum - сумма по Луну; doubleLast - удваивать ли последнюю цифру (для ещё не дописанной контрольной)
+func luhnSum(number string, doubleLast bool) int {
 	sum := 0
-	isSecond := false
+	isSecond := doubleLast
 
 	for i := len(number) - 1; i >= 0; i-- {
 		digit := int(number[i] - '0')
@@ -18,7 +40,7 @@ func ValidateLuhn(number string) bool {
 		isSecond = !isSecond
 	}
 
-	return sum%10 == 0
+	return sum
 }
 
 func ContainsOnlyDigits(s string) bool {
@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"testing"
+
+	luhn "go-musthave-diploma-tpl/pkg"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateLuhn(t *testing.T) {
+	assert.True(t, luhn.ValidateLuhn("12345678903"))
+	assert.True(t, luhn.ValidateLuhn("79927398713"))
+	assert.False(t, luhn.ValidateLuhn("12345678900"))
+	// раньше нецифровые символы давали мусорную сумму
+	assert.False(t, luhn.ValidateLuhn(""))
+	assert.False(t, luhn.ValidateLuhn("1234567890a"))
+	assert.False(t, luhn.ValidateLuhn("0 "))
+}
+
+func TestValidateVerhoeff(t *testing.T) {
+	assert.True(t, luhn.ValidateVerhoeff("2363"))
+	assert.False(t, luhn.ValidateVerhoeff("2364"))
+	// перестановка соседних цифр ловится
+	assert.False(t, luhn.ValidateVerhoeff("3263"))
+	assert.False(t, luhn.ValidateVerhoeff("23a3"))
+}
+
+func TestValidateDamm(t *testing.T) {
+	assert.True(t, luhn.ValidateDamm("5724"))
+	assert.False(t, luhn.ValidateDamm("5727"))
+	assert.False(t, luhn.ValidateDamm("7524"))
+	assert.False(t, luhn.ValidateDamm(""))
+}
+
+func TestCheckDigits(t *testing.T) {
+	tests := []struct {
+		name     string
+		generate func(string) (byte, error)
+		validate func(string) bool
+		payload  string
+		expected byte
+	}{
+		{name: "Luhn", generate: luhn.LuhnCheckDigit, validate: luhn.ValidateLuhn, payload: "7992739871", expected: '3'},
+		{name: "Verhoeff", generate: luhn.VerhoeffCheckDigit, validate: luhn.ValidateVerhoeff, payload: "236", expected: '3'},
+		{name: "Damm", generate: luhn.DammCheckDigit, validate: luhn.ValidateDamm, payload: "572", expected: '4'},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			digit, err := tt.generate(tt.payload)
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, digit)
+
+			// сгенерированный номер проходит проверку для любых payload
+			for _, payload := range []string{"0", "1", "42", "123456789", "98765432109876"} {
+				digit, err := tt.generate(payload)
+				require.NoError(t, err)
+				assert.True(t, tt.validate(payload+string(digit)), payload)
+			}
+
+			_, err = tt.generate("12a")
+			assert.ErrorIs(t, err, luhn.ErrInvalidPayload)
+		})
+	}
+}
+
+func TestRegexValidator(t *testing.T) {
+	v, err := luhn.NewRegexValidator(`SHOP-(\d+)`, luhn.LuhnValidator)
+	require.NoError(t, err)
+
+	assert.True(t, v.Validate("SHOP-12345678903"))
+	assert.False(t, v.Validate("SHOP-12345678900"))
+	assert.False(t, v.Validate("12345678903"))
+	// шаблон применяется к номеру целиком
+	assert.False(t, v.Validate("XSHOP-12345678903"))
+
+	formatOnly, err := luhn.NewRegexValidator(`[A-Z]{2}\d{6}`, nil)
+	require.NoError(t, err)
+	assert.True(t, formatOnly.Validate("AB123456"))
+	assert.False(t, formatOnly.Validate("AB12345"))
+
+	_, err = luhn.NewRegexValidator(`(`, nil)
+	assert.Error(t, err)
+}
+
+func TestNewValidator(t *testing.T) {
+	tests := []struct {
+		name    string
+		scheme  string
+		pattern string
+		valid   string
+		invalid string
+		wantErr bool
+	}{
+		{name: "Default is Luhn", valid: "12345678903", invalid: "12345678900"},
+		{name: "Verhoeff", scheme: "verhoeff", valid: "2363", invalid: "2364"},
+		{name: "Damm", scheme: "DAMM", valid: "5724", invalid: "5727"},
+		{name: "Prefixed Luhn", scheme: "luhn", pattern: `PX(\d+)`, valid: "PX12345678903", invalid: "12345678903"},
+		{name: "Pattern only", scheme: "none", pattern: `\d{4}-\d{4}`, valid: "1234-5678", invalid: "12345678"},
+		{name: "None without pattern", scheme: "none", wantErr: true},
+		{name: "Unknown scheme", scheme: "mod97", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			v, err := luhn.NewValidator(tt.scheme, tt.pattern)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, v.Validate(tt.valid))
+			assert.False(t, v.Validate(tt.invalid))
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package luhn
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// схемы проверки номера заказа
+const (
+	SchemeLuhn     = "luhn"
+	SchemeVerhoeff = "verhoeff"
+	SchemeDamm     = "damm"
+	// только формат, без контрольной цифры
+	SchemeNone = "none"
+)
+
+// Validator - проверка номера заказа
+type Validator interface {
+	Validate(number string) bool
+}
+
+// ValidatorFunc - функция как Validator
+type ValidatorFunc func(number string) bool
+
+func (f ValidatorFunc) Validate(number string) bool {
+	return f(number)
+}
+
+var (
+	LuhnValidator     Validator = ValidatorFunc(ValidateLuhn)
+	VerhoeffValidator Validator = ValidatorFunc(ValidateVerhoeff)
+	DammValidator     Validator = ValidatorFunc(ValidateDamm)
+)
+
+// RegexValidator - номер целиком совпадает с шаблоном.
+// Если в шаблоне есть группа, её значение дополнительно проверяется вложенным валидатором,
+// например `^SHOP-(\d+)$` с Luhn проверяет контрольную цифру без префикса
+type RegexValidator struct {
+	re    *regexp.Regexp
+	inner Validator
+}
+
+// NewRegexValidator - inner nil означает проверку только формата
+func NewRegexValidator(pattern string, inner Validator) (*RegexValidator, error) {
+	// шаблон всегда проверяет номер целиком
+	re, err := regexp.Compile(`^(?:` + pattern + `)$`)
+	if err != nil {
+		return nil, fmt.Errorf("invalid order number pattern: %w", err)
+	}
+	return &RegexValidator{re: re, inner: inner}, nil
+}
+
+func (v *RegexValidator) Validate(number string) bool {
+	match := v.re.FindStringSubmatch(number)
+	if match == nil {
+		return false
+	}
+	if v.inner == nil {
+		return true
+	}
+	if len(match) > 1 {
+		return v.inner.Validate(match[1])
+	}
+	return v.inner.Validate(number)
+}
+
+// NewValidator - валидатор по схеме из конфига; непустой pattern оборачивает схему в RegexValidator
+func NewValidator(scheme, pattern string) (Validator, error) {
+	var v Validator
+	switch strings.ToLower(scheme) {
+	case "", SchemeLuhn:
+		v = LuhnValidator
+	case SchemeVerhoeff:
+		v = VerhoeffValidator
+	case SchemeDamm:
+		v = DammValidator
+	case SchemeNone:
+		if pattern == "" {
+			return nil, fmt.Errorf("scheme %q requires an order number pattern", SchemeNone)
+		}
+	default:
+		return nil, fmt.Errorf("unknown order number scheme %q", scheme)
+	}
+
+	if pattern == "" {
+		return v, nil
+	}
+	return NewRegexValidator(pattern, v)
+}
@@ -0,0 +1,54 @@
+package luhn
+
+// таблицы алгоритма Верхуффа: умножение в группе диэдра D5, перестановка и обратные элементы
+var (
+	verhoeffD = [10][10]int{
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		{1, 2, 3, 4, 0, 6, 7, 8, 9, 5},
+		{2, 3, 4, 0, 1, 7, 8, 9, 5, 6},
+		{3, 4, 0, 1, 2, 8, 9, 5, 6, 7},
+		{4, 0, 1, 2, 3, 9, 5, 6, 7, 8},
+		{5, 9, 8, 7, 6, 0, 4, 3, 2, 1},
+		{6, 5, 9, 8, 7, 1, 0, 4, 3, 2},
+		{7, 6, 5, 9, 8, 2, 1, 0, 4, 3},
+		{8, 7, 6, 5, 9, 3, 2, 1, 0, 4},
+		{9, 8, 7, 6, 5, 4, 3, 2, 1, 0},
+	}
+	verhoeffP = [8][10]int{
+		{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		{1, 5, 7, 6, 2, 8, 3, 0, 9, 4},
+		{5, 8, 0, 3, 7, 9, 6, 1, 4, 2},
+		{8, 9, 1, 6, 0, 4, 3, 5, 2, 7},
+		{9, 4, 5, 3, 1, 2, 6, 8, 7, 0},
+		{4, 2, 8, 6, 5, 7, 3, 9, 0, 1},
+		{2, 7, 9, 3, 8, 0, 6, 4, 1, 5},
+		{7, 0, 4, 6, 9, 1, 3, 2, 5, 8},
+	}
+	verhoeffInv = [10]int{0, 4, 3, 2, 1, 5, 6, 7, 8, 9}
+)
+
+// ValidateVerhoeff - номер из цифр с верной контрольной цифрой по Верхуффу
+func ValidateVerhoeff(number string) bool {
+	if number == "" || !ContainsOnlyDigits(number) {
+		return false
+	}
+	return verhoeffChecksum(number, 0) == 0
+}
+
+// VerhoeffCheckDigit - контрольная цифра, которую нужно дописать к payload
+func VerhoeffCheckDigit(payload string) (byte, error) {
+	if payload == "" || !ContainsOnlyDigits(payload) {
+		return 0, ErrInvalidPayload
+	}
+	return byte('0' + verhoeffInv[verhoeffChecksum(payload, 1)]), nil
+}
+
+// verhoeffChecksum - offset 1 сдвигает позиции, оставляя место под контрольную цифру
+func verhoeffChecksum(number string, offset int) int {
+	c := 0
+	for i := 0; i < len(number); i++ {
+		digit := int(number[len(number)-1-i] - '0')
+		c = verhoeffD[c][verhoeffP[(i+offset)%8][digit]]
+	}
+	return c
+}